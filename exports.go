@@ -1,6 +1,8 @@
 package wav
 
 import (
+	"io"
+
 	"github.com/gravestench/wav/pkg"
 )
 
@@ -11,3 +13,54 @@ func WavDecompress(data []byte, channelCount int) ([]byte, error) {
 func HuffmanDecompress(data []byte) []byte {
 	return pkg.HuffmanDecompress(data)
 }
+
+func HuffmanDecompressSize(data []byte, expectedSize int) ([]byte, error) {
+	return pkg.HuffmanDecompressSize(data, expectedSize)
+}
+
+func MultiDecompress(data []byte, mask byte) ([]byte, error) {
+	return pkg.MultiDecompress(data, mask)
+}
+
+func DecodeD2Sound(data []byte, flags byte) ([]byte, error) {
+	return pkg.DecodeD2Sound(data, flags)
+}
+
+func WavCompress(data []byte, channelCount, compressionLevel int) ([]byte, error) {
+	return pkg.WavCompress(data, channelCount, compressionLevel)
+}
+
+func HuffmanCompress(data []byte, comptype byte) ([]byte, error) {
+	return pkg.HuffmanCompress(data, comptype)
+}
+
+func CompressWave(pcm []byte, channels, quality int) ([]byte, byte, error) {
+	return pkg.CompressWave(pcm, channels, quality)
+}
+
+func Transcode(src io.Reader, dst io.Writer, opts ...pkg.Option) error {
+	return pkg.Transcode(src, dst, opts...)
+}
+
+// Buffer holds decoded audio in memory as interleaved 16-bit samples
+type Buffer = pkg.Buffer
+
+// Decode reads a WAV file, or a compressed MPQ sector, and decodes all of its audio
+func Decode(r io.Reader) (*Buffer, error) {
+	return pkg.DecodeBuffer(r)
+}
+
+// Encode writes the buffer as a PCM WAV file, converted as requested by opts
+func Encode(w io.Writer, buffer *Buffer, opts ...pkg.Option) error {
+	return pkg.EncodeBuffer(w, buffer, opts...)
+}
+
+// DecodeFile reads and decodes all of the audio of a WAV file
+func DecodeFile(path string) (*Buffer, error) {
+	return pkg.DecodeFile(path)
+}
+
+// EncodeFile writes the buffer to a PCM WAV file, converted as requested by opts
+func EncodeFile(path string, buffer *Buffer, opts ...pkg.Option) error {
+	return pkg.EncodeFile(path, buffer, opts...)
+}
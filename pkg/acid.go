@@ -0,0 +1,92 @@
+package pkg
+
+import (
+	"math"
+)
+
+const acidChunkSize = 24
+
+// Acid flags
+const (
+	AcidOneShot    = 0x01
+	AcidRootNote   = 0x02
+	AcidStretch    = 0x04
+	AcidDiskBased  = 0x08
+	AcidHighOctave = 0x10
+)
+
+// Acid holds the contents of an acid chunk, the tempo and loop description written by
+// loop libraries
+type Acid struct {
+	// Flags is a combination of the Acid flags
+	Flags uint32
+	// RootNote is the MIDI note of the loop, used when AcidRootNote is set
+	RootNote uint16
+	// Beats is the length of the loop in beats
+	Beats uint32
+	// MeterDenominator and MeterNumerator give the time signature
+	MeterDenominator uint16
+	MeterNumerator   uint16
+	// Tempo is in beats per minute
+	Tempo float32
+}
+
+// OneShot reports whether the sound is a one shot rather than a loop
+func (v *Acid) OneShot() bool {
+	return v.Flags&AcidOneShot != 0
+}
+
+// parseAcid parses the body of an acid chunk
+func parseAcid(body []byte) (*Acid, error) {
+	if len(body) < acidChunkSize {
+		return nil, ErrShortMetadata
+	}
+
+	r := CreateStreamReader(body)
+	result := &Acid{}
+
+	result.Flags, _ = r.ReadUInt32()
+	result.RootNote, _ = r.ReadUInt16()
+	r.SkipBytes(bytesPerint16 + bytesPerint32) // unknown fields
+	result.Beats, _ = r.ReadUInt32()
+	result.MeterDenominator, _ = r.ReadUInt16()
+	result.MeterNumerator, _ = r.ReadUInt16()
+
+	tempo, _ := r.ReadUInt32()
+	result.Tempo = math.Float32frombits(tempo)
+
+	return result, nil
+}
+
+// marshal returns the body of an acid chunk
+func (v *Acid) marshal() []byte {
+	w := CreateStreamWriterSize(acidChunkSize)
+
+	w.PushUint32(v.Flags)
+	w.PushUint16(v.RootNote)
+	w.PushUint16(0x8000) //nolint:gomnd // unknown, always 0x8000
+	w.PushUint32(0)      // unknown float, always 0
+	w.PushUint32(v.Beats)
+	w.PushUint16(v.MeterDenominator)
+	w.PushUint16(v.MeterNumerator)
+	w.PushUint32(math.Float32bits(v.Tempo))
+
+	return w.GetBytes()
+}
+
+// MarshalBinary implements encoding.BinaryMarshaler, returning the body of an acid chunk
+func (v *Acid) MarshalBinary() ([]byte, error) {
+	return v.marshal(), nil
+}
+
+// UnmarshalBinary implements encoding.BinaryUnmarshaler, parsing the body of an acid chunk
+func (v *Acid) UnmarshalBinary(data []byte) error {
+	parsed, err := parseAcid(data)
+	if err != nil {
+		return err
+	}
+
+	*v = *parsed
+
+	return nil
+}
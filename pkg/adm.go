@@ -0,0 +1,80 @@
+package pkg
+
+const (
+	chnaHeaderSize   = 4
+	chnaEntrySize    = 40
+	chnaUIDSize      = 12
+	chnaTrackRefSize = 14
+	chnaPackRefSize  = 11
+)
+
+// ADMTrack maps a track of the file to an ADM audioTrackUID
+type ADMTrack struct {
+	// Index is the 1 based index of the track (channel) in the file
+	Index uint16
+	// UID is the audioTrackUID, such as "ATU_00000001"
+	UID string
+	// TrackRef is the audioTrackFormatID, such as "AT_00010001_01"
+	TrackRef string
+	// PackRef is the audioPackFormatID, such as "AP_00010002"
+	PackRef string
+}
+
+// ADM holds the Audio Definition Model metadata of a file: the chna chunk associating
+// tracks with the ADM document, and the axml chunk holding the document
+type ADM struct {
+	// Tracks is the number of tracks the chna chunk declares
+	Tracks uint16
+	// Entries lists the track UIDs of the chna chunk
+	Entries []ADMTrack
+	// XML is the ADM document of the axml chunk
+	XML string
+}
+
+// parseChna parses the body of a chna chunk into v
+func (v *ADM) parseChna(body []byte) error {
+	r := CreateStreamReader(body)
+
+	tracks, err := r.ReadUInt16()
+	if err != nil {
+		return ErrShortMetadata
+	}
+
+	count, err := r.ReadUInt16()
+	if err != nil || uint64(count)*chnaEntrySize > r.Size()-r.Position() {
+		return ErrShortMetadata
+	}
+
+	v.Tracks = tracks
+	v.Entries = make([]ADMTrack, count)
+
+	for i := range v.Entries {
+		entry := &v.Entries[i]
+
+		entry.Index, _ = r.ReadUInt16()
+		entry.UID, _ = r.ReadFixedString(chnaUIDSize)
+		entry.TrackRef, _ = r.ReadFixedString(chnaTrackRefSize)
+		entry.PackRef, _ = r.ReadFixedString(chnaPackRefSize)
+		r.SkipBytes(1) // pad
+	}
+
+	return nil
+}
+
+// marshalChna returns the body of the chna chunk
+func (v *ADM) marshalChna() []byte {
+	w := CreateStreamWriterSize(chnaHeaderSize + len(v.Entries)*chnaEntrySize)
+
+	w.PushUint16(v.Tracks)
+	w.PushUint16(uint16(len(v.Entries)))
+
+	for _, entry := range v.Entries {
+		w.PushUint16(entry.Index)
+		w.PushFixedString(entry.UID, chnaUIDSize)
+		w.PushFixedString(entry.TrackRef, chnaTrackRefSize)
+		w.PushFixedString(entry.PackRef, chnaPackRefSize)
+		w.PushBytes(0)
+	}
+
+	return w.GetBytes()
+}
@@ -0,0 +1,119 @@
+package pkg
+
+import (
+	"io"
+
+	"github.com/gravestench/wav/pkg/adpcm"
+)
+
+// Errors returned by the ADPCM codec
+var (
+	ErrChannelCount     = adpcm.ErrChannelCount
+	ErrInvalidChannel   = adpcm.ErrInvalidChannel
+	ErrCompressionLevel = adpcm.ErrCompressionLevel
+	ErrIndex            = adpcm.ErrIndex
+	ErrBlockHeader      = adpcm.ErrBlockHeader
+	ErrPredictor        = adpcm.ErrPredictor
+)
+
+// ADPCM compression levels. The level is the number of bits kept from each sample
+// difference, so higher levels trade size for fidelity. StormLib uses levels 4 to 6.
+const (
+	ADPCMLevelMin      = adpcm.LevelMin
+	ADPCMQualityLow    = adpcm.QualityLow
+	ADPCMQualityMedium = adpcm.QualityMedium
+	ADPCMQualityHigh   = adpcm.QualityHigh
+	ADPCMLevelMax      = adpcm.LevelMax
+)
+
+// ADPCMChannelState is the predictor state the Blizzard ADPCM codec keeps per channel
+type ADPCMChannelState = adpcm.ChannelState
+
+// ADPCMDecoder decodes Blizzard ADPCM, keeping the per-channel predictor state
+// between calls
+type ADPCMDecoder = adpcm.Decoder
+
+// CreateADPCMDecoder creates a decoder for a mono or stereo ADPCM stream
+func CreateADPCMDecoder(channelCount int) (*ADPCMDecoder, error) {
+	return adpcm.CreateDecoder(channelCount)
+}
+
+// ADPCMEncoderOption configures an ADPCMEncoder
+type ADPCMEncoderOption = adpcm.EncoderOption
+
+// WithADPCMLevel sets the compression level. The default is ADPCMQualityMedium.
+func WithADPCMLevel(level int) ADPCMEncoderOption {
+	return adpcm.WithLevel(level)
+}
+
+// WithADPCMNoiseShaping feeds back the quantization error of each sample into the
+// next one, from 0 for none to 1 for full first order shaping
+func WithADPCMNoiseShaping(strength float64) ADPCMEncoderOption {
+	return adpcm.WithNoiseShaping(strength)
+}
+
+// ADPCMEncoder encodes 16-bit PCM as Blizzard ADPCM
+type ADPCMEncoder = adpcm.Encoder
+
+// CreateADPCMEncoder creates an encoder for mono or stereo audio
+func CreateADPCMEncoder(channelCount int, opts ...ADPCMEncoderOption) (*ADPCMEncoder, error) {
+	return adpcm.CreateEncoder(channelCount, opts...)
+}
+
+// ADPCMState is the complete state of a Blizzard ADPCM stream between blocks
+type ADPCMState = adpcm.State
+
+// CreateADPCMState creates the state of a stream starting with the given initial
+// sample of each channel, as stored in an ADPCM stream header
+func CreateADPCMState(shift byte, initial ...int16) (ADPCMState, error) {
+	return adpcm.CreateState(shift, initial...)
+}
+
+// DecodeBlock decodes a block of ADPCM codes following the given state, which is
+// updated to follow the block
+func DecodeBlock(block []byte, state *ADPCMState) ([]int16, error) {
+	return adpcm.DecodeBlock(block, state)
+}
+
+// DecodeBlockTo decodes a block like DecodeBlock, appending the samples to dst and
+// returning the extended slice
+func DecodeBlockTo(dst []int16, block []byte, state *ADPCMState) ([]int16, error) {
+	return adpcm.DecodeBlockTo(dst, block, state)
+}
+
+// EncodeBlock encodes interleaved samples as a block of ADPCM codes following the
+// given state, which is updated to follow the block
+func EncodeBlock(samples []int16, state *ADPCMState) ([]byte, error) {
+	return adpcm.EncodeBlock(samples, state)
+}
+
+// ADPCMSeekPoint is the decoder state at a frame of an ADPCM stream
+type ADPCMSeekPoint = adpcm.SeekPoint
+
+// ADPCMIndex holds seek points of an ADPCM stream, allowing random access decoding
+type ADPCMIndex = adpcm.Index
+
+// BuildADPCMIndex scans the ADPCM stream read from r once, recording a seek point
+// every interval frames
+func BuildADPCMIndex(r io.Reader, channelCount, interval int) (*ADPCMIndex, error) {
+	return adpcm.BuildIndex(r, channelCount, interval)
+}
+
+// CreateADPCMReader creates a reader decompressing the ADPCM stream read from r,
+// like WavDecompress but streaming
+func CreateADPCMReader(r io.Reader, channelCount int) (io.Reader, error) {
+	return adpcm.CreateReader(r, channelCount)
+}
+
+// DecodeIMABlock decodes an IMA ADPCM block of a WAV file, appending its interleaved
+// samples to dst and returning the extended slice
+func DecodeIMABlock(dst []int16, block []byte, channels int) ([]int16, error) {
+	return adpcm.DecodeIMABlock(dst, block, channels)
+}
+
+// DecodeMSBlock decodes a Microsoft ADPCM block of a WAV file with the given predictor
+// coefficients, or the standard ones if nil, appending its interleaved samples to dst
+// and returning the extended slice
+func DecodeMSBlock(dst []int16, block []byte, channels int, coefficients [][2]int) ([]int16, error) {
+	return adpcm.DecodeMSBlock(dst, block, channels, coefficients)
+}
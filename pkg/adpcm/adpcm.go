@@ -0,0 +1,92 @@
+// Package adpcm implements the Blizzard ADPCM codec used for audio in MPQ archives,
+// and decodes the IMA and Microsoft ADPCM blocks of WAV files
+package adpcm
+
+import (
+	"errors"
+)
+
+// ErrChannelCount is returned when ADPCM is used with other than one or two channels
+var ErrChannelCount = errors.New("ADPCM supports one or two channels")
+
+const (
+	bitsPerByte      = 8
+	bytesPerint16    = 2
+	minInt16         = -32768
+	maxInt16         = 32767
+	initialStepIndex = 0x2c
+	maxStepIndex     = 0x58
+)
+
+// sLookup holds the ADPCM step sizes indexed by step index
+//
+//nolint:gochecknoglobals // lookup table
+var sLookup = []int{
+	0x0007, 0x0008, 0x0009, 0x000A, 0x000B, 0x000C, 0x000D, 0x000E,
+	0x0010, 0x0011, 0x0013, 0x0015, 0x0017, 0x0019, 0x001C, 0x001F,
+	0x0022, 0x0025, 0x0029, 0x002D, 0x0032, 0x0037, 0x003C, 0x0042,
+	0x0049, 0x0050, 0x0058, 0x0061, 0x006B, 0x0076, 0x0082, 0x008F,
+	0x009D, 0x00AD, 0x00BE, 0x00D1, 0x00E6, 0x00FD, 0x0117, 0x0133,
+	0x0151, 0x0173, 0x0198, 0x01C1, 0x01EE, 0x0220, 0x0256, 0x0292,
+	0x02D4, 0x031C, 0x036C, 0x03C3, 0x0424, 0x048E, 0x0502, 0x0583,
+	0x0610, 0x06AB, 0x0756, 0x0812, 0x08E0, 0x09C3, 0x0ABD, 0x0BD0,
+	0x0CFF, 0x0E4C, 0x0FBA, 0x114C, 0x1307, 0x14EE, 0x1706, 0x1954,
+	0x1BDC, 0x1EA5, 0x21B6, 0x2515, 0x28CA, 0x2CDF, 0x315B, 0x364B,
+	0x3BB9, 0x41B2, 0x4844, 0x4F7E, 0x5771, 0x602F, 0x69CE, 0x7462,
+	0x7FFF,
+}
+
+// sLookup2 holds the step index adjustments indexed by the low bits of an encoded sample
+//
+//nolint:gochecknoglobals // lookup table
+var sLookup2 = []int{
+	-1, 0, -1, 4, -1, 2, -1, 6,
+	-1, 1, -1, 5, -1, 3, -1, 7,
+	-1, 1, -1, 5, -1, 3, -1, 7,
+	-1, 2, -1, 4, -1, 6, -1, 8,
+}
+
+// Decompress decompresses a complete ADPCM stream, returning little-endian 16-bit samples
+func Decompress(data []byte, channelCount int) ([]byte, error) {
+	decoder, err := CreateDecoder(channelCount)
+	if err != nil {
+		return nil, err
+	}
+
+	return decoder.Decompress(data)
+}
+
+// DecompressSamples decompresses a complete ADPCM stream like Decompress, returning the
+// samples directly instead of little-endian bytes
+func DecompressSamples(data []byte, channelCount int) ([]int16, error) {
+	decoder, err := CreateDecoder(channelCount)
+	if err != nil {
+		return nil, err
+	}
+
+	return decoder.DecompressSamples(data)
+}
+
+// Compress compresses 16-bit little-endian PCM the way StormLib's CompressADPCM
+// does. compressionLevel sets how many bits of each difference are kept; StormLib
+// uses 4, 5 or 6, and the decoder reads the resulting shift from the output header.
+func Compress(data []byte, channelCount, compressionLevel int) ([]byte, error) {
+	encoder, err := CreateEncoder(channelCount, WithLevel(compressionLevel))
+	if err != nil {
+		return nil, err
+	}
+
+	return encoder.Compress(data), nil
+}
+
+// samplesToBytes converts samples to little-endian 16-bit PCM
+func samplesToBytes(samples []int16) []byte {
+	result := make([]byte, len(samples)*bytesPerint16)
+
+	for i, sample := range samples {
+		result[i*2] = byte(sample)
+		result[i*2+1] = byte(uint16(sample) >> bitsPerByte)
+	}
+
+	return result
+}
@@ -0,0 +1,99 @@
+package adpcm
+
+import (
+	"github.com/gravestench/wav/pkg/bitio"
+)
+
+// State is the complete state of a Blizzard ADPCM stream between blocks. It lets
+// containers other than MPQ store blocks without headers and carry the state themselves.
+type State struct {
+	// Channels is 1 for mono or 2 for stereo
+	Channels int
+	// Shift is the bit shift written in an ADPCM stream header, the compression level minus one
+	Shift byte
+	// Channel is the channel of the most recent sample
+	Channel int
+	// Channel state, of which the first Channels entries are used
+	State [2]ChannelState
+}
+
+// CreateState creates the state of a stream starting with the given initial
+// sample of each channel, as stored in an ADPCM stream header
+func CreateState(shift byte, initial ...int16) (State, error) {
+	if len(initial) < 1 || len(initial) > 2 {
+		return State{}, ErrChannelCount
+	}
+
+	result := State{
+		Channels: len(initial),
+		Shift:    shift,
+		Channel:  len(initial) - 1,
+	}
+
+	for i, sample := range initial {
+		result.State[i] = ChannelState{Predictor: int(sample), StepIndex: initialStepIndex}
+	}
+
+	return result, nil
+}
+
+// DecodeBlock decodes a block of ADPCM codes following the given state, which is
+// updated to follow the block
+func DecodeBlock(block []byte, state *State) ([]int16, error) {
+	return DecodeBlockTo(make([]int16, 0, len(block)), block, state)
+}
+
+// DecodeBlockTo decodes a block like DecodeBlock, appending the samples to dst and
+// returning the extended slice. Every code byte yields at most one sample, so with
+// len(block) spare capacity in dst no memory is allocated, which lets a buffer be
+// reused block after block.
+func DecodeBlockTo(dst []int16, block []byte, state *State) ([]int16, error) {
+	if state.Channels < 1 || state.Channels > 2 {
+		return nil, ErrChannelCount
+	}
+
+	if state.Channel < 0 || state.Channel >= state.Channels {
+		return nil, ErrInvalidChannel
+	}
+
+	decoder := Decoder{
+		channels: state.Channels,
+		shift:    state.Shift,
+		channel:  state.Channel,
+		state:    state.State,
+	}
+
+	dst = decoder.decode(block, dst)
+
+	state.Channel = decoder.channel
+	state.State = decoder.state
+
+	return dst, nil
+}
+
+// EncodeBlock encodes interleaved samples as a block of ADPCM codes following the
+// given state, which is updated to follow the block
+func EncodeBlock(samples []int16, state *State) ([]byte, error) {
+	encoder, err := CreateEncoder(state.Channels, WithLevel(int(state.Shift)+1))
+	if err != nil {
+		return nil, err
+	}
+
+	if state.Channel < 0 || state.Channel >= state.Channels {
+		return nil, ErrInvalidChannel
+	}
+
+	encoder.channel = state.Channel
+	encoder.state = state.State
+
+	output := bitio.CreateStreamWriterSize(len(samples))
+
+	for _, sample := range samples {
+		encoder.encode(int(sample), output)
+	}
+
+	state.Channel = encoder.channel
+	state.State = encoder.state
+
+	return output.GetBytes(), nil
+}
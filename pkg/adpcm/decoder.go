@@ -0,0 +1,254 @@
+package adpcm
+
+import (
+	"errors"
+
+	"github.com/gravestench/wav/pkg/bitio"
+)
+
+// ErrInvalidChannel is returned when addressing a channel the ADPCM stream doesn't have
+var ErrInvalidChannel = errors.New("invalid ADPCM channel")
+
+// ChannelState is the predictor state the Blizzard ADPCM codec keeps per channel
+type ChannelState struct {
+	// Predictor is the last sample decoded on the channel
+	Predictor int
+	// StepIndex indexes the step size used to scale the next difference, from 0 to 88
+	StepIndex int
+}
+
+// Decoder decodes Blizzard ADPCM, keeping the per-channel predictor state
+// between calls so it can be inspected, or set to resynchronize mid-stream
+type Decoder struct {
+	channels int
+	shift    byte
+	channel  int
+	state    [2]ChannelState
+}
+
+// CreateDecoder creates a decoder for a mono or stereo ADPCM stream
+func CreateDecoder(channelCount int) (*Decoder, error) {
+	if channelCount < 1 || channelCount > 2 {
+		return nil, ErrChannelCount
+	}
+
+	result := &Decoder{
+		channels: channelCount,
+		channel:  channelCount - 1,
+		state: [2]ChannelState{
+			{StepIndex: initialStepIndex},
+			{StepIndex: initialStepIndex},
+		},
+	}
+
+	return result, nil
+}
+
+// State returns the predictor state of a channel
+func (v *Decoder) State(channel int) (ChannelState, error) {
+	if channel < 0 || channel >= v.channels {
+		return ChannelState{}, ErrInvalidChannel
+	}
+
+	return v.state[channel], nil
+}
+
+// SetState replaces the predictor state of a channel, clamping it to valid values
+func (v *Decoder) SetState(channel int, state ChannelState) error {
+	if channel < 0 || channel >= v.channels {
+		return ErrInvalidChannel
+	}
+
+	state.Predictor = clampInt(state.Predictor, minInt16, maxInt16)
+	state.StepIndex = clampInt(state.StepIndex, 0, maxStepIndex)
+	v.state[channel] = state
+
+	return nil
+}
+
+// Shift returns the bit shift read from the stream header
+func (v *Decoder) Shift() byte {
+	return v.shift
+}
+
+// SetShift sets the bit shift used when decoding without a stream header
+func (v *Decoder) SetShift(shift byte) {
+	v.shift = shift
+}
+
+// Channel returns the channel of the most recently decoded sample. The next sample
+// belongs to the other channel of a stereo stream.
+func (v *Decoder) Channel() int {
+	return v.channel
+}
+
+// SetChannel sets the channel of the most recently decoded sample
+func (v *Decoder) SetChannel(channel int) error {
+	if channel < 0 || channel >= v.channels {
+		return ErrInvalidChannel
+	}
+
+	v.channel = channel
+
+	return nil
+}
+
+// Decompress decodes a complete ADPCM stream, starting from the shift and initial
+// samples in its header, and returns little-endian 16-bit samples
+func (v *Decoder) Decompress(data []byte) ([]byte, error) {
+	samples, err := v.DecompressSamples(data)
+	if err != nil {
+		return nil, err
+	}
+
+	return samplesToBytes(samples), nil
+}
+
+// DecompressSamples decodes a complete ADPCM stream like Decompress, returning the
+// samples without serializing them to bytes
+func (v *Decoder) DecompressSamples(data []byte) ([]int16, error) {
+	input := bitio.CreateStreamReader(data)
+
+	_, err := input.ReadByte()
+	if err != nil {
+		return nil, err
+	}
+
+	v.shift, err = input.ReadByte()
+	if err != nil {
+		return nil, err
+	}
+
+	// every code byte produces at most one sample
+	output := make([]int16, 0, len(data))
+
+	for i := 0; i < v.channels; i++ {
+		temp, err := input.ReadInt16()
+		if err != nil {
+			return nil, err
+		}
+
+		v.state[i] = ChannelState{Predictor: int(temp), StepIndex: initialStepIndex}
+		output = append(output, temp)
+	}
+
+	v.channel = v.channels - 1
+
+	return v.decode(data[input.Position():], output), nil
+}
+
+// Decode decodes ADPCM bytes following the stream header using the current state,
+// and returns little-endian 16-bit samples
+func (v *Decoder) Decode(body []byte) []byte {
+	return samplesToBytes(v.decode(body, make([]int16, 0, len(body))))
+}
+
+// DecodeSamples decodes ADPCM bytes following the stream header using the current
+// state, appending the samples to dst and returning the extended slice. Passing a
+// dst with enough capacity, len(body) samples at most, avoids allocating.
+func (v *Decoder) DecodeSamples(dst []int16, body []byte) []int16 {
+	return v.decode(body, dst)
+}
+
+//nolint:gomnd,funlen,gocognit,gocyclo // binary decode magic
+func (v *Decoder) decode(body []byte, output []int16) []int16 {
+	shift := v.shift
+	channel := v.channel
+
+	for _, value := range body {
+		if v.channels == 2 {
+			channel = 1 - channel
+		}
+
+		state := &v.state[channel]
+
+		if (value & 0x80) != 0 {
+			switch value & 0x7f {
+			case 0:
+				if state.StepIndex != 0 {
+					state.StepIndex--
+				}
+
+				output = append(output, int16(state.Predictor))
+			case 1:
+				state.StepIndex += 8
+				if state.StepIndex > maxStepIndex {
+					state.StepIndex = maxStepIndex
+				}
+
+				if v.channels == 2 {
+					channel = 1 - channel
+				}
+			case 2:
+			default:
+				state.StepIndex -= 8
+				if state.StepIndex < 0 {
+					state.StepIndex = 0
+				}
+
+				if v.channels == 2 {
+					channel = 1 - channel
+				}
+			}
+
+			continue
+		}
+
+		temp1 := sLookup[state.StepIndex]
+		temp2 := temp1 >> shift
+
+		if (value & 1) != 0 {
+			temp2 += temp1 >> 0
+		}
+		if (value & 2) != 0 {
+			temp2 += temp1 >> 1
+		}
+		if (value & 4) != 0 {
+			temp2 += temp1 >> 2
+		}
+		if (value & 8) != 0 {
+			temp2 += temp1 >> 3
+		}
+		if (value & 0x10) != 0 {
+			temp2 += temp1 >> 4
+		}
+		if (value & 0x20) != 0 {
+			temp2 += temp1 >> 5
+		}
+
+		temp3 := state.Predictor
+		if (value & 0x40) != 0 {
+			temp3 -= temp2
+			if temp3 <= minInt16 {
+				temp3 = minInt16
+			}
+		} else {
+			temp3 += temp2
+			if temp3 >= maxInt16 {
+				temp3 = maxInt16
+			}
+		}
+
+		state.Predictor = temp3
+		output = append(output, int16(temp3))
+
+		state.StepIndex = clampInt(state.StepIndex+sLookup2[value&0x1f], 0, maxStepIndex)
+	}
+
+	v.channel = channel
+
+	return output
+}
+
+// clampInt limits value to the range [low, high]
+func clampInt(value, low, high int) int {
+	if value < low {
+		return low
+	}
+
+	if value > high {
+		return high
+	}
+
+	return value
+}
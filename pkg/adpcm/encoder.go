@@ -0,0 +1,221 @@
+package adpcm
+
+import (
+	"errors"
+	"fmt"
+	"math"
+
+	"github.com/gravestench/wav/pkg/bitio"
+)
+
+// ADPCM compression levels. The level is the number of bits kept from each sample
+// difference, so higher levels trade size for fidelity. StormLib uses levels 4 to 6.
+const (
+	LevelMin      = 2
+	QualityLow    = 4
+	QualityMedium = 5
+	QualityHigh   = 6
+	LevelMax      = 7
+)
+
+// ErrCompressionLevel is returned for ADPCM compression levels outside LevelMin to LevelMax
+var ErrCompressionLevel = errors.New("invalid ADPCM compression level")
+
+// EncoderOption configures an Encoder
+type EncoderOption func(*encoderOptions)
+
+type encoderOptions struct {
+	level        int
+	noiseShaping float64
+}
+
+// WithLevel sets the compression level, such as QualityLow for short UI
+// sounds or QualityHigh for music. The default is QualityMedium.
+func WithLevel(level int) EncoderOption {
+	return func(o *encoderOptions) {
+		o.level = level
+	}
+}
+
+// WithNoiseShaping feeds back the quantization error of each sample into the
+// next one, moving the noise from the low and mid frequencies, where it is most
+// audible, towards the top of the spectrum. strength ranges from 0, which disables
+// it, to 1 for full first order shaping. The total noise grows, so SNR drops even
+// though the result usually sounds cleaner.
+func WithNoiseShaping(strength float64) EncoderOption {
+	return func(o *encoderOptions) {
+		o.noiseShaping = math.Max(0, math.Min(1, strength))
+	}
+}
+
+// Encoder encodes 16-bit PCM as Blizzard ADPCM
+type Encoder struct {
+	channels     int
+	level        int
+	noiseShaping float64
+	channel      int
+	state        [2]ChannelState
+	// errors holds the last quantization error of each channel for noise shaping
+	errors [2]int
+}
+
+// CreateEncoder creates an encoder for mono or stereo audio
+func CreateEncoder(channelCount int, opts ...EncoderOption) (*Encoder, error) {
+	if channelCount < 1 || channelCount > 2 {
+		return nil, ErrChannelCount
+	}
+
+	options := encoderOptions{level: QualityMedium}
+	for _, opt := range opts {
+		opt(&options)
+	}
+
+	if options.level < LevelMin || options.level > LevelMax {
+		return nil, fmt.Errorf("%w: %d", ErrCompressionLevel, options.level)
+	}
+
+	result := &Encoder{
+		channels:     channelCount,
+		level:        options.level,
+		noiseShaping: options.noiseShaping,
+	}
+
+	return result, nil
+}
+
+// Level returns the compression level
+func (v *Encoder) Level() int {
+	return v.level
+}
+
+// Shift returns the bit shift written to the stream header
+func (v *Encoder) Shift() byte {
+	return byte(v.level - 1)
+}
+
+// Compress encodes little-endian 16-bit PCM as a complete ADPCM stream, starting
+// with the header holding the shift and the initial sample of each channel
+func (v *Encoder) Compress(data []byte) []byte {
+	input := bitio.CreateStreamReader(data)
+	output := bitio.CreateStreamWriterSize(len(data)/2 + 2*bytesPerint16) //nolint:gomnd // header
+
+	output.PushBytes(0, v.Shift())
+
+	for i := 0; i < v.channels; i++ {
+		sample, err := input.ReadInt16()
+		if err != nil {
+			return output.GetBytes()
+		}
+
+		v.state[i] = ChannelState{Predictor: int(sample), StepIndex: initialStepIndex}
+		v.errors[i] = 0
+		output.PushInt16(sample)
+	}
+
+	v.channel = v.channels - 1
+
+	for {
+		sample, err := input.ReadInt16()
+		if err != nil {
+			break
+		}
+
+		v.encodeShaped(sample, output)
+	}
+
+	return output.GetBytes()
+}
+
+// encodeShaped encodes the next sample, first subtracting the weighted quantization
+// error of the previous sample on the channel when noise shaping is enabled
+func (v *Encoder) encodeShaped(sample int16, output *bitio.StreamWriter) {
+	if v.noiseShaping == 0 {
+		v.encode(int(sample), output)
+		return
+	}
+
+	channel := (v.channel + 1) % v.channels
+	target := clampInt(int(sample)-int(math.Round(v.noiseShaping*float64(v.errors[channel]))), minInt16, maxInt16)
+
+	v.encode(target, output)
+
+	v.errors[channel] = v.state[channel].Predictor - target
+}
+
+// encode writes the codes for the next sample, which belongs to the channel after
+// the previous one
+//
+//nolint:gomnd // binary encode magic
+func (v *Encoder) encode(sample int, output *bitio.StreamWriter) {
+	v.channel = (v.channel + 1) % v.channels
+	state := &v.state[v.channel]
+	bitShift := v.level - 1
+
+	encoded := 0
+	difference := sample - state.Predictor
+
+	if difference < 0 {
+		difference = -difference
+		encoded |= 0x40
+	}
+
+	stepSize := sLookup[state.StepIndex]
+
+	// too small a difference to encode, lower the step size and repeat the last sample
+	if difference < stepSize>>v.level {
+		if state.StepIndex != 0 {
+			state.StepIndex--
+		}
+
+		output.PushBytes(0x80)
+
+		return
+	}
+
+	// too large a difference, raise the step size until it can be encoded
+	for difference > stepSize<<1 && state.StepIndex < maxStepIndex {
+		state.StepIndex += 8
+		if state.StepIndex > maxStepIndex {
+			state.StepIndex = maxStepIndex
+		}
+
+		stepSize = sLookup[state.StepIndex]
+
+		output.PushBytes(0x81)
+	}
+
+	maxBitMask := 1 << (bitShift - 1)
+	if maxBitMask > 0x20 {
+		maxBitMask = 0x20
+	}
+
+	total := stepSize >> bitShift
+	accumulated := 0
+
+	for bit := 0x01; bit <= maxBitMask; bit <<= 1 {
+		if accumulated+stepSize <= difference {
+			accumulated += stepSize
+			encoded |= bit
+		}
+
+		stepSize >>= 1
+	}
+
+	total += accumulated
+
+	if encoded&0x40 != 0 {
+		state.Predictor -= total
+		if state.Predictor <= minInt16 {
+			state.Predictor = minInt16
+		}
+	} else {
+		state.Predictor += total
+		if state.Predictor >= maxInt16 {
+			state.Predictor = maxInt16
+		}
+	}
+
+	output.PushBytes(byte(encoded))
+
+	state.StepIndex = clampInt(state.StepIndex+sLookup2[encoded&0x1f], 0, maxStepIndex)
+}
@@ -0,0 +1,285 @@
+package adpcm
+
+import (
+	"bufio"
+	"errors"
+	"io"
+	"sort"
+
+	"github.com/gravestench/wav/pkg/bitio"
+)
+
+const (
+	adpcmIndexVersion    = 1
+	adpcmIndexHeaderSize = 4 + 3 + 8 + 4
+	adpcmIndexChunk      = 4096
+	adpcmIndexPointSize  = 8 + 8 + 1
+	adpcmIndexStateSize  = 2 + 1
+)
+
+// Errors returned when building or using an index
+var (
+	ErrIndex        = errors.New("invalid ADPCM index")
+	ErrInvalidRange = errors.New("invalid frame range")
+)
+
+// indexMagic starts a serialized ADPCM index
+//
+//nolint:gochecknoglobals // magic bytes
+var indexMagic = [4]byte{'A', 'D', 'I', 'X'}
+
+// SeekPoint is the decoder state at a frame boundary of an ADPCM stream, from
+// which decoding can resume without reading what comes before
+type SeekPoint struct {
+	// Frame is the index of the next sample frame decoded from Offset
+	Frame int64
+	// Offset is the byte offset in the stream, including its header
+	Offset int64
+	// Channel is the channel of the most recently decoded sample
+	Channel int
+	// State is the predictor state of each channel
+	State [2]ChannelState
+}
+
+// Index maps sample frames of an ADPCM stream to seek points, so that a range
+// of frames can be decoded without decoding the stream from its start
+type Index struct {
+	Channels int
+	Shift    byte
+	// Frames is the number of sample frames in the stream
+	Frames int64
+	// Points are in frame order; the first follows the stream header
+	Points []SeekPoint
+}
+
+// BuildIndex scans the ADPCM stream read from r once, recording a seek point
+// every interval frames
+func BuildIndex(r io.Reader, channelCount, interval int) (*Index, error) {
+	decoder, err := CreateDecoder(channelCount)
+	if err != nil {
+		return nil, err
+	}
+
+	if interval <= 0 {
+		return nil, ErrInvalidRange
+	}
+
+	input := bufio.NewReader(r)
+
+	header := make([]byte, bytesPerint16+channelCount*bytesPerint16)
+	if _, err = io.ReadFull(input, header); err != nil {
+		return nil, err
+	}
+
+	if _, err = decoder.DecompressSamples(header); err != nil {
+		return nil, err
+	}
+
+	result := &Index{
+		Channels: channelCount,
+		Shift:    decoder.shift,
+	}
+
+	offset := int64(len(header))
+	samples := int64(channelCount)
+	next := samples
+	step := int64(interval) * int64(channelCount)
+	code := make([]byte, 1)
+	scratch := make([]int16, 0, 1)
+
+	for {
+		if samples == next {
+			result.Points = append(result.Points, SeekPoint{
+				Frame:   samples / int64(channelCount),
+				Offset:  offset,
+				Channel: decoder.channel,
+				State:   decoder.state,
+			})
+
+			next += step
+		}
+
+		if code[0], err = input.ReadByte(); err != nil {
+			break
+		}
+
+		samples += int64(len(decoder.decode(code, scratch[:0])))
+		offset++
+	}
+
+	if !errors.Is(err, io.EOF) {
+		return nil, err
+	}
+
+	result.Frames = samples / int64(channelCount)
+
+	return result, nil
+}
+
+// SeekPoint returns the last seek point at or before frame, which is the first point for
+// frames before it
+func (v *Index) SeekPoint(frame int64) SeekPoint {
+	i := sort.Search(len(v.Points), func(i int) bool {
+		return v.Points[i].Frame > frame
+	})
+
+	if i == 0 {
+		return v.Points[0]
+	}
+
+	return v.Points[i-1]
+}
+
+// DecodeRange decodes count sample frames of the indexed stream starting at frame,
+// reading r from the nearest seek point. Fewer frames are returned at the end of
+// the stream.
+func (v *Index) DecodeRange(r io.ReaderAt, frame, count int64) ([]int16, error) {
+	if len(v.Points) == 0 || frame < 0 || count < 0 {
+		return nil, ErrInvalidRange
+	}
+
+	if frame+count > v.Frames {
+		count = v.Frames - frame
+	}
+
+	if count <= 0 {
+		return nil, nil
+	}
+
+	channels := int64(v.Channels)
+	result := make([]int16, 0, count*channels)
+
+	if frame == 0 {
+		// the first frame is stored in the stream header, as the initial predictors
+		for ch := 0; ch < v.Channels; ch++ {
+			result = append(result, int16(v.Points[0].State[ch].Predictor))
+		}
+
+		frame++
+		count--
+	}
+
+	point := v.SeekPoint(frame)
+	skip := (frame - point.Frame) * channels
+	want := skip + count*channels
+
+	state := State{Channels: v.Channels, Shift: v.Shift, Channel: point.Channel, State: point.State}
+	block := make([]byte, adpcmIndexChunk)
+	decoded := make([]int16, 0, want)
+
+	for offset := point.Offset; int64(len(decoded)) < want; {
+		n, readErr := r.ReadAt(block, offset)
+		offset += int64(n)
+
+		var err error
+		if decoded, err = DecodeBlockTo(decoded, block[:n], &state); err != nil {
+			return nil, err
+		}
+
+		if errors.Is(readErr, io.EOF) {
+			break
+		}
+
+		if readErr != nil {
+			return nil, readErr
+		}
+	}
+
+	if int64(len(decoded)) > want {
+		decoded = decoded[:want]
+	}
+
+	if int64(len(decoded)) > skip {
+		result = append(result, decoded[skip:]...)
+	}
+
+	return result, nil
+}
+
+// MarshalBinary implements encoding.BinaryMarshaler, serializing the index so it
+// can be cached alongside the stream
+func (v *Index) MarshalBinary() ([]byte, error) {
+	w := bitio.CreateStreamWriterSize(adpcmIndexHeaderSize + len(v.Points)*(adpcmIndexPointSize+v.Channels*adpcmIndexStateSize))
+
+	w.PushFourCC(indexMagic)
+	w.PushBytes(adpcmIndexVersion, byte(v.Channels), v.Shift)
+	w.PushUint64(uint64(v.Frames))
+	w.PushUint32(uint32(len(v.Points)))
+
+	for _, point := range v.Points {
+		w.PushUint64(uint64(point.Frame))
+		w.PushUint64(uint64(point.Offset))
+		w.PushBytes(byte(point.Channel))
+
+		for ch := 0; ch < v.Channels; ch++ {
+			w.PushInt16(int16(point.State[ch].Predictor))
+			w.PushBytes(byte(point.State[ch].StepIndex))
+		}
+	}
+
+	return w.GetBytes(), nil
+}
+
+// UnmarshalBinary implements encoding.BinaryUnmarshaler, restoring an index written
+// by MarshalBinary
+func (v *Index) UnmarshalBinary(data []byte) error {
+	r := bitio.CreateStreamReader(data)
+
+	magic, err := r.ReadFourCC()
+	if err != nil || magic != indexMagic {
+		return ErrIndex
+	}
+
+	fields, err := r.ReadBytes(3) //nolint:gomnd // version, channels and shift
+	if err != nil || fields[0] != adpcmIndexVersion || fields[1] < 1 || fields[1] > 2 {
+		return ErrIndex
+	}
+
+	frames, _ := r.ReadUInt64()
+
+	count, err := r.ReadUInt32()
+	if err != nil {
+		return ErrIndex
+	}
+
+	channels := int(fields[1])
+	pointSize := uint64(adpcmIndexPointSize + channels*adpcmIndexStateSize)
+
+	if count == 0 || uint64(count)*pointSize != r.Size()-r.Position() {
+		return ErrIndex
+	}
+
+	result := Index{
+		Channels: channels,
+		Shift:    fields[2],
+		Frames:   int64(frames),
+		Points:   make([]SeekPoint, count),
+	}
+
+	for i := range result.Points {
+		point := &result.Points[i]
+
+		frame, _ := r.ReadUInt64()
+		offset, _ := r.ReadUInt64()
+		channel, _ := r.ReadByte()
+
+		point.Frame = int64(frame)
+		point.Offset = int64(offset)
+		point.Channel = int(channel)
+
+		for ch := 0; ch < channels; ch++ {
+			predictor, _ := r.ReadInt16()
+			stepIndex, _ := r.ReadByte()
+
+			point.State[ch] = ChannelState{Predictor: int(predictor), StepIndex: int(stepIndex)}
+		}
+
+		if point.Channel >= channels || point.State[0].StepIndex > maxStepIndex || point.State[1].StepIndex > maxStepIndex {
+			return ErrIndex
+		}
+	}
+
+	*v = result
+
+	return nil
+}
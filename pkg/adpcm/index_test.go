@@ -0,0 +1,114 @@
+package adpcm_test
+
+import (
+	"bytes"
+	"math"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/gravestench/wav/pkg/adpcm"
+)
+
+const (
+	testRate      = 22050
+	indexInterval = 1000
+)
+
+// sinePCM returns d of a 440 Hz sine at half scale as interleaved little-endian
+// 16-bit samples
+func sinePCM(channels int, d time.Duration) []byte {
+	frames := int(d * testRate / time.Second)
+	result := make([]byte, frames*channels*2)
+
+	for i := 0; i < len(result); i += 2 {
+		phase := 2 * math.Pi * 440 * float64(i/2/channels) / testRate
+		sample := int16(math.Sin(phase) * math.MaxInt16 / 2)
+		result[i] = byte(sample)
+		result[i+1] = byte(uint16(sample) >> 8)
+	}
+
+	return result
+}
+
+// TestIndexDecodeRange decodes ranges of a stream from its seek points, which
+// must match the same frames of the whole stream decoded from its start
+func TestIndexDecodeRange(t *testing.T) {
+	for _, channels := range []int{1, 2} {
+		encoder, err := adpcm.CreateEncoder(channels)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		stream := encoder.Compress(sinePCM(channels, time.Second))
+
+		decoder, err := adpcm.CreateDecoder(channels)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		whole, err := decoder.DecompressSamples(stream)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		index, err := adpcm.BuildIndex(bytes.NewReader(stream), channels, indexInterval)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		frames := int64(len(whole) / channels)
+		if index.Frames != frames {
+			t.Fatalf("%d channels: indexed %d frames, want %d", channels, index.Frames, frames)
+		}
+
+		for _, r := range [][2]int64{{0, 10}, {1, 1}, {999, 3}, {1000, 1}, {4321, 1500}, {frames - 5, 10}} {
+			got, err := index.DecodeRange(bytes.NewReader(stream), r[0], r[1])
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			end := r[0] + r[1]
+			if end > frames {
+				end = frames
+			}
+
+			if want := whole[r[0]*int64(channels) : end*int64(channels)]; !reflect.DeepEqual(got, want) {
+				t.Errorf("%d channels: frames %d+%d differ from the whole stream", channels, r[0], r[1])
+			}
+		}
+	}
+}
+
+// TestIndexMarshal serializes an index and reads it back unchanged
+func TestIndexMarshal(t *testing.T) {
+	encoder, err := adpcm.CreateEncoder(2)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	stream := encoder.Compress(sinePCM(2, time.Second))
+
+	index, err := adpcm.BuildIndex(bytes.NewReader(stream), 2, indexInterval)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	data, err := index.MarshalBinary()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var got adpcm.Index
+	if err = got.UnmarshalBinary(data); err != nil {
+		t.Fatal(err)
+	}
+
+	if !reflect.DeepEqual(&got, index) {
+		t.Errorf("unmarshaled %+v, want %+v", got, *index)
+	}
+
+	if err = got.UnmarshalBinary(data[:len(data)-1]); err == nil {
+		t.Error("unmarshaled a truncated index")
+	}
+}
@@ -0,0 +1,91 @@
+package adpcm
+
+import (
+	"io"
+)
+
+// readerChunk is the number of ADPCM bytes decoded at a time
+const readerChunk = 4096
+
+// reader decodes an ADPCM stream as it is read into little-endian 16-bit
+// samples, holding fixed size buffers
+type reader struct {
+	src     io.Reader
+	decoder *Decoder
+	started bool
+	in      []byte
+	samples []int16
+	out     []byte
+	pending []byte
+	err     error
+}
+
+// CreateReader creates a reader decompressing the ADPCM stream read from r,
+// like Decompress but streaming, so memory use doesn't grow with the length of
+// the stream
+func CreateReader(r io.Reader, channelCount int) (io.Reader, error) {
+	decoder, err := CreateDecoder(channelCount)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &reader{
+		src:     r,
+		decoder: decoder,
+		in:      make([]byte, readerChunk),
+		samples: make([]int16, 0, readerChunk),
+		out:     make([]byte, readerChunk*bytesPerint16),
+	}
+
+	return result, nil
+}
+
+// start decodes the stream header, producing the initial samples
+func (v *reader) start() error {
+	header := v.in[:bytesPerint16+v.decoder.channels*bytesPerint16]
+	if _, err := io.ReadFull(v.src, header); err != nil {
+		return err
+	}
+
+	samples, err := v.decoder.DecompressSamples(header)
+	if err != nil {
+		return err
+	}
+
+	v.pending = v.out[:copy(v.out, samplesToBytes(samples))]
+
+	return nil
+}
+
+// Read implements io.Reader
+func (v *reader) Read(p []byte) (int, error) {
+	if !v.started {
+		v.started = true
+
+		if err := v.start(); err != nil {
+			v.err = err
+		}
+	}
+
+	for len(v.pending) == 0 {
+		if v.err != nil {
+			return 0, v.err
+		}
+
+		n, err := v.src.Read(v.in)
+		v.err = err
+
+		v.samples = v.decoder.DecodeSamples(v.samples[:0], v.in[:n])
+		for i, sample := range v.samples {
+			v.out[i*2] = byte(sample)
+			v.out[i*2+1] = byte(uint16(sample) >> bitsPerByte)
+		}
+
+		v.pending = v.out[:len(v.samples)*bytesPerint16]
+	}
+
+	n := copy(p, v.pending)
+	v.pending = v.pending[n:]
+
+	return n, nil
+}
@@ -0,0 +1,215 @@
+package adpcm
+
+import (
+	"errors"
+	"fmt"
+	"math"
+)
+
+// IMA ADPCM and Microsoft ADPCM, the block formats WAV files store ADPCM in. Every
+// block starts with a header per channel holding the decoder state, so each block
+// decodes on its own and a decoder can seek to any of them.
+
+// Sizes of the per-channel block headers
+const (
+	IMAHeaderSize = 4
+	MSHeaderSize  = 7
+)
+
+const (
+	// imaWordSize is the number of code bytes of one channel before the next
+	// channel's, holding imaWordSamples samples
+	imaWordSize    = 4
+	imaWordSamples = imaWordSize * 2
+	// msHeaderFrames is the number of frames stored uncompressed in a Microsoft
+	// ADPCM block header
+	msHeaderFrames = 2
+	msMinDelta     = 16
+	// msMaxDelta keeps the delta of malformed blocks from overflowing, as ffmpeg does
+	msMaxDelta      = math.MaxInt32 / 768
+	nibbleBits      = 4
+	nibbleMask      = 0x0F
+	nibbleSign      = 0x08
+	coefficientBits = 8
+)
+
+// Errors returned while decoding IMA and Microsoft ADPCM blocks
+var (
+	ErrBlockHeader = errors.New("ADPCM block is shorter than its header")
+	ErrPredictor   = errors.New("invalid Microsoft ADPCM predictor")
+)
+
+// MSCoefficients holds the predictor coefficient pairs every Microsoft ADPCM file
+// starts its fmt chunk list with, which are used when the fmt chunk has none
+//
+//nolint:gochecknoglobals // lookup table
+var MSCoefficients = [][2]int{
+	{256, 0}, {512, -256}, {0, 0}, {192, 64}, {240, 0}, {460, -208}, {392, -232},
+}
+
+// imaIndexAdjust holds the step index adjustments of IMA ADPCM indexed by code
+//
+//nolint:gochecknoglobals // lookup table
+var imaIndexAdjust = [16]int{-1, -1, -1, -1, 2, 4, 6, 8, -1, -1, -1, -1, 2, 4, 6, 8}
+
+// msAdaptation holds the factors scaling the Microsoft ADPCM delta indexed by code
+//
+//nolint:gochecknoglobals // lookup table
+var msAdaptation = [16]int{230, 230, 230, 230, 307, 409, 512, 614, 768, 614, 512, 409, 307, 230, 230, 230}
+
+// IMAFramesPerBlock returns the number of sample frames an IMA ADPCM block of size
+// bytes holds, counting only whole code words of a final short block, or 0 if it
+// doesn't even hold its header
+func IMAFramesPerBlock(size, channels int) int {
+	codes := size - IMAHeaderSize*channels
+	if channels < 1 || codes < 0 {
+		return 0
+	}
+
+	return 1 + codes/(imaWordSize*channels)*imaWordSamples
+}
+
+// MSFramesPerBlock returns the number of sample frames a Microsoft ADPCM block of
+// size bytes holds, or 0 if it doesn't even hold its header
+func MSFramesPerBlock(size, channels int) int {
+	codes := size - MSHeaderSize*channels
+	if channels < 1 || codes < 0 {
+		return 0
+	}
+
+	return msHeaderFrames + codes*2/channels
+}
+
+// DecodeIMABlock decodes an IMA ADPCM block of interleaved channels, appending the
+// samples to dst and returning the extended slice. No memory is allocated when dst
+// has room for IMAFramesPerBlock frames.
+func DecodeIMABlock(dst []int16, block []byte, channels int) ([]int16, error) {
+	frames := IMAFramesPerBlock(len(block), channels)
+	if frames == 0 {
+		return dst, ErrBlockHeader
+	}
+
+	start := len(dst)
+	dst = grow(dst, frames*channels)
+	out := dst[start:]
+	codes := block[IMAHeaderSize*channels:]
+
+	for ch := 0; ch < channels; ch++ {
+		header := block[ch*IMAHeaderSize:]
+		predictor := int(int16(uint16(header[0]) | uint16(header[1])<<bitsPerByte))
+		stepIndex := clampInt(int(header[2]), 0, maxStepIndex)
+		out[ch] = int16(predictor)
+
+		for word := 0; word < (frames-1)/imaWordSamples; word++ {
+			words := codes[(word*channels+ch)*imaWordSize:]
+
+			for i := 0; i < imaWordSamples; i++ {
+				code := int(words[i/2]>>(uint(i%2)*nibbleBits)) & nibbleMask
+				predictor, stepIndex = imaDecode(code, predictor, stepIndex)
+				out[(1+word*imaWordSamples+i)*channels+ch] = int16(predictor)
+			}
+		}
+	}
+
+	return dst, nil
+}
+
+// imaDecode applies an IMA ADPCM code to the predictor and step index
+func imaDecode(code, predictor, stepIndex int) (int, int) {
+	step := sLookup[stepIndex]
+	difference := step >> 3
+
+	if code&4 != 0 {
+		difference += step
+	}
+
+	if code&2 != 0 {
+		difference += step >> 1
+	}
+
+	if code&1 != 0 {
+		difference += step >> 2
+	}
+
+	if code&nibbleSign != 0 {
+		difference = -difference
+	}
+
+	predictor = clampInt(predictor+difference, minInt16, maxInt16)
+	stepIndex = clampInt(stepIndex+imaIndexAdjust[code], 0, maxStepIndex)
+
+	return predictor, stepIndex
+}
+
+// DecodeMSBlock decodes a Microsoft ADPCM block of interleaved channels with the given
+// predictor coefficients, or MSCoefficients if nil, appending the samples to dst and
+// returning the extended slice. No memory is allocated when dst has room for
+// MSFramesPerBlock frames.
+func DecodeMSBlock(dst []int16, block []byte, channels int, coefficients [][2]int) ([]int16, error) {
+	frames := MSFramesPerBlock(len(block), channels)
+	if frames == 0 {
+		return dst, ErrBlockHeader
+	}
+
+	if coefficients == nil {
+		coefficients = MSCoefficients
+	}
+
+	start := len(dst)
+	dst = grow(dst, frames*channels)
+	out := dst[start:]
+	codes := block[MSHeaderSize*channels:]
+
+	for ch := 0; ch < channels; ch++ {
+		predictor := int(block[ch])
+		if predictor >= len(coefficients) {
+			return dst[:start], fmt.Errorf("%w: %d", ErrPredictor, predictor)
+		}
+
+		// the predictor bytes are followed by the delta, sample1 and sample2 of every channel
+		fields := block[channels:]
+		coefficient1, coefficient2 := coefficients[predictor][0], coefficients[predictor][1]
+		delta := readInt16(fields[ch*bytesPerint16:])
+		sample1 := readInt16(fields[(channels+ch)*bytesPerint16:])
+		sample2 := readInt16(fields[(2*channels+ch)*bytesPerint16:])
+
+		// the older sample is played first
+		out[ch] = int16(sample2)
+		out[channels+ch] = int16(sample1)
+
+		for frame := msHeaderFrames; frame < frames; frame++ {
+			// codes alternate between the channels, high nibble first
+			index := (frame-msHeaderFrames)*channels + ch
+			code := int(codes[index/2]>>(uint(1-index%2)*nibbleBits)) & nibbleMask
+
+			signed := code
+			if code&nibbleSign != 0 {
+				signed -= 1 << nibbleBits
+			}
+
+			prediction := (sample1*coefficient1 + sample2*coefficient2) >> coefficientBits
+			sample := clampInt(prediction+signed*delta, minInt16, maxInt16)
+			sample2, sample1 = sample1, sample
+			out[frame*channels+ch] = int16(sample)
+
+			delta = msAdaptation[code] * delta >> coefficientBits
+			delta = clampInt(delta, msMinDelta, msMaxDelta)
+		}
+	}
+
+	return dst, nil
+}
+
+// readInt16 reads a little-endian signed 16-bit value
+func readInt16(b []byte) int {
+	return int(int16(uint16(b[0]) | uint16(b[1])<<bitsPerByte))
+}
+
+// grow extends dst by n samples, reusing its spare capacity when there is enough
+func grow(dst []int16, n int) []int16 {
+	if cap(dst)-len(dst) >= n {
+		return dst[:len(dst)+n]
+	}
+
+	return append(dst, make([]int16, n)...)
+}
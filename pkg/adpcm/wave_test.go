@@ -0,0 +1,102 @@
+package adpcm_test
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/gravestench/wav/pkg/adpcm"
+)
+
+// imaCodes are the code words of the IMA ADPCM test blocks, decoded by Python's
+// audioop from the states of imaHeaders into imaMono and imaSecond
+//
+//nolint:gochecknoglobals // test vectors
+var (
+	imaCodes   = []byte{0x07, 0x70, 0x3f, 0xf3, 0x12, 0x9a, 0x80, 0x08}
+	imaHeaders = [][]byte{{0x00, 0x00, 0, 0}, {0x18, 0xfc, 40, 0}}
+	imaMono    = []int16{0, 11, 13, 14, 37, -15, 37, 83, -10, 56, 92, 37, 7, 16, 8, 1, 7}
+	imaSecond  = []int16{
+		-1000, -369, -279, -197, 923, -1480, 924, 3109, -1151, 1892, 3552, 1036, -336, 79, -299, -642, -330,
+	}
+)
+
+// interleave returns the samples of two channels as frames
+func interleave(first, second []int16) []int16 {
+	result := make([]int16, 0, len(first)*2)
+	for i := range first {
+		result = append(result, first[i], second[i])
+	}
+
+	return result
+}
+
+func TestDecodeIMABlock(t *testing.T) {
+	mono := append(append([]byte(nil), imaHeaders[0]...), imaCodes...)
+
+	got, err := adpcm.DecodeIMABlock(nil, mono, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !reflect.DeepEqual(got, imaMono) {
+		t.Errorf("decoded %v, want %v", got, imaMono)
+	}
+
+	// stereo blocks interleave the channels a code word of 4 bytes at a time
+	var stereo []byte
+
+	stereo = append(append(stereo, imaHeaders[0]...), imaHeaders[1]...)
+	stereo = append(append(stereo, imaCodes[:4]...), imaCodes[:4]...)
+	stereo = append(append(stereo, imaCodes[4:]...), imaCodes[4:]...)
+
+	got, err = adpcm.DecodeIMABlock(nil, stereo, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if want := interleave(imaMono, imaSecond); !reflect.DeepEqual(got, want) {
+		t.Errorf("decoded %v, want %v", got, want)
+	}
+
+	if _, err = adpcm.DecodeIMABlock(nil, stereo[:7], 2); !errors.Is(err, adpcm.ErrBlockHeader) {
+		t.Errorf("decoding a short block returned %v, want %v", err, adpcm.ErrBlockHeader)
+	}
+}
+
+func TestDecodeMSBlock(t *testing.T) {
+	// predictor 1, delta 40, then the second and first sample
+	mono := []byte{1, 40, 0, 0xe8, 0x03, 0x84, 0x03, 0x12, 0xf8, 0x77, 0x09}
+	first := []int16{900, 1000, 1140, 1350, 1529, 1492, 2022, 3910, 5798, 4767}
+
+	got, err := adpcm.DecodeMSBlock(nil, mono, 1, adpcm.MSCoefficients)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !reflect.DeepEqual(got, first) {
+		t.Errorf("decoded %v, want %v", got, first)
+	}
+
+	// stereo headers interleave each field, and each code byte holds a code of both
+	// channels; the second channel uses predictor 5, delta 300, -2000 and -1500
+	stereo := []byte{
+		1, 5, 40, 0, 0x2c, 0x01, 0xe8, 0x03, 0x30, 0xf8, 0x84, 0x03, 0x24, 0xfa,
+		0x17, 0x2f, 0xf8, 0x80, 0x73, 0x71, 0x0c, 0x94,
+	}
+	second := []int16{-1500, -2000, -275, 411, -4199, -7880, -5534, -1981, -4672, -62}
+
+	got, err = adpcm.DecodeMSBlock(nil, stereo, 2, adpcm.MSCoefficients)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if want := interleave(first, second); !reflect.DeepEqual(got, want) {
+		t.Errorf("decoded %v, want %v", got, want)
+	}
+
+	mono[0] = byte(len(adpcm.MSCoefficients))
+	if _, err = adpcm.DecodeMSBlock(nil, mono, 1, adpcm.MSCoefficients); !errors.Is(err, adpcm.ErrPredictor) {
+		t.Errorf("decoding predictor %d returned %v, want %v", mono[0], err, adpcm.ErrPredictor)
+	}
+}
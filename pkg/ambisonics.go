@@ -0,0 +1,132 @@
+package pkg
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+)
+
+// ErrAmbisonics is returned for ambisonic conventions which can't be written
+var ErrAmbisonics = errors.New("unsupported ambisonic format")
+
+// ambGUIDSuffix follows the format tag in the sub format GUIDs of AMB files,
+// KSDATAFORMAT_SUBTYPE_AMBISONIC_B_FORMAT_PCM and _IEEE_FLOAT
+//
+//nolint:gochecknoglobals // GUID bytes
+var ambGUIDSuffix = []byte{0x00, 0x00, 0x21, 0x07, 0xd3, 0x11, 0x86, 0x44, 0xc8, 0xc1, 0xca, 0x00, 0x00, 0x00}
+
+const (
+	maxFuMaOrder    = 3
+	nonDiegeticPair = 2
+)
+
+// AmbisonicConvention is a channel ordering and normalization of ambisonic audio
+type AmbisonicConvention int
+
+// Ambisonic conventions
+const (
+	// AmbisonicFuMa is Furse-Malham ordering and normalization, used by AMB files
+	AmbisonicFuMa AmbisonicConvention = iota
+	// AmbisonicAmbiX is ACN ordering with SN3D normalization
+	AmbisonicAmbiX
+)
+
+// String returns the name of the convention
+func (v AmbisonicConvention) String() string {
+	if v == AmbisonicAmbiX {
+		return "ambiX (ACN/SN3D)"
+	}
+
+	return "FuMa"
+}
+
+// Ambisonics describes ambisonic audio
+type Ambisonics struct {
+	// Order is the ambisonic order; mixed order FuMa files report the highest order
+	Order      int
+	Convention AmbisonicConvention
+	// NonDiegetic is set when a head-locked stereo pair follows the ambisonic channels
+	NonDiegetic bool
+}
+
+// Channels returns the number of channels of full sphere audio of the order
+func (v Ambisonics) Channels() int {
+	result := (v.Order + 1) * (v.Order + 1)
+
+	if v.NonDiegetic {
+		result += nonDiegeticPair
+	}
+
+	return result
+}
+
+// Ambisonics reports whether the format holds ambisonic audio. AMB files are found by
+// their sub format GUID. WAVE_FORMAT_EXTENSIBLE files without speaker assignments and
+// with (order+1)² channels, optionally followed by a stereo pair, are taken to be ambiX.
+func (v Format) Ambisonics() (Ambisonics, bool) {
+	if v.FormatTag != FormatExtensible || len(v.Extension) < extensibleSize {
+		return Ambisonics{}, false
+	}
+
+	guid := v.Extension[extensibleSubFormatAt+bytesPerint16 : extensibleSize]
+	channels := int(v.Channels)
+
+	if bytes.Equal(guid, ambGUIDSuffix) {
+		order := 0
+		for (order+1)*(order+1) < channels {
+			order++
+		}
+
+		return Ambisonics{Order: order, Convention: AmbisonicFuMa}, true
+	}
+
+	if !bytes.Equal(guid, extensibleGUIDSuffix) || v.ChannelMask() != 0 {
+		return Ambisonics{}, false
+	}
+
+	for order := 1; (order+1)*(order+1) <= channels; order++ {
+		result := Ambisonics{Order: order, Convention: AmbisonicAmbiX}
+		if result.Channels() == channels {
+			return result, true
+		}
+
+		result.NonDiegetic = true
+		if result.Channels() == channels {
+			return result, true
+		}
+	}
+
+	return Ambisonics{}, false
+}
+
+// CreateAmbisonicFormat creates the Format of integer PCM ambisonic audio. FuMa audio
+// is written as an AMB file, up to third order; ambiX audio as WAVE_FORMAT_EXTENSIBLE
+// without speaker assignments.
+func CreateAmbisonicFormat(sampleRate, bitsPerSample int, ambisonics Ambisonics) (Format, error) {
+	if ambisonics.Order < 1 {
+		return Format{}, fmt.Errorf("%w: order %d", ErrAmbisonics, ambisonics.Order)
+	}
+
+	guid := extensibleGUIDSuffix
+
+	if ambisonics.Convention == AmbisonicFuMa {
+		if ambisonics.Order > maxFuMaOrder || ambisonics.NonDiegetic {
+			return Format{}, fmt.Errorf("%w: FuMa order %d", ErrAmbisonics, ambisonics.Order)
+		}
+
+		guid = ambGUIDSuffix
+	}
+
+	result := CreatePCMFormat(sampleRate, bitsPerSample, ambisonics.Channels())
+	result.FormatTag = FormatExtensible
+
+	w := CreateStreamWriterSize(extensibleSize)
+	w.PushUint16(uint16(bitsPerSample))
+	w.PushUint32(0)
+	w.PushUint16(FormatPCM)
+	w.PushBytes(guid...)
+
+	result.Extension = w.GetBytes()
+
+	return result, nil
+}
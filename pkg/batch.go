@@ -0,0 +1,201 @@
+package pkg
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime"
+	"sync"
+)
+
+const outputDirMode = 0o755
+
+// BatchJob is one input/output file pair converted by a Batch
+type BatchJob struct {
+	Input  string
+	Output string
+}
+
+// BatchFailure is a job of a Batch which failed to convert
+type BatchFailure struct {
+	Job BatchJob
+	Err error
+}
+
+// BatchError aggregates the failures of a Batch run
+type BatchError struct {
+	Failures []BatchFailure
+}
+
+// Error implements error
+func (v *BatchError) Error() string {
+	if len(v.Failures) == 1 {
+		return fmt.Sprintf("converting %s: %v", v.Failures[0].Job.Input, v.Failures[0].Err)
+	}
+
+	return fmt.Sprintf("%d of the batch files failed to convert, first %s: %v",
+		len(v.Failures), v.Failures[0].Job.Input, v.Failures[0].Err)
+}
+
+// Batch converts many files with Transcode across a pool of workers
+type Batch struct {
+	// Workers is the number of files converted at once, defaulting to the CPU count
+	Workers int
+	// Options are passed to Transcode for every file
+	Options []Option
+	// Progress, if set, is called after each file with the number of files done so
+	// far. Calls are never concurrent.
+	Progress func(done, total int, job BatchJob, err error)
+
+	jobs []BatchJob
+}
+
+// CreateBatch creates a Batch converting files with the given options
+func CreateBatch(workers int, opts ...Option) *Batch {
+	result := &Batch{
+		Workers: workers,
+		Options: opts,
+	}
+
+	return result
+}
+
+// Add queues the conversion of the input file to the output file
+func (v *Batch) Add(input, output string) {
+	v.jobs = append(v.jobs, BatchJob{Input: input, Output: output})
+}
+
+// Jobs returns the queued jobs
+func (v *Batch) Jobs() []BatchJob {
+	return append([]BatchJob(nil), v.jobs...)
+}
+
+// Run converts every queued file, continuing past failures. The returned error is a
+// *BatchError listing every failed job, in queue order, or nil if all succeeded.
+func (v *Batch) Run() error {
+	workers := v.Workers
+	if workers <= 0 {
+		workers = runtime.NumCPU()
+	}
+
+	errs := make([]error, len(v.jobs))
+
+	var (
+		mu   sync.Mutex
+		done int
+	)
+
+	parallel(len(v.jobs), workers, func(i int) {
+		errs[i] = TranscodeFile(v.jobs[i].Input, v.jobs[i].Output, v.Options...)
+
+		mu.Lock()
+		defer mu.Unlock()
+
+		done++
+
+		if v.Progress != nil {
+			v.Progress(done, len(v.jobs), v.jobs[i], errs[i])
+		}
+	})
+
+	var failures []BatchFailure
+
+	for i, err := range errs {
+		if err != nil {
+			failures = append(failures, BatchFailure{Job: v.jobs[i], Err: err})
+		}
+	}
+
+	if len(failures) == 0 {
+		return nil
+	}
+
+	return &BatchError{Failures: failures}
+}
+
+// ConvertResult is the outcome of converting one input of ConvertAll
+type ConvertResult struct {
+	// Output is the converted WAV file, or nil if the conversion failed
+	Output []byte
+	Err    error
+}
+
+// ConvertAll transcodes in-memory inputs, such as the sounds of a sound bank, with
+// up to workers conversions running at once, defaulting to the CPU count. The
+// results are in the order of the inputs, whichever finishes first.
+func ConvertAll(inputs [][]byte, workers int, opts ...Option) []ConvertResult {
+	if workers <= 0 {
+		workers = runtime.NumCPU()
+	}
+
+	results := make([]ConvertResult, len(inputs))
+
+	parallel(len(inputs), workers, func(i int) {
+		var output bytes.Buffer
+
+		if err := Transcode(bytes.NewReader(inputs[i]), &output, opts...); err != nil {
+			results[i].Err = err
+			return
+		}
+
+		results[i].Output = output.Bytes()
+	})
+
+	return results
+}
+
+// parallel calls fn with every index below count, from at most workers goroutines
+// at once, and returns when all calls are done
+func parallel(count, workers int, fn func(i int)) {
+	indexes := make(chan int)
+
+	var wg sync.WaitGroup
+
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+
+		go func() {
+			defer wg.Done()
+
+			for i := range indexes {
+				fn(i)
+			}
+		}()
+	}
+
+	for i := 0; i < count; i++ {
+		indexes <- i
+	}
+
+	close(indexes)
+	wg.Wait()
+}
+
+// TranscodeFile transcodes the input file into the output file, creating the
+// output directory if needed
+func TranscodeFile(input, output string, opts ...Option) (err error) {
+	src, err := os.Open(input) //nolint:gosec // opening caller supplied paths is the point
+	if err != nil {
+		return err
+	}
+
+	defer src.Close() //nolint:errcheck // read only
+
+	if err = os.MkdirAll(filepath.Dir(output), outputDirMode); err != nil {
+		return err
+	}
+
+	dst, err := os.Create(output) //nolint:gosec // creating caller supplied paths is the point
+	if err != nil {
+		return err
+	}
+
+	defer func() {
+		if closeErr := dst.Close(); err == nil {
+			err = closeErr
+		}
+	}()
+
+	return Transcode(src, dst, opts...)
+}
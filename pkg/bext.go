@@ -0,0 +1,122 @@
+package pkg
+
+const (
+	bextDescriptionSize = 256
+	bextOriginatorSize  = 32
+	bextReferenceSize   = 32
+	bextDateSize        = 10
+	bextTimeSize        = 8
+	bextUMIDSize        = 64
+	bextLoudnessCount   = 5
+	bextReservedSize    = 180
+	bextChunkMinSize    = 602
+)
+
+// Bext holds the contents of a bext chunk, the Broadcast Audio Extension of BWF
+// (EBU Tech 3285)
+type Bext struct {
+	Description         string
+	Originator          string
+	OriginatorReference string
+	// OriginationDate is formatted as yyyy-mm-dd, OriginationTime as hh:mm:ss
+	OriginationDate string
+	OriginationTime string
+	// TimeReference is the position of the first sample in sample frames since midnight
+	TimeReference uint64
+	Version       uint16
+	UMID          [bextUMIDSize]byte
+	// Loudness values of version 2, in hundredths of LU, LUFS or dBTP
+	LoudnessValue        int16
+	LoudnessRange        int16
+	MaxTruePeakLevel     int16
+	MaxMomentaryLoudness int16
+	MaxShortTermLoudness int16
+	// CodingHistory describes the processing the audio went through, one line per step
+	CodingHistory string
+}
+
+// parseBext parses the body of a bext chunk
+func parseBext(body []byte) (*Bext, error) {
+	if len(body) < bextChunkMinSize {
+		return nil, ErrShortMetadata
+	}
+
+	r := CreateStreamReader(body)
+	result := &Bext{}
+
+	result.Description, _ = r.ReadFixedString(bextDescriptionSize)
+	result.Originator, _ = r.ReadFixedString(bextOriginatorSize)
+	result.OriginatorReference, _ = r.ReadFixedString(bextReferenceSize)
+	result.OriginationDate, _ = r.ReadFixedString(bextDateSize)
+	result.OriginationTime, _ = r.ReadFixedString(bextTimeSize)
+	result.TimeReference, _ = r.ReadUInt64()
+	result.Version, _ = r.ReadUInt16()
+
+	_, _ = r.ReadBytesTo(result.UMID[:])
+
+	for _, field := range []*int16{
+		&result.LoudnessValue, &result.LoudnessRange, &result.MaxTruePeakLevel,
+		&result.MaxMomentaryLoudness, &result.MaxShortTermLoudness,
+	} {
+		*field, _ = r.ReadInt16()
+	}
+
+	r.SkipBytes(bextReservedSize)
+
+	result.CodingHistory, _ = r.ReadFixedString(len(body) - bextChunkMinSize)
+
+	return result, nil
+}
+
+// marshal returns the body of a bext chunk
+func (v *Bext) marshal() []byte {
+	w := CreateStreamWriterSize(bextChunkMinSize + len(v.CodingHistory))
+
+	w.PushFixedString(v.Description, bextDescriptionSize)
+	w.PushFixedString(v.Originator, bextOriginatorSize)
+	w.PushFixedString(v.OriginatorReference, bextReferenceSize)
+	w.PushFixedString(v.OriginationDate, bextDateSize)
+	w.PushFixedString(v.OriginationTime, bextTimeSize)
+	w.PushUint64(v.TimeReference)
+	w.PushUint16(v.Version)
+	w.PushBytes(v.UMID[:]...)
+
+	for _, field := range []int16{
+		v.LoudnessValue, v.LoudnessRange, v.MaxTruePeakLevel,
+		v.MaxMomentaryLoudness, v.MaxShortTermLoudness,
+	} {
+		w.PushInt16(field)
+	}
+
+	w.PushBytes(make([]byte, bextReservedSize)...)
+	w.PushString(v.CodingHistory)
+
+	return w.GetBytes()
+}
+
+// MarshalBinary implements encoding.BinaryMarshaler, returning the body of a bext chunk
+func (v *Bext) MarshalBinary() ([]byte, error) {
+	return v.marshal(), nil
+}
+
+// UnmarshalBinary implements encoding.BinaryUnmarshaler, parsing the body of a bext chunk
+func (v *Bext) UnmarshalBinary(data []byte) error {
+	parsed, err := parseBext(data)
+	if err != nil {
+		return err
+	}
+
+	*v = *parsed
+
+	return nil
+}
+
+// StartTimecode returns the timecode of the first sample, from TimeReference
+func (v *Bext) StartTimecode(sampleRate int, rate FrameRate) Timecode {
+	return TimecodeAt(int64(v.TimeReference), sampleRate, rate)
+}
+
+// SetStartTimecode sets TimeReference to the position of the timecode
+func (v *Bext) SetStartTimecode(timecode Timecode, sampleRate int) {
+	v.TimeReference = uint64(timecode.SamplePosition(sampleRate))
+}
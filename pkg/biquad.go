@@ -0,0 +1,173 @@
+package pkg
+
+import (
+	"math"
+)
+
+// butterworthQ is the Q of a second order Butterworth filter, which has the flattest
+// passband
+const butterworthQ = math.Sqrt2 / 2
+
+// Biquad is a second order IIR filter, designed with the formulas of the Audio EQ
+// Cookbook. It keeps the state of each channel between calls, so a stream of
+// interleaved samples can be filtered block by block.
+type Biquad struct {
+	b0, b1, b2, a1, a2 float64
+	// state holds the last two inputs and outputs of each channel
+	state [][4]float64
+	// channel is the channel of the next sample
+	channel int
+}
+
+// CreateLowPass creates a filter removing frequencies above frequency, such as hiss.
+// A q of 0 makes it a Butterworth filter.
+func CreateLowPass(sampleRate int, frequency, q float64) *Biquad {
+	cos, alpha := biquadAngle(sampleRate, frequency, q)
+
+	return createBiquad((1-cos)/2, 1-cos, (1-cos)/2, 1+alpha, -2*cos, 1-alpha) //nolint:gomnd // cookbook
+}
+
+// CreateHighPass creates a filter removing frequencies below frequency, such as rumble.
+// A q of 0 makes it a Butterworth filter.
+func CreateHighPass(sampleRate int, frequency, q float64) *Biquad {
+	cos, alpha := biquadAngle(sampleRate, frequency, q)
+
+	return createBiquad((1+cos)/2, -(1 + cos), (1+cos)/2, 1+alpha, -2*cos, 1-alpha) //nolint:gomnd // cookbook
+}
+
+// CreateBandPass creates a filter passing frequencies around frequency, with a
+// bandwidth narrowing as q grows and a peak gain of 0 dB
+func CreateBandPass(sampleRate int, frequency, q float64) *Biquad {
+	cos, alpha := biquadAngle(sampleRate, frequency, q)
+
+	return createBiquad(alpha, 0, -alpha, 1+alpha, -2*cos, 1-alpha) //nolint:gomnd // cookbook
+}
+
+// CreateLowShelf creates a filter boosting or cutting frequencies below frequency by
+// gain decibels
+func CreateLowShelf(sampleRate int, frequency, gain float64) *Biquad {
+	a, cos, sqrtAlpha := biquadShelf(sampleRate, frequency, gain)
+
+	//nolint:gomnd // cookbook
+	return createBiquad(
+		a*((a+1)-(a-1)*cos+sqrtAlpha),
+		2*a*((a-1)-(a+1)*cos),
+		a*((a+1)-(a-1)*cos-sqrtAlpha),
+		(a+1)+(a-1)*cos+sqrtAlpha,
+		-2*((a-1)+(a+1)*cos),
+		(a+1)+(a-1)*cos-sqrtAlpha,
+	)
+}
+
+// CreateHighShelf creates a filter boosting or cutting frequencies above frequency by
+// gain decibels
+func CreateHighShelf(sampleRate int, frequency, gain float64) *Biquad {
+	a, cos, sqrtAlpha := biquadShelf(sampleRate, frequency, gain)
+
+	//nolint:gomnd // cookbook
+	return createBiquad(
+		a*((a+1)+(a-1)*cos+sqrtAlpha),
+		-2*a*((a-1)+(a+1)*cos),
+		a*((a+1)+(a-1)*cos-sqrtAlpha),
+		(a+1)-(a-1)*cos+sqrtAlpha,
+		2*((a-1)-(a+1)*cos),
+		(a+1)-(a-1)*cos-sqrtAlpha,
+	)
+}
+
+// biquadAngle returns the cosine of the angular frequency and the alpha term of the
+// cookbook formulas
+func biquadAngle(sampleRate int, frequency, q float64) (cos, alpha float64) {
+	if q <= 0 {
+		q = butterworthQ
+	}
+
+	omega := 2 * math.Pi * frequency / float64(sampleRate)
+
+	return math.Cos(omega), math.Sin(omega) / (2 * q) //nolint:gomnd // cookbook
+}
+
+// biquadShelf returns the amplitude, the cosine of the angular frequency and the
+// 2*sqrt(A)*alpha term of the cookbook shelving formulas, with a slope of 1
+func biquadShelf(sampleRate int, frequency, gain float64) (a, cos, sqrtAlpha float64) {
+	a = math.Pow(10, gain/40) //nolint:gomnd // square root of the decibel amplitude
+	cos, alpha := biquadAngle(sampleRate, frequency, butterworthQ)
+
+	return a, cos, 2 * math.Sqrt(a) * alpha //nolint:gomnd // cookbook
+}
+
+// createBiquad normalizes the cookbook coefficients by a0
+func createBiquad(b0, b1, b2, a0, a1, a2 float64) *Biquad {
+	return &Biquad{
+		b0: b0 / a0,
+		b1: b1 / a0,
+		b2: b2 / a0,
+		a1: a1 / a0,
+		a2: a2 / a0,
+	}
+}
+
+// Reset clears the state of the filter, as before the first sample
+func (v *Biquad) Reset() {
+	v.state = v.state[:0]
+	v.channel = 0
+}
+
+// Process filters interleaved samples with the given number of channels in place,
+// continuing from the state left by the previous call. Samples needn't end on a
+// frame boundary. Changing the number of channels resets the filter.
+func (v *Biquad) Process(samples []int16, channels int) {
+	if channels <= 0 {
+		return
+	}
+
+	if len(v.state) != channels {
+		v.state = make([][4]float64, channels)
+		v.channel = 0
+	}
+
+	for i, sample := range samples {
+		s := &v.state[v.channel]
+		x := float64(sample)
+		y := v.b0*x + v.b1*s[0] + v.b2*s[1] - v.a1*s[2] - v.a2*s[3]
+
+		s[0], s[1], s[2], s[3] = x, s[0], y, s[2]
+		samples[i] = int16(clampInt(int(math.Round(y)), math.MinInt16, math.MaxInt16))
+
+		if v.channel++; v.channel == channels {
+			v.channel = 0
+		}
+	}
+}
+
+// Filter runs the samples of the buffer through each filter in turn, in place
+func (v *Buffer) Filter(filters ...*Biquad) {
+	for _, filter := range filters {
+		filter.Process(v.Samples, v.Channels)
+	}
+}
+
+// filterSource is a SampleSource running the samples it reads through filters
+type filterSource struct {
+	src      SampleSource
+	channels int
+	filters  []*Biquad
+}
+
+// CreateFilterSource returns a SampleSource reading interleaved samples with the given
+// number of channels from src, such as a Decoder, and running them through each filter
+// in turn
+func CreateFilterSource(src SampleSource, channels int, filters ...*Biquad) SampleSource {
+	return &filterSource{src: src, channels: channels, filters: filters}
+}
+
+// ReadSamples reads and filters up to len(dst) samples
+func (v *filterSource) ReadSamples(dst []int16) (int, error) {
+	n, err := v.src.ReadSamples(dst)
+
+	for _, filter := range v.filters {
+		filter.Process(dst[:n], v.channels)
+	}
+
+	return n, err
+}
@@ -0,0 +1,250 @@
+package bitio
+
+import (
+	"bytes"
+	"log"
+)
+
+const (
+	maxBits = 16
+)
+
+// BitStream is a utility class for reading groups of bits from a stream
+type BitStream struct {
+	data         []byte
+	dataPosition int
+	current      int
+	bitCount     int
+}
+
+// CreateBitStream creates a new BitStream
+func CreateBitStream(newData []byte) *BitStream {
+	result := &BitStream{
+		data:         newData,
+		dataPosition: 0,
+		current:      0,
+		bitCount:     0,
+	}
+
+	return result
+}
+
+// ReadBits reads the specified number of bits and returns the value
+func (v *BitStream) ReadBits(bitCount int) int {
+	if bitCount > maxBits {
+		log.Panic("Maximum BitCount is 16")
+	}
+
+	if !v.EnsureBits(bitCount) {
+		return -1
+	}
+
+	// nolint:gomnd // byte expresion
+	result := v.current & (0xffff >> uint(maxBits-bitCount))
+	v.WasteBits(bitCount)
+
+	return result
+}
+
+// PeekByte returns the current byte without adjusting the position
+func (v *BitStream) PeekByte() int {
+	if !v.EnsureBits(bitsPerByte) {
+		return -1
+	}
+
+	// nolint:gomnd // byte
+	return v.current & 0xff
+}
+
+// EnsureBits ensures that the specified number of bits are available
+func (v *BitStream) EnsureBits(bitCount int) bool {
+	if bitCount <= v.bitCount {
+		return true
+	}
+
+	if v.dataPosition >= len(v.data) {
+		return false
+	}
+
+	nextValue := v.data[v.dataPosition]
+	v.dataPosition++
+	v.current |= int(nextValue) << uint(v.bitCount)
+	v.bitCount += 8
+
+	return true
+}
+
+// AlignToByte discards the bits left in a partially read byte, so the next read starts
+// at a byte boundary
+func (v *BitStream) AlignToByte() {
+	v.WasteBits(v.bitCount % bitsPerByte)
+}
+
+// WasteBits dry-reads the specified number of bits
+func (v *BitStream) WasteBits(bitCount int) {
+	// noinspection GoRedundantConversion
+	v.current >>= uint(bitCount)
+	v.bitCount -= bitCount
+}
+
+// BitOrder selects how a BitWriter packs bits into bytes
+type BitOrder int
+
+// Bit orders
+const (
+	// LSBFirst fills bytes from the least significant bit and writes values least
+	// significant bit first, matching what BitStream reads
+	LSBFirst BitOrder = iota
+	// MSBFirst fills bytes from the most significant bit and writes values most
+	// significant bit first
+	MSBFirst
+)
+
+const (
+	maxWriteBits = 64
+)
+
+// BitWriter is a utility class for writing groups of bits to a stream, the
+// counterpart to BitStream
+type BitWriter struct {
+	data     *bytes.Buffer
+	order    BitOrder
+	current  byte
+	bitCount int
+}
+
+// CreateBitWriter creates a new BitWriter packing bits in the given order
+func CreateBitWriter(order BitOrder) *BitWriter {
+	result := &BitWriter{
+		data:  new(bytes.Buffer),
+		order: order,
+	}
+
+	return result
+}
+
+// PushBit writes a single bit
+func (v *BitWriter) PushBit(b bool) {
+	if b {
+		if v.order == MSBFirst {
+			v.current |= 0x80 >> uint(v.bitCount) //nolint:gomnd // top bit
+		} else {
+			v.current |= 1 << uint(v.bitCount)
+		}
+	}
+
+	v.bitCount++
+
+	if v.bitCount != bitsPerByte {
+		return
+	}
+
+	v.data.WriteByte(v.current)
+	v.current = 0
+	v.bitCount = 0
+}
+
+// PushBits writes the low bitCount bits of value, up to 64
+func (v *BitWriter) PushBits(value uint64, bitCount int) {
+	if bitCount > maxWriteBits {
+		log.Panic("Maximum BitCount is 64")
+	}
+
+	for i := 0; i < bitCount; i++ {
+		if v.order == MSBFirst {
+			v.PushBit(value>>uint(bitCount-1-i)&1 == 1)
+		} else {
+			v.PushBit(value>>uint(i)&1 == 1)
+		}
+	}
+}
+
+// PushBytes writes whole bytes, bit by bit when the writer isn't on a byte boundary
+func (v *BitWriter) PushBytes(b ...byte) {
+	if v.bitCount == 0 {
+		v.data.Write(b)
+		return
+	}
+
+	for _, value := range b {
+		v.PushBits(uint64(value), bitsPerByte)
+	}
+}
+
+// AlignToByte pads a partially written byte with zero bits, so the next write starts
+// at a byte boundary
+func (v *BitWriter) AlignToByte() {
+	if v.bitCount == 0 {
+		return
+	}
+
+	v.data.WriteByte(v.current)
+	v.current = 0
+	v.bitCount = 0
+}
+
+// BitCount returns the number of bits written
+func (v *BitWriter) BitCount() int {
+	return v.data.Len()*bitsPerByte + v.bitCount
+}
+
+// GetBytes returns the written bits, with a partial final byte padded with zeros
+func (v *BitWriter) GetBytes() []byte {
+	result := v.data.Bytes()
+	if v.bitCount == 0 {
+		return result
+	}
+
+	return append(result[:len(result):len(result)], v.current)
+}
+
+// CopyBits copies bitCount bits from the stream to w in the order they are read, and
+// returns the number copied, which is less if the data runs out. Whole bytes are copied
+// at once while both are on a byte boundary and w packs bits LSBFirst, as the stream
+// reads them.
+func (v *BitStream) CopyBits(w *BitWriter, bitCount int) int {
+	copied := 0
+
+	for copied < bitCount {
+		remaining := bitCount - copied
+
+		if w.order == LSBFirst && w.bitCount == 0 && v.bitCount == 0 && remaining >= bitsPerByte {
+			count := remaining / bitsPerByte
+			if left := len(v.data) - v.dataPosition; count > left {
+				count = left
+			}
+
+			if count == 0 {
+				break
+			}
+
+			w.data.Write(v.data[v.dataPosition : v.dataPosition+count])
+			v.dataPosition += count
+			copied += count * bitsPerByte
+
+			continue
+		}
+
+		n := remaining
+		if n > bitsPerByte {
+			n = bitsPerByte
+		}
+
+		if !v.EnsureBits(n) {
+			if n = v.bitCount; n == 0 {
+				break
+			}
+		}
+
+		value := v.current & (1<<uint(n) - 1)
+		v.WasteBits(n)
+
+		for i := 0; i < n; i++ {
+			w.PushBit(value>>uint(i)&1 == 1)
+		}
+
+		copied += n
+	}
+
+	return copied
+}
@@ -0,0 +1,25 @@
+package bitio
+
+// ByteReader16 reads little-endian 16-bit words
+type ByteReader16 interface {
+	ReadInt16() (int16, error)
+	ReadUInt16() (uint16, error)
+}
+
+// ByteReader24 reads little-endian 24-bit integers
+type ByteReader24 interface {
+	ReadInt24() (int32, error)
+	ReadUInt24() (uint32, error)
+}
+
+// ByteReader32 reads little-endian 32-bit dwords
+type ByteReader32 interface {
+	ReadInt32() (int32, error)
+	ReadUInt32() (uint32, error)
+}
+
+var (
+	_ ByteReader16 = (*StreamReader)(nil)
+	_ ByteReader24 = (*StreamReader)(nil)
+	_ ByteReader32 = (*StreamReader)(nil)
+)
@@ -0,0 +1,179 @@
+package bitio
+
+import (
+	"bytes"
+	"hash"
+	"io"
+)
+
+const (
+	defaultStagingSize = 4096
+)
+
+// stagingBuffer holds the data of a StreamWriter. When it has an underlying writer,
+// the data is handed on to it whenever more than threshold bytes are staged, so only
+// the bytes written since are kept in memory.
+type stagingBuffer struct {
+	bytes.Buffer
+	w         io.Writer
+	threshold int
+	// flushed counts the bytes handed on to w
+	flushed int64
+	// err is the first error returned by w, after which nothing more is staged or
+	// written and every write returns it
+	err error
+	// hash receives every byte written, or is nil
+	hash hash.Hash
+	one  [1]byte
+}
+
+// WriteByte stages a byte
+func (v *stagingBuffer) WriteByte(c byte) error {
+	if v.err != nil {
+		return v.err
+	}
+
+	_ = v.Buffer.WriteByte(c)
+
+	if v.hash != nil {
+		v.one[0] = c
+		_, _ = v.hash.Write(v.one[:])
+	}
+
+	if v.w != nil && v.Buffer.Len() >= v.threshold {
+		return v.flush()
+	}
+
+	return nil
+}
+
+// Write stages p like write, adding what was written to the hash
+func (v *stagingBuffer) Write(p []byte) (int, error) {
+	if v.err != nil {
+		return 0, v.err
+	}
+
+	n, err := v.write(p)
+
+	if v.hash != nil {
+		_, _ = v.hash.Write(p[:n])
+	}
+
+	return n, err
+}
+
+// write stages p, or writes it straight through when it doesn't fit the staging space
+func (v *stagingBuffer) write(p []byte) (int, error) {
+	if v.w == nil {
+		return v.Buffer.Write(p)
+	}
+
+	if v.Buffer.Len()+len(p) < v.threshold {
+		return v.Buffer.Write(p)
+	}
+
+	if err := v.flush(); err != nil {
+		return 0, err
+	}
+
+	if len(p) < v.threshold {
+		return v.Buffer.Write(p)
+	}
+
+	n, err := v.w.Write(p)
+	v.flushed += int64(n)
+
+	if err != nil {
+		v.err = err
+	}
+
+	return n, err
+}
+
+// WriteString stages s like Write
+func (v *stagingBuffer) WriteString(s string) (int, error) {
+	if v.w != nil {
+		return v.Write([]byte(s))
+	}
+
+	if v.hash != nil {
+		_, _ = io.WriteString(v.hash, s)
+	}
+
+	return v.Buffer.WriteString(s)
+}
+
+// ReadFrom stages everything read from r, handing it on in pieces when there is an
+// underlying writer
+func (v *stagingBuffer) ReadFrom(r io.Reader) (int64, error) {
+	if v.err != nil {
+		return 0, v.err
+	}
+
+	if v.w == nil {
+		start := v.Buffer.Len()
+		n, err := v.Buffer.ReadFrom(r)
+
+		if v.hash != nil {
+			_, _ = v.hash.Write(v.Buffer.Bytes()[start:])
+		}
+
+		return n, err
+	}
+
+	return io.CopyBuffer(struct{ io.Writer }{v}, r, make([]byte, v.threshold))
+}
+
+// flush hands the staged bytes on to the underlying writer
+func (v *stagingBuffer) flush() error {
+	if v.err != nil {
+		return v.err
+	}
+
+	if v.w == nil || v.Buffer.Len() == 0 {
+		return nil
+	}
+
+	n, err := v.w.Write(v.Buffer.Bytes())
+	v.flushed += int64(n)
+	v.Buffer.Next(n)
+
+	if err != nil {
+		v.err = err
+	}
+
+	return v.err
+}
+
+// CreateStreamWriterTo creates a StreamWriter which writes to w through a staging
+// buffer of size bytes, or 4096 if size is 0, instead of keeping everything in memory.
+// GetBytes returns the bytes still staged, which Flush writes out; PatchBytes and
+// Truncate can only reach those. The first error w returns is sticky: nothing more is
+// staged, and Flush and every later write such as ReadFrom return it.
+func CreateStreamWriterTo(w io.Writer, size int) *StreamWriter {
+	if size <= 0 {
+		size = defaultStagingSize
+	}
+
+	result := &StreamWriter{
+		data: &stagingBuffer{w: w, threshold: size},
+	}
+
+	result.data.Grow(size)
+
+	return result
+}
+
+// SetHash makes the writer add every byte pushed from now on to h, or stops it if h
+// is nil, so a checksum such as CRC32 or MD5 is computed in the same pass. Bytes
+// changed by PatchBytes or discarded by Truncate or Reset stay in the checksum.
+func (v *StreamWriter) SetHash(h hash.Hash) {
+	v.data.hash = h
+}
+
+// Flush writes the staged bytes of a StreamWriter created with CreateStreamWriterTo to
+// its underlying writer, returning the first error it returned. It does nothing for
+// other writers.
+func (v *StreamWriter) Flush() error {
+	return v.data.flush()
+}
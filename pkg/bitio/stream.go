@@ -0,0 +1,551 @@
+// Package bitio reads and writes little-endian binary data and bit streams
+package bitio
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"math"
+)
+
+const (
+	bitsPerByte   = 8
+	bytesPerint16 = 2
+	bytesPerint24 = 3
+	bytesPerint32 = 4
+	bytesPerint64 = 8
+	int24SignBit  = 1 << 23
+)
+
+// Errors returned when changing data a StreamWriter can no longer reach
+var (
+	// ErrPatchRange is returned when patching bytes beyond the data written so far
+	ErrPatchRange = errors.New("patch beyond the written data")
+	// ErrTruncateRange is returned when truncating data already flushed to the
+	// underlying writer
+	ErrTruncateRange = errors.New("truncate before the flushed data")
+)
+
+// StreamReader allows you to read data from a byte array in various formats
+type StreamReader struct {
+	data     []byte
+	position uint64
+}
+
+// CreateStreamReader creates an instance of the stream reader
+func CreateStreamReader(source []byte) *StreamReader {
+	result := &StreamReader{
+		data:     source,
+		position: 0,
+	}
+
+	return result
+}
+
+// ReadByte reads a byte from the stream
+func (v *StreamReader) ReadByte() (byte, error) {
+	if v.position >= v.Size() {
+		return 0, io.EOF
+	}
+
+	result := v.data[v.position]
+	v.position++
+
+	return result, nil
+}
+
+// ReadInt16 returns a int16 word from the stream
+func (v *StreamReader) ReadInt16() (int16, error) {
+	b, err := v.ReadUInt16()
+	return int16(b), err
+}
+
+// ReadUInt16 returns a uint16 word from the stream
+func (v *StreamReader) ReadUInt16() (uint16, error) {
+	b, err := v.ReadBytes(bytesPerint16)
+	if err != nil {
+		return 0, err
+	}
+
+	return uint16(b[0]) | uint16(b[1])<<8, err
+}
+
+// ReadInt24 returns a sign extended little-endian 24-bit integer from the stream, such
+// as a 24-bit PCM sample
+func (v *StreamReader) ReadInt24() (int32, error) {
+	b, err := v.ReadUInt24()
+	return int32(b^int24SignBit) - int24SignBit, err
+}
+
+// ReadUInt24 returns a little-endian 24-bit unsigned integer from the stream
+func (v *StreamReader) ReadUInt24() (uint32, error) {
+	b, err := v.ReadBytes(bytesPerint24)
+	if err != nil {
+		return 0, err
+	}
+
+	return uint32(b[0]) | uint32(b[1])<<8 | uint32(b[2])<<16, nil
+}
+
+// ReadInt24BE returns a sign extended big-endian 24-bit integer from the stream, as
+// AIFF stores 24-bit samples
+func (v *StreamReader) ReadInt24BE() (int32, error) {
+	b, err := v.ReadUInt24BE()
+	return int32(b^int24SignBit) - int24SignBit, err
+}
+
+// ReadUInt24BE returns a big-endian 24-bit unsigned integer from the stream
+func (v *StreamReader) ReadUInt24BE() (uint32, error) {
+	b, err := v.ReadBytes(bytesPerint24)
+	if err != nil {
+		return 0, err
+	}
+
+	return uint32(b[0])<<16 | uint32(b[1])<<8 | uint32(b[2]), nil
+}
+
+// ReadInt32 returns an int32 dword from the stream
+func (v *StreamReader) ReadInt32() (int32, error) {
+	b, err := v.ReadUInt32()
+	return int32(b), err
+}
+
+// ReadUInt32 returns a uint32 dword from the stream
+// nolint
+func (v *StreamReader) ReadUInt32() (uint32, error) {
+	b, err := v.ReadBytes(bytesPerint32)
+	if err != nil {
+		return 0, err
+	}
+
+	return uint32(b[0]) | uint32(b[1])<<8 | uint32(b[2])<<16 | uint32(b[3])<<24, err
+}
+
+// ReadInt64 returns a uint64 qword from the stream
+func (v *StreamReader) ReadInt64() (int64, error) {
+	b, err := v.ReadUInt64()
+	return int64(b), err
+}
+
+// ReadUInt64 returns a uint64 qword from the stream
+// nolint
+func (v *StreamReader) ReadUInt64() (uint64, error) {
+	b, err := v.ReadBytes(bytesPerint64)
+	if err != nil {
+		return 0, err
+	}
+
+	return uint64(b[0]) | uint64(b[1])<<8 | uint64(b[2])<<16 | uint64(b[3])<<24 |
+		uint64(b[4])<<32 | uint64(b[5])<<40 | uint64(b[6])<<48 | uint64(b[7])<<56, err
+}
+
+// Position returns the current stream position
+func (v *StreamReader) Position() uint64 {
+	return v.position
+}
+
+// SetPosition sets the stream position with the given position
+func (v *StreamReader) SetPosition(newPosition uint64) {
+	v.position = newPosition
+}
+
+// Size returns the total size of the stream in bytes
+func (v *StreamReader) Size() uint64 {
+	return uint64(len(v.data))
+}
+
+// ReadBytes reads multiple bytes. The result is a slice of the data the reader was
+// created with rather than a copy, so it changes if that data is modified or reused;
+// use ReadBytesCopy or ReadBytesTo to keep the bytes.
+func (v *StreamReader) ReadBytes(count int) ([]byte, error) {
+	if count <= 0 {
+		return nil, nil
+	}
+
+	size := v.Size()
+	if v.position >= size || v.position+uint64(count) > size {
+		return nil, io.EOF
+	}
+
+	result := v.data[v.position : v.position+uint64(count)]
+	v.position += uint64(count)
+
+	return result, nil
+}
+
+// ReadBytesAt reads count bytes starting at offset without moving the stream position.
+// Like ReadBytes, the result is a slice of the data rather than a copy.
+func (v *StreamReader) ReadBytesAt(offset uint64, count int) ([]byte, error) {
+	if count <= 0 {
+		return nil, nil
+	}
+
+	size := v.Size()
+	if offset >= size || offset+uint64(count) > size {
+		return nil, io.EOF
+	}
+
+	return v.data[offset : offset+uint64(count)], nil
+}
+
+// ReadUInt16At returns the uint16 word at offset without moving the stream position
+func (v *StreamReader) ReadUInt16At(offset uint64) (uint16, error) {
+	b, err := v.ReadBytesAt(offset, bytesPerint16)
+	if err != nil {
+		return 0, err
+	}
+
+	return uint16(b[0]) | uint16(b[1])<<8, nil
+}
+
+// ReadUInt32At returns the uint32 dword at offset without moving the stream position
+// nolint
+func (v *StreamReader) ReadUInt32At(offset uint64) (uint32, error) {
+	b, err := v.ReadBytesAt(offset, bytesPerint32)
+	if err != nil {
+		return 0, err
+	}
+
+	return uint32(b[0]) | uint32(b[1])<<8 | uint32(b[2])<<16 | uint32(b[3])<<24, nil
+}
+
+// ReadBytesCopy reads multiple bytes like ReadBytes, returning a copy the caller owns
+func (v *StreamReader) ReadBytesCopy(count int) ([]byte, error) {
+	b, err := v.ReadBytes(count)
+	if err != nil || b == nil {
+		return nil, err
+	}
+
+	return append([]byte(nil), b...), nil
+}
+
+// ReadBytesTo reads len(dst) bytes into dst, so that no slice of the stream is kept.
+// Like ReadBytes, it reads nothing and returns io.EOF if fewer bytes are left.
+func (v *StreamReader) ReadBytesTo(dst []byte) (int, error) {
+	if len(dst) == 0 {
+		return 0, nil
+	}
+
+	size := v.Size()
+	if v.position >= size || v.position+uint64(len(dst)) > size {
+		return 0, io.EOF
+	}
+
+	n := copy(dst, v.data[v.position:])
+	v.position += uint64(n)
+
+	return n, nil
+}
+
+// ReadFourCC reads a four character code, such as a RIFF chunk ID
+func (v *StreamReader) ReadFourCC() ([4]byte, error) {
+	var result [4]byte
+
+	_, err := v.ReadBytesTo(result[:])
+
+	return result, err
+}
+
+// ReadCString reads a NUL terminated string, consuming the terminator. A string
+// running to the end of the stream without one is returned as is.
+func (v *StreamReader) ReadCString() (string, error) {
+	if v.EOF() {
+		return "", io.EOF
+	}
+
+	rest := v.data[v.position:]
+
+	end := bytes.IndexByte(rest, 0)
+	if end < 0 {
+		v.position += uint64(len(rest))
+		return string(rest), nil
+	}
+
+	v.position += uint64(end) + 1
+
+	return string(rest[:end]), nil
+}
+
+// ReadFixedString reads a field of count bytes holding a NUL terminated or NUL padded string
+func (v *StreamReader) ReadFixedString(count int) (string, error) {
+	b, err := v.ReadBytes(count)
+	if err != nil {
+		return "", err
+	}
+
+	return TrimString(b), nil
+}
+
+// SkipBytes moves the stream position forward by the given amount
+func (v *StreamReader) SkipBytes(count int) {
+	v.position += uint64(count)
+}
+
+// Read implements io.Reader
+func (v *StreamReader) Read(p []byte) (n int, err error) {
+	streamLength := v.Size()
+
+	for i := 0; ; i++ {
+		if v.Position() >= streamLength {
+			return i, io.EOF
+		}
+
+		if i >= len(p) {
+			return i, nil
+		}
+
+		p[i], err = v.ReadByte()
+		if err != nil {
+			return i, err
+		}
+	}
+}
+
+// EOF returns if the stream position is reached to the end of the data, or not
+func (v *StreamReader) EOF() bool {
+	return v.position >= uint64(len(v.data))
+}
+
+// StreamWriter allows you to create a byte array by streaming in writes of various sizes
+type StreamWriter struct {
+	data *stagingBuffer
+}
+
+// CreateStreamWriter creates a new StreamWriter instance
+func CreateStreamWriter() *StreamWriter {
+	result := &StreamWriter{
+		data: new(stagingBuffer),
+	}
+
+	return result
+}
+
+// CreateStreamWriterSize creates a new StreamWriter with room for size bytes
+func CreateStreamWriterSize(size int) *StreamWriter {
+	result := &StreamWriter{
+		data: new(stagingBuffer),
+	}
+
+	result.data.Grow(size)
+
+	return result
+}
+
+// GetBytes returns the the byte slice of the underlying data
+func (v *StreamWriter) GetBytes() []byte {
+	return v.data.Bytes()
+}
+
+// Len returns the number of bytes written
+func (v *StreamWriter) Len() int {
+	return int(v.data.flushed) + v.data.Len()
+}
+
+// Reset discards the written data, keeping the allocated space for reuse
+func (v *StreamWriter) Reset() {
+	v.data.Reset()
+	v.data.flushed = 0
+	v.data.err = nil
+}
+
+// Truncate discards all but the first n bytes written, keeping the allocated space
+// for reuse, after which Len returns n. It does nothing if fewer bytes have been
+// written, and fails with ErrTruncateRange if bytes beyond n were already flushed.
+func (v *StreamWriter) Truncate(n int) error {
+	staged := int64(n) - v.data.flushed
+	if staged < 0 {
+		return ErrTruncateRange
+	}
+
+	if staged < int64(v.data.Len()) {
+		v.data.Truncate(int(staged))
+	}
+
+	return nil
+}
+
+// ReadFrom implements io.ReaderFrom, appending everything read from r to the stream
+func (v *StreamWriter) ReadFrom(r io.Reader) (int64, error) {
+	return v.data.ReadFrom(r)
+}
+
+// PushBytes writes a bytes to the stream
+func (v *StreamWriter) PushBytes(b ...byte) {
+	_, _ = v.data.Write(b)
+}
+
+// PushString writes the bytes of a string to the stream
+func (v *StreamWriter) PushString(s string) {
+	v.data.WriteString(s)
+}
+
+// PushFourCC writes a four character code
+func (v *StreamWriter) PushFourCC(id [4]byte) {
+	v.data.Write(id[:])
+}
+
+// PushCString writes a string followed by a NUL terminator
+func (v *StreamWriter) PushCString(s string) {
+	v.data.WriteString(s)
+	v.data.WriteByte(0)
+}
+
+// PushFixedString writes a string into a field of width bytes, truncating it or
+// padding it with NULs to fit
+func (v *StreamWriter) PushFixedString(s string, width int) {
+	if len(s) > width {
+		s = s[:width]
+	}
+
+	v.data.WriteString(s)
+
+	for i := len(s); i < width; i++ {
+		v.data.WriteByte(0)
+	}
+}
+
+// PushPrefixedString writes a string preceded by its length as a 1, 2 or 4 byte
+// little-endian integer. Strings too long for the prefix are truncated. Like the
+// bit count checks of BitStream, other prefix sizes panic.
+func (v *StreamWriter) PushPrefixedString(s string, lengthBytes int) {
+	v.PushPrefixedFixedString(s, lengthBytes, -1)
+}
+
+// PushPrefixedFixedString writes a string preceded by its length like
+// PushPrefixedString, into a field of width bytes after the prefix, truncating the
+// string or padding it with NULs to fit. A negative width writes the string as is.
+func (v *StreamWriter) PushPrefixedFixedString(s string, lengthBytes, width int) {
+	var limit uint64
+
+	switch lengthBytes {
+	case 1:
+		limit = math.MaxUint8
+	case bytesPerint16:
+		limit = math.MaxUint16
+	case bytesPerint32:
+		limit = math.MaxUint32
+	default:
+		panic("length prefix must be 1, 2 or 4 bytes")
+	}
+
+	if width >= 0 && uint64(width) < limit {
+		limit = uint64(width)
+	}
+
+	if uint64(len(s)) > limit {
+		s = s[:limit]
+	}
+
+	for i := 0; i < lengthBytes; i++ {
+		v.data.WriteByte(byte(len(s) >> uint(i*bitsPerByte)))
+	}
+
+	if width < 0 {
+		v.data.WriteString(s)
+		return
+	}
+
+	v.PushFixedString(s, width)
+}
+
+// PatchBytes overwrites the bytes written at offset with data, such as a size field
+// which is only known once the rest has been written
+func (v *StreamWriter) PatchBytes(offset int, data []byte) error {
+	written := v.data.Bytes()
+
+	offset -= int(v.data.flushed)
+	if offset < 0 || offset+len(data) > len(written) {
+		return ErrPatchRange
+	}
+
+	copy(written[offset:], data)
+
+	return nil
+}
+
+// WriteUint32At overwrites the uint32 dword written at offset
+// nolint
+func (v *StreamWriter) WriteUint32At(offset int, val uint32) error {
+	return v.PatchBytes(offset, []byte{byte(val), byte(val >> 8), byte(val >> 16), byte(val >> 24)})
+}
+
+// PushInt16 writes a int16 word to the stream
+func (v *StreamWriter) PushInt16(val int16) {
+	v.PushUint16(uint16(val))
+}
+
+// PushUint16 writes an uint16 word to the stream
+// nolint
+func (v *StreamWriter) PushUint16(val uint16) {
+	v.data.WriteByte(byte(val))
+	v.data.WriteByte(byte(val >> 8))
+}
+
+// PushInt24 writes the low 24 bits of val little-endian, such as a 24-bit PCM sample
+func (v *StreamWriter) PushInt24(val int32) {
+	v.PushUint24(uint32(val))
+}
+
+// PushUint24 writes the low 24 bits of val little-endian
+// nolint
+func (v *StreamWriter) PushUint24(val uint32) {
+	v.data.WriteByte(byte(val))
+	v.data.WriteByte(byte(val >> 8))
+	v.data.WriteByte(byte(val >> 16))
+}
+
+// PushInt24BE writes the low 24 bits of val big-endian, as AIFF stores 24-bit samples
+func (v *StreamWriter) PushInt24BE(val int32) {
+	v.PushUint24BE(uint32(val))
+}
+
+// PushUint24BE writes the low 24 bits of val big-endian
+// nolint
+func (v *StreamWriter) PushUint24BE(val uint32) {
+	v.data.WriteByte(byte(val >> 16))
+	v.data.WriteByte(byte(val >> 8))
+	v.data.WriteByte(byte(val))
+}
+
+// PushInt32 writes a int32 dword to the stream
+func (v *StreamWriter) PushInt32(val int32) {
+	v.PushUint32(uint32(val))
+}
+
+// PushUint32 writes a uint32 dword to the stream
+// nolint
+func (v *StreamWriter) PushUint32(val uint32) {
+	v.data.WriteByte(byte(val))
+	v.data.WriteByte(byte(val >> 8))
+	v.data.WriteByte(byte(val >> 16))
+	v.data.WriteByte(byte(val >> 24))
+}
+
+// PushInt64 writes a uint64 qword to the stream
+func (v *StreamWriter) PushInt64(val int64) {
+	v.PushUint64(uint64(val))
+}
+
+// PushUint64 writes a uint64 qword to the stream
+// nolint
+func (v *StreamWriter) PushUint64(val uint64) {
+	v.data.WriteByte(byte(val))
+	v.data.WriteByte(byte(val >> 8))
+	v.data.WriteByte(byte(val >> 16))
+	v.data.WriteByte(byte(val >> 24))
+	v.data.WriteByte(byte(val >> 32))
+	v.data.WriteByte(byte(val >> 40))
+	v.data.WriteByte(byte(val >> 48))
+	v.data.WriteByte(byte(val >> 56))
+}
+
+// TrimString converts a NUL terminated or NUL padded byte string to a string
+func TrimString(b []byte) string {
+	for i, c := range b {
+		if c == 0 {
+			return string(b[:i])
+		}
+	}
+
+	return string(b)
+}
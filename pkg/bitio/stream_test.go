@@ -0,0 +1,85 @@
+package bitio_test
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/gravestench/wav/pkg/bitio"
+)
+
+func TestPushPrefixedString(t *testing.T) {
+	tests := []struct {
+		s           string
+		lengthBytes int
+		want        []byte
+	}{
+		{"abc", 1, []byte{3, 'a', 'b', 'c'}},
+		{"abc", 2, []byte{3, 0, 'a', 'b', 'c'}},
+		{"", 4, []byte{0, 0, 0, 0}},
+		{strings.Repeat("x", 300), 1, append([]byte{255}, strings.Repeat("x", 255)...)},
+	}
+
+	for _, test := range tests {
+		w := bitio.CreateStreamWriter()
+		w.PushPrefixedString(test.s, test.lengthBytes)
+
+		if got := w.GetBytes(); !bytes.Equal(got, test.want) {
+			t.Errorf("%d byte prefix of %d bytes: wrote %v, want %v", test.lengthBytes, len(test.s), got, test.want)
+		}
+	}
+}
+
+func TestPushPrefixedFixedString(t *testing.T) {
+	tests := []struct {
+		s           string
+		lengthBytes int
+		width       int
+		want        []byte
+	}{
+		{"abc", 1, 6, []byte{3, 'a', 'b', 'c', 0, 0, 0}},
+		{"abcdef", 2, 4, []byte{4, 0, 'a', 'b', 'c', 'd'}},
+		{"", 1, 2, []byte{0, 0, 0}},
+		{"abc", 1, -1, []byte{3, 'a', 'b', 'c'}},
+		{strings.Repeat("x", 300), 1, 260, append(append([]byte{255}, strings.Repeat("x", 255)...), 0, 0, 0, 0, 0)},
+	}
+
+	for _, test := range tests {
+		w := bitio.CreateStreamWriter()
+		w.PushPrefixedFixedString(test.s, test.lengthBytes, test.width)
+
+		if got := w.GetBytes(); !bytes.Equal(got, test.want) {
+			t.Errorf("%q in %d bytes: wrote %v, want %v", test.s, test.width, got, test.want)
+		}
+	}
+}
+
+func TestPushPrefixedStringWidth(t *testing.T) {
+	for _, lengthBytes := range []int{0, 3, 8} {
+		func() {
+			defer func() {
+				if recover() == nil {
+					t.Errorf("a %d byte prefix didn't panic", lengthBytes)
+				}
+			}()
+
+			bitio.CreateStreamWriter().PushPrefixedString("abc", lengthBytes)
+		}()
+	}
+}
+
+func TestTrimString(t *testing.T) {
+	tests := map[string]string{
+		"abc":        "abc",
+		"abc\x00":    "abc",
+		"ab\x00\x00": "ab",
+		"a\x00bc":    "a",
+		"":           "",
+	}
+
+	for in, want := range tests {
+		if got := bitio.TrimString([]byte(in)); got != want {
+			t.Errorf("trimmed %q to %q, want %q", in, got, want)
+		}
+	}
+}
@@ -0,0 +1,66 @@
+package bitio
+
+import (
+	"encoding/binary"
+)
+
+// Integer is the fixed-size integer types, including named types such as chunk
+// fields declared as uint32
+type Integer interface {
+	~int8 | ~int16 | ~int32 | ~int64 | ~uint8 | ~uint16 | ~uint32 | ~uint64
+}
+
+// sizeOf returns the size of an integer type in bytes, the first number of bytes a 1
+// shifted past doesn't fit into
+func sizeOf[T Integer]() int {
+	for size := 1; size < bytesPerint64; size++ {
+		if T(uint64(1)<<uint(size*bitsPerByte)) == 0 {
+			return size
+		}
+	}
+
+	return bytesPerint64
+}
+
+// Read returns a little-endian integer of type T from the stream, such as
+// Read[uint32](r) or Read[LoopType](r)
+func Read[T Integer](r *StreamReader) (T, error) {
+	size := sizeOf[T]()
+
+	b, err := r.ReadBytes(size)
+	if err != nil {
+		return 0, err
+	}
+
+	var result uint64
+
+	for i := size - 1; i >= 0; i-- {
+		result = result<<bitsPerByte | uint64(b[i])
+	}
+
+	return T(result), nil
+}
+
+// Push writes an integer of type T to the stream, little-endian
+func Push[T Integer](w *StreamWriter, val T) {
+	bits := uint64(val)
+
+	for i := sizeOf[T](); i > 0; i-- {
+		_ = w.data.WriteByte(byte(bits))
+		bits >>= bitsPerByte
+	}
+}
+
+// ReadStruct reads a fixed-layout structure, such as a chunk header, into the struct
+// dst points to in one call. Its fields are read in order, little-endian, and must
+// all be fixed-size: integers, floats, bools and arrays or structs of them. Blank (_)
+// fields skip their size.
+func (v *StreamReader) ReadStruct(dst interface{}) error {
+	return binary.Read(v, binary.LittleEndian, dst)
+}
+
+// PushStruct writes a fixed-layout structure laid out as ReadStruct reads it. Blank (_)
+// fields are written as zeros.
+func (v *StreamWriter) PushStruct(src interface{}) error {
+	return binary.Write(v.data, binary.LittleEndian, src)
+}
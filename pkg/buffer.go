@@ -0,0 +1,89 @@
+package pkg
+
+import (
+	"io"
+	"time"
+)
+
+// Buffer holds decoded audio in memory as interleaved 16-bit samples
+type Buffer struct {
+	SampleRate int
+	Channels   int
+	// BitsPerSample is the bit depth of the source, which encoding keeps by default
+	BitsPerSample int
+	// Layout is the speaker of each channel, or nil for the default layout
+	Layout  Layout
+	Samples []int16
+	// Metadata holds the INFO tags, bext chunk and markers of the source, which encoding
+	// writes back, or nil for none
+	Metadata *Metadata
+}
+
+// Frames returns the number of sample frames in the buffer
+func (v *Buffer) Frames() int {
+	if v.Channels == 0 {
+		return 0
+	}
+
+	return len(v.Samples) / v.Channels
+}
+
+// Duration returns the playing time of the buffer
+func (v *Buffer) Duration() time.Duration {
+	return framesToDuration(int64(v.Frames()), int64(v.SampleRate))
+}
+
+// DecodeBuffer reads a WAV file or a compressed MPQ sector (its compression mask
+// byte followed by the payload) from r and decodes all of its audio. Headerless
+// sectors are taken as 16-bit audio at the rate and channel count set by
+// WithInputSampleRate and WithInputChannels. Other input fails with ErrInputFormat.
+func DecodeBuffer(r io.Reader, opts ...Option) (*Buffer, error) {
+	options := applyOptions(opts)
+
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+
+	samples, format, riff, err := decodeInput(data, options)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &Buffer{
+		SampleRate:    int(format.SampleRate),
+		Channels:      int(format.Channels),
+		BitsPerSample: int(format.BitsPerSample),
+		Samples:       samples,
+	}
+
+	if format.ChannelMask() != 0 {
+		result.Layout = format.Layout()
+	}
+
+	if riff != nil {
+		result.Metadata = metadataOf(riff)
+	}
+
+	return result, nil
+}
+
+// EncodeBuffer writes the buffer to w as a WAV file, converted as requested by opts
+// like Transcode. Without WithCodec or WithBitDepth it is integer PCM at the bit
+// depth of the buffer. The metadata of the buffer is written too, with its markers
+// rescaled if the output is resampled.
+func EncodeBuffer(w io.Writer, buffer *Buffer, opts ...Option) error {
+	options := applyOptions(opts)
+	options.metadata = buffer.Metadata
+
+	if options.bitsPerSample == 0 && (options.codec == 0 || options.codec == FormatPCM) {
+		options.bitsPerSample = buffer.BitsPerSample
+	}
+
+	format := CreatePCMFormat(buffer.SampleRate, d2BitsPerSample, buffer.Channels)
+	if buffer.Layout != nil {
+		format = CreateExtensibleFormat(buffer.SampleRate, d2BitsPerSample, buffer.Layout)
+	}
+
+	return encodeOutput(w, buffer.Samples, format, nil, options)
+}
@@ -0,0 +1,144 @@
+package pkg
+
+const (
+	cartVersionSize  = 4
+	cartTextSize     = 64
+	cartDateSize     = 10
+	cartTimeSize     = 8
+	cartTimerCount   = 8
+	cartReservedSize = 276
+	cartURLSize      = 1024
+	cartChunkMinSize = 2048
+	cartVersion      = "0101"
+)
+
+// CartTimer is a timer marker of a cart chunk
+type CartTimer struct {
+	// Usage identifies the timer, such as "SEGs" for the segue start
+	Usage FourCC
+	// Value is the position of the timer in sample frames
+	Value uint32
+}
+
+// Cart holds the contents of a cart chunk (AES46), which describes a cut for radio
+// broadcast automation systems
+type Cart struct {
+	// Version is the version of the cart chunk; it defaults to "0101" when written
+	Version        string
+	Title          string
+	Artist         string
+	CutID          string
+	ClientID       string
+	Category       string
+	Classification string
+	OutCue         string
+	// StartDate and EndDate are formatted as yyyy-mm-dd, StartTime and EndTime as hh:mm:ss
+	StartDate          string
+	StartTime          string
+	EndDate            string
+	EndTime            string
+	ProducerAppID      string
+	ProducerAppVersion string
+	UserDef            string
+	// LevelReference is the sample value of 0 dB reference level
+	LevelReference int32
+	Timers         [cartTimerCount]CartTimer
+	URL            string
+	// TagText is free form text following the fixed fields
+	TagText string
+}
+
+// parseCart parses the body of a cart chunk
+func parseCart(body []byte) (*Cart, error) {
+	if len(body) < cartChunkMinSize {
+		return nil, ErrShortMetadata
+	}
+
+	r := CreateStreamReader(body)
+	result := &Cart{}
+
+	result.Version, _ = r.ReadFixedString(cartVersionSize)
+
+	for _, field := range []*string{
+		&result.Title, &result.Artist, &result.CutID, &result.ClientID,
+		&result.Category, &result.Classification, &result.OutCue,
+	} {
+		*field, _ = r.ReadFixedString(cartTextSize)
+	}
+
+	result.StartDate, _ = r.ReadFixedString(cartDateSize)
+	result.StartTime, _ = r.ReadFixedString(cartTimeSize)
+	result.EndDate, _ = r.ReadFixedString(cartDateSize)
+	result.EndTime, _ = r.ReadFixedString(cartTimeSize)
+	result.ProducerAppID, _ = r.ReadFixedString(cartTextSize)
+	result.ProducerAppVersion, _ = r.ReadFixedString(cartTextSize)
+	result.UserDef, _ = r.ReadFixedString(cartTextSize)
+	result.LevelReference, _ = r.ReadInt32()
+
+	for i := range result.Timers {
+		result.Timers[i].Usage, _ = r.ReadFourCC()
+		result.Timers[i].Value, _ = r.ReadUInt32()
+	}
+
+	r.SkipBytes(cartReservedSize)
+
+	result.URL, _ = r.ReadFixedString(cartURLSize)
+	result.TagText, _ = r.ReadFixedString(len(body) - cartChunkMinSize)
+
+	return result, nil
+}
+
+// marshal returns the body of a cart chunk
+func (v *Cart) marshal() []byte {
+	w := CreateStreamWriterSize(cartChunkMinSize + len(v.TagText))
+
+	version := v.Version
+	if version == "" {
+		version = cartVersion
+	}
+
+	w.PushFixedString(version, cartVersionSize)
+
+	for _, field := range []string{
+		v.Title, v.Artist, v.CutID, v.ClientID, v.Category, v.Classification, v.OutCue,
+	} {
+		w.PushFixedString(field, cartTextSize)
+	}
+
+	w.PushFixedString(v.StartDate, cartDateSize)
+	w.PushFixedString(v.StartTime, cartTimeSize)
+	w.PushFixedString(v.EndDate, cartDateSize)
+	w.PushFixedString(v.EndTime, cartTimeSize)
+	w.PushFixedString(v.ProducerAppID, cartTextSize)
+	w.PushFixedString(v.ProducerAppVersion, cartTextSize)
+	w.PushFixedString(v.UserDef, cartTextSize)
+	w.PushInt32(v.LevelReference)
+
+	for _, timer := range v.Timers {
+		w.PushFourCC(timer.Usage)
+		w.PushUint32(timer.Value)
+	}
+
+	w.PushBytes(make([]byte, cartReservedSize)...)
+	w.PushFixedString(v.URL, cartURLSize)
+	w.PushString(v.TagText)
+
+	return w.GetBytes()
+}
+
+// MarshalBinary implements encoding.BinaryMarshaler, returning the body of a cart chunk
+func (v *Cart) MarshalBinary() ([]byte, error) {
+	return v.marshal(), nil
+}
+
+// UnmarshalBinary implements encoding.BinaryUnmarshaler, parsing the body of a cart chunk
+func (v *Cart) UnmarshalBinary(data []byte) error {
+	parsed, err := parseCart(data)
+	if err != nil {
+		return err
+	}
+
+	*v = *parsed
+
+	return nil
+}
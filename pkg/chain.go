@@ -0,0 +1,128 @@
+package pkg
+
+import (
+	"bufio"
+	"encoding/binary"
+	"errors"
+	"io"
+	"math"
+)
+
+// StreamHandler is called by a ChainDecoder when it starts decoding each file, with
+// the index of the file in the source and the Decoder holding its format and metadata
+type StreamHandler func(index int, decoder *Decoder) error
+
+// ChainDecoder decodes WAV files read back to back from one source, such as clips
+// sent one after another over a connection, into one continuous sequence of samples.
+// Each file is decoded in its own format, so sources mixing channel counts or rates
+// should be watched with a StreamHandler. A RIFF size is needed to find where each
+// file ends; an RF64 file is taken to run to the end of the source. Compressed MPQ
+// sectors carry no size, so clips stored that way must be split before decoding.
+type ChainDecoder struct {
+	src     *bufio.Reader
+	handler StreamHandler
+	options []DecoderOption
+	// current decodes the file being read, and file holds what remains of it
+	current *Decoder
+	file    *io.LimitedReader
+	odd     bool
+	index   int
+}
+
+// CreateChainDecoder creates a ChainDecoder reading WAV files from r, calling handler,
+// which may be nil, as each file starts
+func CreateChainDecoder(r io.Reader, handler StreamHandler, opts ...DecoderOption) *ChainDecoder {
+	return &ChainDecoder{
+		src:     bufio.NewReader(r),
+		handler: handler,
+		options: opts,
+		index:   -1,
+	}
+}
+
+// Index returns the index of the file being decoded, or -1 before the first one
+func (v *ChainDecoder) Index() int {
+	return v.index
+}
+
+// Decoder returns the Decoder of the file being decoded, or nil between files
+func (v *ChainDecoder) Decoder() *Decoder {
+	return v.current
+}
+
+// ReadSamples decodes up to len(dst) interleaved samples into dst, moving on to the
+// next file when one ends. Samples of two files are never returned by the same call,
+// and io.EOF is returned once the source ends after a whole file.
+func (v *ChainDecoder) ReadSamples(dst []int16) (int, error) {
+	for {
+		if v.current == nil {
+			if err := v.next(); err != nil {
+				return 0, err
+			}
+		}
+
+		n, err := v.current.ReadSamples(dst)
+		if n > 0 || (err != nil && !errors.Is(err, io.EOF)) {
+			return n, err
+		}
+
+		if err = v.finish(); err != nil {
+			return 0, err
+		}
+	}
+}
+
+// next starts decoding the following file of the source
+func (v *ChainDecoder) next() error {
+	header, err := v.src.Peek(riffHeaderSize)
+	if len(header) == 0 && errors.Is(err, io.EOF) {
+		return io.EOF
+	}
+
+	if err != nil {
+		return headerError(len(header), err)
+	}
+
+	v.file = &io.LimitedReader{R: v.src, N: math.MaxInt64}
+	v.odd = false
+
+	if !isRF64(readFourCC(header)) {
+		riffSize := int64(binary.LittleEndian.Uint32(header[4:8]))
+		v.file.N = riffSize + chunkHeaderSize
+		v.odd = riffSize%2 == 1
+	}
+
+	decoder, err := CreateStreamDecoder(v.file, v.options...)
+	if err != nil {
+		return err
+	}
+
+	v.current = decoder
+	v.index++
+
+	if v.handler != nil {
+		return v.handler(v.index, decoder)
+	}
+
+	return nil
+}
+
+// finish skips the rest of the current file, such as chunks following its data chunk
+func (v *ChainDecoder) finish() error {
+	v.current = nil
+
+	if _, err := io.Copy(io.Discard, v.file); err != nil {
+		return err
+	}
+
+	if !v.odd {
+		return nil
+	}
+
+	// the pad byte of the RIFF chunk is sometimes omitted
+	if pad, err := v.src.Peek(1); err == nil && pad[0] == 0 {
+		v.src.Discard(1) //nolint:errcheck // already peeked
+	}
+
+	return nil
+}
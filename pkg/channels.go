@@ -0,0 +1,190 @@
+package pkg
+
+import (
+	"errors"
+	"fmt"
+)
+
+// ErrChannelMap is returned when a ChannelMap refers to a channel the audio doesn't have
+var ErrChannelMap = errors.New("channel map refers to a missing channel")
+
+// Speaker is a speaker position, valued as its bit in a WAVE_FORMAT_EXTENSIBLE channel mask
+type Speaker uint32
+
+// Speaker positions
+const (
+	SpeakerFrontLeft Speaker = 1 << iota
+	SpeakerFrontRight
+	SpeakerFrontCenter
+	SpeakerLowFrequency
+	SpeakerBackLeft
+	SpeakerBackRight
+	SpeakerFrontLeftOfCenter
+	SpeakerFrontRightOfCenter
+	SpeakerBackCenter
+	SpeakerSideLeft
+	SpeakerSideRight
+	SpeakerTopCenter
+	SpeakerTopFrontLeft
+	SpeakerTopFrontCenter
+	SpeakerTopFrontRight
+	SpeakerTopBackLeft
+	SpeakerTopBackCenter
+	SpeakerTopBackRight
+)
+
+// speakerNames holds the name of each speaker position, by bit
+//
+//nolint:gochecknoglobals // name table
+var speakerNames = []string{
+	"front left", "front right", "front center", "low frequency", "back left", "back right",
+	"front left of center", "front right of center", "back center", "side left", "side right",
+	"top center", "top front left", "top front center", "top front right", "top back left",
+	"top back center", "top back right",
+}
+
+// String returns the name of the speaker position
+func (v Speaker) String() string {
+	for bit, name := range speakerNames {
+		if v == 1<<bit {
+			return name
+		}
+	}
+
+	return fmt.Sprintf("speaker 0x%x", uint32(v))
+}
+
+// Layout lists the speaker of each channel, in channel order
+type Layout []Speaker
+
+// Standard layouts, in the channel order WAVE_FORMAT_EXTENSIBLE requires
+//
+//nolint:gochecknoglobals // layout tables
+var (
+	LayoutMono       = Layout{SpeakerFrontCenter}
+	LayoutStereo     = Layout{SpeakerFrontLeft, SpeakerFrontRight}
+	LayoutQuad       = Layout{SpeakerFrontLeft, SpeakerFrontRight, SpeakerBackLeft, SpeakerBackRight}
+	LayoutSurround51 = Layout{
+		SpeakerFrontLeft, SpeakerFrontRight, SpeakerFrontCenter, SpeakerLowFrequency,
+		SpeakerBackLeft, SpeakerBackRight,
+	}
+	LayoutSurround71 = Layout{
+		SpeakerFrontLeft, SpeakerFrontRight, SpeakerFrontCenter, SpeakerLowFrequency,
+		SpeakerBackLeft, SpeakerBackRight, SpeakerSideLeft, SpeakerSideRight,
+	}
+)
+
+// LayoutFromMask returns the speakers of a channel mask, in channel order
+func LayoutFromMask(mask uint32) Layout {
+	var result Layout
+
+	for bit := range speakerNames {
+		if speaker := Speaker(1 << bit); mask&uint32(speaker) != 0 {
+			result = append(result, speaker)
+		}
+	}
+
+	return result
+}
+
+// Mask returns the channel mask of the layout
+func (v Layout) Mask() uint32 {
+	var result uint32
+
+	for _, speaker := range v {
+		result |= uint32(speaker)
+	}
+
+	return result
+}
+
+// ChannelMap lists, for each output channel, the input channel it is copied from.
+// Channels can be reordered, dropped or duplicated; -1 makes a silent channel.
+type ChannelMap []int
+
+// MapLayout returns the ChannelMap taking audio laid out as from to the layout to.
+// Speakers of to which from lacks are silent.
+func MapLayout(from, to Layout) ChannelMap {
+	result := make(ChannelMap, len(to))
+
+	for i, speaker := range to {
+		result[i] = -1
+
+		for j, source := range from {
+			if source == speaker {
+				result[i] = j
+				break
+			}
+		}
+	}
+
+	return result
+}
+
+// Apply remaps interleaved samples with the given number of channels
+func (v ChannelMap) Apply(samples []int16, channels int) ([]int16, error) {
+	if channels <= 0 {
+		return nil, ErrChannelMap
+	}
+
+	for _, source := range v {
+		if source >= channels {
+			return nil, fmt.Errorf("%w: channel %d of %d", ErrChannelMap, source, channels)
+		}
+	}
+
+	frames := len(samples) / channels
+	result := make([]int16, frames*len(v))
+
+	for frame := 0; frame < frames; frame++ {
+		in := samples[frame*channels : (frame+1)*channels]
+		out := result[frame*len(v) : (frame+1)*len(v)]
+
+		for ch, source := range v {
+			if source >= 0 {
+				out[ch] = in[source]
+			}
+		}
+	}
+
+	return result, nil
+}
+
+// Remix mixes interleaved samples from one channel count to another. When reducing
+// channels, output channel i is the average of the input channels j with j % to == i,
+// so stereo becomes mono by averaging and 4 channels become stereo by folding the rear
+// pair onto the front. When adding channels, output channel i copies input channel
+// i % from, so mono is duplicated to every output channel.
+func Remix(samples []int16, from, to int) []int16 {
+	if from <= 0 || to <= 0 {
+		return nil
+	}
+
+	frames := len(samples) / from
+	result := make([]int16, frames*to)
+
+	for frame := 0; frame < frames; frame++ {
+		in := samples[frame*from : (frame+1)*from]
+		out := result[frame*to : (frame+1)*to]
+
+		if to >= from {
+			for ch := range out {
+				out[ch] = in[ch%from]
+			}
+
+			continue
+		}
+
+		for ch := range out {
+			sum, count := 0, 0
+			for j := ch; j < from; j += to {
+				sum += int(in[j])
+				count++
+			}
+
+			out[ch] = int16(sum / count)
+		}
+	}
+
+	return result
+}
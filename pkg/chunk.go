@@ -0,0 +1,34 @@
+package pkg
+
+import (
+	"github.com/gravestench/wav/pkg/riff"
+)
+
+// chunkPadding returns the number of pad bytes following a chunk body of the given
+// size, as RIFF keeps every chunk word aligned
+func chunkPadding(size int64) int64 {
+	return riff.Padding(size)
+}
+
+// pushChunk writes a chunk header, the body and its pad byte
+func pushChunk(w *streamWriter, id FourCC, body []byte) {
+	riff.PushChunk(w, id, body)
+}
+
+// paddedChunkSize returns the number of bytes a chunk with a body of the given size
+// occupies, including its header and pad byte
+func paddedChunkSize(size int) int {
+	return riff.PaddedSize(size)
+}
+
+// isChunkID reports whether b starts with four printable ASCII characters, which is
+// used to detect writers that omit the pad byte after odd-sized chunks
+func isChunkID(b []byte) bool {
+	return riff.IsChunkID(b)
+}
+
+// headerError returns the error of reading a RIFF header of which n bytes were read,
+// wrapping io.ErrUnexpectedEOF when the source ended first
+func headerError(n int, err error) error {
+	return riff.HeaderError(n, err)
+}
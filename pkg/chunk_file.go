@@ -0,0 +1,27 @@
+package pkg
+
+import (
+	"io"
+
+	"github.com/gravestench/wav/pkg/riff"
+)
+
+// ErrChunkNotFound is returned when reading a chunk the file doesn't have
+var ErrChunkNotFound = riff.ErrChunkNotFound
+
+// Chunk is a chunk found in a RIFF file. Its body is only read when asked for.
+type Chunk = riff.Chunk
+
+// File holds the chunk layout of a RIFF WAVE file without reading the chunk bodies,
+// so files can be probed for metadata without loading their audio
+type File = riff.File
+
+// Open indexes the chunks of a WAV file. The returned File must be closed.
+func Open(path string) (*File, error) {
+	return riff.Open(path)
+}
+
+// CreateFile indexes the chunks of the WAV file of the given size held by r
+func CreateFile(r io.ReaderAt, size int64) (*File, error) {
+	return riff.CreateFile(r, size)
+}
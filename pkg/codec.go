@@ -0,0 +1,39 @@
+package pkg
+
+import (
+	"io"
+	"sync"
+)
+
+// CodecDecoder decodes the data chunk payload of a format this package can't decode
+// itself into interleaved 16-bit samples
+type CodecDecoder func(format Format, payload io.Reader) ([]int16, error)
+
+//nolint:gochecknoglobals // codec registry
+var codecs = struct {
+	sync.RWMutex
+	decoders map[uint16]CodecDecoder
+}{decoders: make(map[uint16]CodecDecoder)}
+
+// RegisterCodec registers the decoder of a format tag, replacing any previous one.
+// Decoders then accept files in that format and DecodeAll, and so Transcode, decode
+// them through it. A nil decoder removes the registration.
+func RegisterCodec(tag uint16, decoder CodecDecoder) {
+	codecs.Lock()
+	defer codecs.Unlock()
+
+	if decoder == nil {
+		delete(codecs.decoders, tag)
+		return
+	}
+
+	codecs.decoders[tag] = decoder
+}
+
+// lookupCodec returns the registered decoder of the format, or nil if there is none
+func lookupCodec(format Format) CodecDecoder {
+	codecs.RLock()
+	defer codecs.RUnlock()
+
+	return codecs.decoders[format.effectiveTag()]
+}
@@ -0,0 +1,118 @@
+package pkg
+
+import (
+	"errors"
+	"io"
+)
+
+// Comparison holds the sample-level differences between two WAV files
+type Comparison struct {
+	// FramesA and FramesB are the number of sample frames in each file
+	FramesA int64
+	FramesB int64
+	// FirstDifference is the first frame in which any sample differs, or -1
+	FirstDifference int64
+	// MaxDelta is the largest absolute difference between two 16-bit samples
+	MaxDelta int
+	// DifferingSamples counts the samples which differ
+	DifferingSamples int64
+}
+
+// Equal reports whether both files hold identical samples
+func (v Comparison) Equal() bool {
+	return v.FramesA == v.FramesB && v.DifferingSamples == 0
+}
+
+// Compare decodes two WAV files and compares their samples, ignoring metadata
+// chunks and the encoding of the samples. Only the frames both files have are
+// compared sample by sample.
+func Compare(a, b io.Reader) (Comparison, error) {
+	result := Comparison{FirstDifference: -1}
+
+	decoderA, err := CreateStreamDecoder(a)
+	if err != nil {
+		return result, err
+	}
+
+	decoderB, err := CreateStreamDecoder(b)
+	if err != nil {
+		return result, err
+	}
+
+	channels := decoderA.Channels()
+	if channels != decoderB.Channels() {
+		return result, ErrChannelMismatch
+	}
+
+	bufA := make([]int16, decodeChunkSamples*channels)
+	bufB := make([]int16, decodeChunkSamples*channels)
+
+	var sample int64
+
+	for {
+		nA, errA := readFullSamples(decoderA, bufA)
+		nB, errB := readFullSamples(decoderB, bufB)
+
+		result.FramesA += int64(nA / channels)
+		result.FramesB += int64(nB / channels)
+
+		n := nA
+		if nB < n {
+			n = nB
+		}
+
+		for i := 0; i < n; i++ {
+			delta := int(bufA[i]) - int(bufB[i])
+			if delta == 0 {
+				continue
+			}
+
+			if delta < 0 {
+				delta = -delta
+			}
+
+			if result.FirstDifference < 0 {
+				result.FirstDifference = (sample + int64(i)) / int64(channels)
+			}
+
+			if delta > result.MaxDelta {
+				result.MaxDelta = delta
+			}
+
+			result.DifferingSamples++
+		}
+
+		sample += int64(n)
+
+		if done, err := comparisonDone(errA, errB); done {
+			return result, err
+		}
+	}
+}
+
+// comparisonDone reports whether both decoders are exhausted or failed
+func comparisonDone(errA, errB error) (bool, error) {
+	for _, err := range []error{errA, errB} {
+		if err != nil && !errors.Is(err, io.EOF) && !errors.Is(err, io.ErrUnexpectedEOF) {
+			return true, err
+		}
+	}
+
+	return errA != nil && errB != nil, nil
+}
+
+// readFullSamples fills dst from the decoder unless it runs out of samples
+func readFullSamples(decoder *Decoder, dst []int16) (int, error) {
+	n := 0
+
+	for n < len(dst) {
+		read, err := decoder.ReadSamples(dst[n:])
+		n += read
+
+		if err != nil {
+			return n, err
+		}
+	}
+
+	return n, nil
+}
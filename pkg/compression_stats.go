@@ -0,0 +1,95 @@
+package pkg
+
+import (
+	"time"
+)
+
+// CompressionStats describes how well compressed audio shrank its 16-bit PCM.
+// Stats of consecutive sectors can be summed with Add while streaming.
+type CompressionStats struct {
+	CompressedSize int64
+	DecodedSize    int64
+	Channels       int
+	SampleRate     int
+}
+
+// AnalyzeCompression decompresses an MPQ sector payload compressed with mask and
+// reports its sizes. sampleRate is needed for the bitrate, 22050 for Diablo II.
+func AnalyzeCompression(payload []byte, mask byte, sampleRate int) (CompressionStats, error) {
+	decoded, err := MultiDecompress(payload, mask)
+	if err != nil {
+		return CompressionStats{}, err
+	}
+
+	channels := 1
+	if mask&CompressionADPCMStereo != 0 {
+		channels = 2
+	}
+
+	result := CompressionStats{
+		CompressedSize: int64(len(payload)),
+		DecodedSize:    int64(len(decoded)),
+		Channels:       channels,
+		SampleRate:     sampleRate,
+	}
+
+	return result, nil
+}
+
+// Add accumulates the sizes of other, such as the next sector of the same sound
+func (v *CompressionStats) Add(other CompressionStats) {
+	v.CompressedSize += other.CompressedSize
+	v.DecodedSize += other.DecodedSize
+
+	if v.Channels == 0 {
+		v.Channels = other.Channels
+	}
+
+	if v.SampleRate == 0 {
+		v.SampleRate = other.SampleRate
+	}
+}
+
+// Frames returns the number of decoded sample frames
+func (v CompressionStats) Frames() int64 {
+	if v.Channels == 0 {
+		return 0
+	}
+
+	return v.DecodedSize / int64(bytesPerint16*v.Channels)
+}
+
+// Duration returns the playing time of the decoded audio
+func (v CompressionStats) Duration() time.Duration {
+	return framesToDuration(v.Frames(), int64(v.SampleRate))
+}
+
+// Ratio returns the decoded size divided by the compressed size
+func (v CompressionStats) Ratio() float64 {
+	if v.CompressedSize == 0 {
+		return 0
+	}
+
+	return float64(v.DecodedSize) / float64(v.CompressedSize)
+}
+
+// BitsPerSample returns the average number of compressed bits spent on each sample
+// of each channel
+func (v CompressionStats) BitsPerSample() float64 {
+	samples := v.Frames() * int64(v.Channels)
+	if samples == 0 {
+		return 0
+	}
+
+	return float64(v.CompressedSize*bitsPerByte) / float64(samples)
+}
+
+// Bitrate returns the effective compressed bitrate in bits per second
+func (v CompressionStats) Bitrate() float64 {
+	return v.BitsPerSample() * float64(v.Channels*v.SampleRate)
+}
+
+// ChannelBitrate returns the effective compressed bitrate of each channel
+func (v CompressionStats) ChannelBitrate() float64 {
+	return v.BitsPerSample() * float64(v.SampleRate)
+}
@@ -0,0 +1,122 @@
+package pkg
+
+import (
+	"math"
+)
+
+const (
+	int16Scale     = 32768
+	stereoChannels = 2
+)
+
+// Int16ToFloat32 converts samples to float32 in the range [-1, 1), returning the
+// number converted, which is the length of the shorter slice. amd64 and arm64 use
+// SIMD instructions unless built with the purego tag.
+func Int16ToFloat32(dst []float32, src []int16) int {
+	n := len(src)
+	if len(dst) < n {
+		n = len(dst)
+	}
+
+	done := int16ToFloat32Fast(dst[:n], src[:n])
+
+	for i := done; i < n; i++ {
+		dst[i] = float32(src[i]) * (1.0 / int16Scale)
+	}
+
+	return n
+}
+
+// Float32ToInt16 converts float32 samples in the range [-1, 1) to 16-bit samples,
+// rounding to the nearest value, ties to even, and saturating values outside the
+// range. NaN converts to 0. It returns the number converted, which is the length of
+// the shorter slice.
+func Float32ToInt16(dst []int16, src []float32) int {
+	n := len(src)
+	if len(dst) < n {
+		n = len(dst)
+	}
+
+	done := float32ToInt16Fast(dst[:n], src[:n])
+
+	for i := done; i < n; i++ {
+		dst[i] = float32ToInt16(src[i])
+	}
+
+	return n
+}
+
+func float32ToInt16(sample float32) int16 {
+	scaled := float64(sample) * int16Scale
+
+	switch {
+	case math.IsNaN(scaled):
+		return 0
+	case scaled >= maxInt16:
+		return maxInt16
+	case scaled <= minInt16:
+		return minInt16
+	}
+
+	return int16(math.RoundToEven(scaled))
+}
+
+// Interleave merges one slice per channel into dst, returning the number of frames
+// written: the length of the shortest channel, limited by the room in dst. Stereo
+// uses SIMD instructions on amd64 and arm64.
+func Interleave(dst []float32, src [][]float32) int {
+	channels := len(src)
+	if channels == 0 {
+		return 0
+	}
+
+	frames := len(dst) / channels
+	for _, ch := range src {
+		if len(ch) < frames {
+			frames = len(ch)
+		}
+	}
+
+	done := 0
+	if channels == stereoChannels {
+		done = interleaveStereoFast(dst[:frames*channels], src[0][:frames], src[1][:frames])
+	}
+
+	for i := done; i < frames; i++ {
+		for ch, samples := range src {
+			dst[i*channels+ch] = samples[i]
+		}
+	}
+
+	return frames
+}
+
+// Deinterleave splits interleaved samples into one slice per channel, returning the
+// number of frames read: the whole frames in src, limited by the shortest channel
+// of dst. Stereo uses SIMD instructions on amd64 and arm64.
+func Deinterleave(dst [][]float32, src []float32) int {
+	channels := len(dst)
+	if channels == 0 {
+		return 0
+	}
+
+	frames := len(src) / channels
+	for _, ch := range dst {
+		if len(ch) < frames {
+			frames = len(ch)
+		}
+	}
+
+	done := 0
+	if channels == stereoChannels {
+		done = deinterleaveStereoFast(dst[0][:frames], dst[1][:frames], src[:frames*channels])
+	}
+
+	for i := done; i < frames; i++ {
+		for ch, samples := range dst {
+			samples[i] = src[i*channels+ch]
+		}
+	}
+
+	return frames
+}
@@ -0,0 +1,140 @@
+//go:build !purego
+
+#include "textflag.h"
+
+// SSE2 kernels, part of the amd64 baseline. Counts are multiples of the block sizes
+// in convert_asm.go.
+
+// func int16ToFloat32Asm(dst *float32, src *int16, n int)
+TEXT ·int16ToFloat32Asm(SB), NOSPLIT, $0-24
+	MOVQ dst+0(FP), DI
+	MOVQ src+8(FP), SI
+	MOVQ n+16(FP), CX
+
+	// 1/32768 in every lane
+	MOVL   $0x38000000, AX
+	MOVQ   AX, X3
+	SHUFPS $0x00, X3, X3
+
+loop:
+	MOVOU (SI), X0
+
+	// sign extend the words to dwords by unpacking each next to itself
+	MOVO      X0, X1
+	PUNPCKLWL X0, X1
+	PSRAL     $16, X1
+	PUNPCKHWL X0, X0
+	PSRAL     $16, X0
+
+	CVTPL2PS X1, X1
+	CVTPL2PS X0, X0
+	MULPS    X3, X1
+	MULPS    X3, X0
+
+	MOVUPS X1, (DI)
+	MOVUPS X0, 16(DI)
+
+	ADDQ $16, SI
+	ADDQ $32, DI
+	SUBQ $8, CX
+	JNZ  loop
+
+	RET
+
+// func float32ToInt16Asm(dst *int16, src *float32, n int)
+TEXT ·float32ToInt16Asm(SB), NOSPLIT, $0-24
+	MOVQ dst+0(FP), DI
+	MOVQ src+8(FP), SI
+	MOVQ n+16(FP), CX
+
+	// 32768, 32767 and -32768 in every lane
+	MOVL   $0x47000000, AX
+	MOVQ   AX, X4
+	SHUFPS $0x00, X4, X4
+	MOVL   $0x46fffe00, AX
+	MOVQ   AX, X5
+	SHUFPS $0x00, X5, X5
+	MOVL   $0xc7000000, AX
+	MOVQ   AX, X6
+	SHUFPS $0x00, X6, X6
+
+loop:
+	MOVUPS (SI), X0
+	MOVUPS 16(SI), X1
+	MULPS  X4, X0
+	MULPS  X4, X1
+
+	// zero NaN lanes, which compare unordered with themselves
+	MOVAPS X0, X2
+	CMPPS  X0, X2, $7
+	ANDPS  X2, X0
+	MOVAPS X1, X2
+	CMPPS  X1, X2, $7
+	ANDPS  X2, X1
+
+	// saturate before converting, as out of range values convert to 0x80000000
+	MINPS X5, X0
+	MAXPS X6, X0
+	MINPS X5, X1
+	MAXPS X6, X1
+
+	CVTPS2PL X0, X0
+	CVTPS2PL X1, X1
+	PACKSSLW X1, X0
+
+	MOVOU X0, (DI)
+
+	ADDQ $32, SI
+	ADDQ $16, DI
+	SUBQ $8, CX
+	JNZ  loop
+
+	RET
+
+// func interleaveStereoAsm(dst, left, right *float32, frames int)
+TEXT ·interleaveStereoAsm(SB), NOSPLIT, $0-32
+	MOVQ dst+0(FP), DI
+	MOVQ left+8(FP), SI
+	MOVQ right+16(FP), DX
+	MOVQ frames+24(FP), CX
+
+loop:
+	MOVUPS   (SI), X0
+	MOVUPS   (DX), X1
+	MOVAPS   X0, X2
+	UNPCKLPS X1, X2
+	UNPCKHPS X1, X0
+	MOVUPS   X2, (DI)
+	MOVUPS   X0, 16(DI)
+
+	ADDQ $16, SI
+	ADDQ $16, DX
+	ADDQ $32, DI
+	SUBQ $4, CX
+	JNZ  loop
+
+	RET
+
+// func deinterleaveStereoAsm(left, right, src *float32, frames int)
+TEXT ·deinterleaveStereoAsm(SB), NOSPLIT, $0-32
+	MOVQ left+0(FP), DI
+	MOVQ right+8(FP), DX
+	MOVQ src+16(FP), SI
+	MOVQ frames+24(FP), CX
+
+loop:
+	MOVUPS (SI), X0
+	MOVUPS 16(SI), X1
+	MOVAPS X0, X2
+	SHUFPS $0x88, X1, X2
+	SHUFPS $0xdd, X1, X0
+	MOVUPS X2, (DI)
+	MOVUPS X0, (DX)
+
+	ADDQ $32, SI
+	ADDQ $16, DI
+	ADDQ $16, DX
+	SUBQ $4, CX
+	JNZ  loop
+
+	RET
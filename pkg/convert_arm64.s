@@ -0,0 +1,89 @@
+//go:build !purego
+
+#include "textflag.h"
+
+// NEON kernels. Counts are multiples of the block sizes in convert_asm.go. The
+// assembler lacks the widening, conversion and narrowing forms used here, so they
+// are encoded as WORDs with the instruction they hold alongside.
+
+// func int16ToFloat32Asm(dst *float32, src *int16, n int)
+TEXT ·int16ToFloat32Asm(SB), NOSPLIT, $0-24
+	MOVD dst+0(FP), R0
+	MOVD src+8(FP), R1
+	MOVD n+16(FP), R2
+
+	// 1/32768 in every lane
+	MOVW $0x38000000, R3
+	VDUP R3, V3.S4
+
+loop:
+	VLD1.P 16(R1), [V0.H8]
+	WORD   $0x0f10a401         // SXTL  V1.4S, V0.4H
+	WORD   $0x4f10a402         // SXTL2 V2.4S, V0.8H
+	WORD   $0x4e21d821         // SCVTF V1.4S, V1.4S
+	WORD   $0x4e21d842         // SCVTF V2.4S, V2.4S
+	WORD   $0x6e23dc21         // FMUL  V1.4S, V1.4S, V3.4S
+	WORD   $0x6e23dc42         // FMUL  V2.4S, V2.4S, V3.4S
+	VST1.P [V1.S4, V2.S4], 32(R0)
+	SUBS   $8, R2, R2
+	BNE    loop
+
+	RET
+
+// func float32ToInt16Asm(dst *int16, src *float32, n int)
+TEXT ·float32ToInt16Asm(SB), NOSPLIT, $0-24
+	MOVD dst+0(FP), R0
+	MOVD src+8(FP), R1
+	MOVD n+16(FP), R2
+
+	// 32768 in every lane
+	MOVW $0x47000000, R3
+	VDUP R3, V3.S4
+
+loop:
+	// FCVTNS rounds ties to even, saturates and turns NaN into 0, and SQXTN
+	// saturates again to 16 bits
+	VLD1.P 32(R1), [V0.S4, V1.S4]
+	WORD   $0x6e23dc00         // FMUL   V0.4S, V0.4S, V3.4S
+	WORD   $0x6e23dc21         // FMUL   V1.4S, V1.4S, V3.4S
+	WORD   $0x4e21a800         // FCVTNS V0.4S, V0.4S
+	WORD   $0x4e21a821         // FCVTNS V1.4S, V1.4S
+	WORD   $0x0e614802         // SQXTN  V2.4H, V0.4S
+	WORD   $0x4e614822         // SQXTN2 V2.8H, V1.4S
+	VST1.P [V2.H8], 16(R0)
+	SUBS   $8, R2, R2
+	BNE    loop
+
+	RET
+
+// func interleaveStereoAsm(dst, left, right *float32, frames int)
+TEXT ·interleaveStereoAsm(SB), NOSPLIT, $0-32
+	MOVD dst+0(FP), R0
+	MOVD left+8(FP), R1
+	MOVD right+16(FP), R2
+	MOVD frames+24(FP), R3
+
+loop:
+	VLD1.P 16(R1), [V0.S4]
+	VLD1.P 16(R2), [V1.S4]
+	VST2.P [V0.S4, V1.S4], 32(R0)
+	SUBS   $4, R3, R3
+	BNE    loop
+
+	RET
+
+// func deinterleaveStereoAsm(left, right, src *float32, frames int)
+TEXT ·deinterleaveStereoAsm(SB), NOSPLIT, $0-32
+	MOVD left+0(FP), R0
+	MOVD right+8(FP), R1
+	MOVD src+16(FP), R2
+	MOVD frames+24(FP), R3
+
+loop:
+	VLD2.P 32(R2), [V0.S4, V1.S4]
+	VST1.P [V0.S4], 16(R0)
+	VST1.P [V1.S4], 16(R1)
+	SUBS   $4, R3, R3
+	BNE    loop
+
+	RET
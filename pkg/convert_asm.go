@@ -0,0 +1,64 @@
+//go:build (amd64 || arm64) && !purego
+
+package pkg
+
+const (
+	// convertBlock is the number of samples the conversion kernels process per step
+	convertBlock = 8
+	// interleaveBlock is the number of frames the stereo kernels process per step
+	interleaveBlock = 4
+)
+
+//go:noescape
+func int16ToFloat32Asm(dst *float32, src *int16, n int)
+
+//go:noescape
+func float32ToInt16Asm(dst *int16, src *float32, n int)
+
+//go:noescape
+func interleaveStereoAsm(dst, left, right *float32, frames int)
+
+//go:noescape
+func deinterleaveStereoAsm(left, right, src *float32, frames int)
+
+// int16ToFloat32Fast converts the leading whole blocks of src, returning their length
+func int16ToFloat32Fast(dst []float32, src []int16) int {
+	n := len(src) &^ (convertBlock - 1)
+	if n > 0 {
+		int16ToFloat32Asm(&dst[0], &src[0], n)
+	}
+
+	return n
+}
+
+// float32ToInt16Fast converts the leading whole blocks of src, returning their length
+func float32ToInt16Fast(dst []int16, src []float32) int {
+	n := len(src) &^ (convertBlock - 1)
+	if n > 0 {
+		float32ToInt16Asm(&dst[0], &src[0], n)
+	}
+
+	return n
+}
+
+// interleaveStereoFast interleaves the leading whole blocks of frames, returning
+// their number
+func interleaveStereoFast(dst, left, right []float32) int {
+	frames := len(left) &^ (interleaveBlock - 1)
+	if frames > 0 {
+		interleaveStereoAsm(&dst[0], &left[0], &right[0], frames)
+	}
+
+	return frames
+}
+
+// deinterleaveStereoFast deinterleaves the leading whole blocks of frames, returning
+// their number
+func deinterleaveStereoFast(left, right, src []float32) int {
+	frames := len(left) &^ (interleaveBlock - 1)
+	if frames > 0 {
+		deinterleaveStereoAsm(&left[0], &right[0], &src[0], frames)
+	}
+
+	return frames
+}
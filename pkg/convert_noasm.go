@@ -0,0 +1,21 @@
+//go:build !(amd64 || arm64) || purego
+
+package pkg
+
+// The portable build converts every sample in Go
+
+func int16ToFloat32Fast(_ []float32, _ []int16) int {
+	return 0
+}
+
+func float32ToInt16Fast(_ []int16, _ []float32) int {
+	return 0
+}
+
+func interleaveStereoFast(_, _, _ []float32) int {
+	return 0
+}
+
+func deinterleaveStereoFast(_, _, _ []float32) int {
+	return 0
+}
@@ -0,0 +1,123 @@
+package pkg
+
+import (
+	"encoding/csv"
+	"errors"
+	"io"
+	"strconv"
+)
+
+const csvChunkFrames = 1024
+
+// WriteCSV writes interleaved samples as CSV, one row per frame holding the frame
+// index followed by the sample of each channel, after a header row naming the columns
+func WriteCSV(w io.Writer, samples []int16, channels int) error {
+	if channels <= 0 {
+		return ErrChannelMismatch
+	}
+
+	output := csv.NewWriter(w)
+	row := make([]string, channels+1)
+
+	if err := output.Write(csvHeader(row)); err != nil {
+		return err
+	}
+
+	writeCSVRows(output, row, samples, channels, 0)
+	output.Flush()
+
+	return output.Error()
+}
+
+// DumpCSV decodes count frames starting at frame first and writes them as CSV like
+// WriteCSV, with frame indexes counting from the start of the data chunk. A count
+// of 0 or less dumps every frame to the end of the data chunk.
+func (v *Decoder) DumpCSV(w io.Writer, first, count int64) error {
+	if first < 0 {
+		return ErrInvalidRange
+	}
+
+	if err := v.format.validate(); err != nil {
+		return err
+	}
+
+	channels := int(v.format.Channels)
+
+	last := v.Frames()
+	if count > 0 && first+count < last {
+		last = first + count
+	}
+
+	output := csv.NewWriter(w)
+	row := make([]string, channels+1)
+
+	if err := output.Write(csvHeader(row)); err != nil {
+		return err
+	}
+
+	skip := v.seekFrame(first) * int64(channels)
+	buf := make([]int16, csvChunkFrames*channels)
+	frame := first
+
+	for frame < last {
+		n, err := v.ReadSamples(buf)
+		samples := buf[:n]
+
+		if skip > 0 {
+			dropped := int64(len(samples))
+			if dropped > skip {
+				dropped = skip
+			}
+
+			samples = samples[dropped:]
+			skip -= dropped
+		}
+
+		if remaining := (last - frame) * int64(channels); int64(len(samples)) > remaining {
+			samples = samples[:remaining]
+		}
+
+		frame = writeCSVRows(output, row, samples, channels, frame)
+
+		if errors.Is(err, io.EOF) {
+			break
+		}
+
+		if err != nil {
+			return err
+		}
+	}
+
+	output.Flush()
+
+	return output.Error()
+}
+
+// csvHeader fills row with the column names
+func csvHeader(row []string) []string {
+	row[0] = "frame"
+
+	for ch := 1; ch < len(row); ch++ {
+		row[ch] = "ch" + strconv.Itoa(ch-1)
+	}
+
+	return row
+}
+
+// writeCSVRows writes a row for every whole frame of samples, numbering them from
+// frame, and returns the index of the next frame
+func writeCSVRows(output *csv.Writer, row []string, samples []int16, channels int, frame int64) int64 {
+	for i := 0; i+channels <= len(samples); i += channels {
+		row[0] = strconv.FormatInt(frame, 10)
+
+		for ch, sample := range samples[i : i+channels] {
+			row[ch+1] = strconv.Itoa(int(sample))
+		}
+
+		// errors are sticky, and reported by Flush
+		_ = output.Write(row)
+		frame++
+	}
+
+	return frame
+}
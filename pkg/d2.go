@@ -0,0 +1,49 @@
+package pkg
+
+import (
+	"bytes"
+)
+
+// D2SampleRate is the sample rate of Diablo II sound effects, speech and music
+const D2SampleRate = 22050
+
+const d2BitsPerSample = 16
+
+// DecodeD2Sound decompresses a sound file pulled from a Diablo II MPQ using its
+// compression flags and returns a ready-to-play WAV file. Files which decompress to
+// a RIFF/WAVE file are returned as is, headerless PCM is wrapped in a 16-bit 22050 Hz
+// WAV with the channel count implied by the ADPCM flag (mono unless stereo ADPCM).
+func DecodeD2Sound(data []byte, flags byte) ([]byte, error) {
+	decompressed, err := MultiDecompress(data, flags)
+	if err != nil {
+		return nil, err
+	}
+
+	if bytes.HasPrefix(decompressed, ChunkRIFF[:]) {
+		return decompressed, nil
+	}
+
+	channels := 1
+	if flags&CompressionADPCMStereo != 0 {
+		channels = 2
+	}
+
+	var out bytes.Buffer
+
+	encoder, err := CreateEncoder(&out, D2SampleRate, d2BitsPerSample, channels)
+	if err != nil {
+		return nil, err
+	}
+
+	// drop a trailing partial frame rather than failing on it
+	frameSize := channels * bytesPerint16
+	if _, err = encoder.Write(decompressed[:len(decompressed)-len(decompressed)%frameSize]); err != nil {
+		return nil, err
+	}
+
+	if err = encoder.Close(); err != nil {
+		return nil, err
+	}
+
+	return out.Bytes(), nil
+}
@@ -0,0 +1,111 @@
+package pkg
+
+const dbmdEndSegment = 0
+
+// DolbySegment is a metadata segment of a dbmd chunk
+type DolbySegment struct {
+	// ID identifies the kind of metadata, such as 1 for Dolby E or 3 for Dolby Digital
+	ID uint8
+	// Offset is the position of the payload in the chunk body
+	Offset int
+	// Payload is the uninterpreted segment payload
+	Payload []byte
+	// Checksum is the checksum byte following the payload
+	Checksum uint8
+}
+
+// DolbyMetadata holds a dbmd chunk. The chunk isn't interpreted beyond splitting it
+// into segments, and is written back exactly as read.
+type DolbyMetadata struct {
+	Version  uint32
+	Segments []DolbySegment
+	// Raw is the chunk body
+	Raw []byte
+}
+
+// parseDolbyMetadata parses the body of a dbmd chunk
+func parseDolbyMetadata(body []byte) (*DolbyMetadata, error) {
+	r := CreateStreamReader(body)
+
+	version, err := r.ReadUInt32()
+	if err != nil {
+		return nil, ErrShortMetadata
+	}
+
+	result := &DolbyMetadata{
+		Version: version,
+		Raw:     append([]byte(nil), body...),
+	}
+
+	for !r.EOF() {
+		id, _ := r.ReadByte()
+		if id == dbmdEndSegment {
+			break
+		}
+
+		size, err := r.ReadUInt16()
+		if err != nil {
+			return nil, ErrShortMetadata
+		}
+
+		offset := int(r.Position())
+
+		if _, err = r.ReadBytes(int(size)); err != nil {
+			return nil, ErrShortMetadata
+		}
+
+		checksum, err := r.ReadByte()
+		if err != nil {
+			return nil, ErrShortMetadata
+		}
+
+		result.Segments = append(result.Segments, DolbySegment{
+			ID:       id,
+			Offset:   offset,
+			Payload:  result.Raw[offset : offset+int(size)],
+			Checksum: checksum,
+		})
+	}
+
+	return result, nil
+}
+
+// marshal returns the body of the dbmd chunk. Raw is written when set, otherwise the
+// chunk is built from the version and segments.
+func (v *DolbyMetadata) marshal() []byte {
+	if v.Raw != nil {
+		return v.Raw
+	}
+
+	w := CreateStreamWriter()
+
+	w.PushUint32(v.Version)
+
+	for _, segment := range v.Segments {
+		w.PushBytes(segment.ID)
+		w.PushUint16(uint16(len(segment.Payload)))
+		w.PushBytes(segment.Payload...)
+		w.PushBytes(segment.Checksum)
+	}
+
+	w.PushBytes(dbmdEndSegment)
+
+	return w.GetBytes()
+}
+
+// MarshalBinary implements encoding.BinaryMarshaler, returning the body of a dbmd chunk
+func (v *DolbyMetadata) MarshalBinary() ([]byte, error) {
+	return v.marshal(), nil
+}
+
+// UnmarshalBinary implements encoding.BinaryUnmarshaler, parsing the body of a dbmd chunk
+func (v *DolbyMetadata) UnmarshalBinary(data []byte) error {
+	parsed, err := parseDolbyMetadata(data)
+	if err != nil {
+		return err
+	}
+
+	*v = *parsed
+
+	return nil
+}
@@ -0,0 +1,1273 @@
+package pkg
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+
+	"github.com/gravestench/wav/pkg/riff"
+)
+
+const (
+	riffHeaderSize     = riff.FormHeaderSize
+	chunkHeaderSize    = riff.HeaderSize
+	decodeChunkSamples = 4096
+	writeToChunkBytes  = 64 * 1024
+	// nextChunkFrames is the number of frames Next decodes at a time from formats
+	// without blocks
+	nextChunkFrames = 1024
+	// maxStreamCapacity caps the samples DecodeAll allocates up front for a source of
+	// unknown size, whose data chunk size nothing checks
+	maxStreamCapacity = 1 << 24
+)
+
+// Errors returned while parsing a RIFF/WAVE container
+var (
+	ErrNotRIFF       = riff.ErrNotRIFF
+	ErrNotWAVE       = riff.ErrNotWAVE
+	ErrMissingFormat = errors.New("missing fmt chunk")
+	ErrMissingData   = errors.New("missing data chunk")
+	ErrNotCloneable  = errors.New("decoder source does not support concurrent reads")
+	ErrNotSeekable   = errors.New("decoder source does not support seeking backwards")
+	ErrCorruptChunk  = riff.ErrCorruptChunk
+	ErrChunkSize     = riff.ErrChunkSize
+	ErrRIFFSize      = errors.New("RIFF size doesn't match the file")
+)
+
+// DecoderOption configures how a Decoder is created
+type DecoderOption func(*decoderOptions)
+
+type decoderOptions struct {
+	mmap        bool
+	lenient     bool
+	raw         bool
+	stats       bool
+	progressive bool
+}
+
+// WithLenient makes the decoder recover from problems common in game files, such as
+// wrong chunk sizes, misplaced fmt chunks or garbage in the chunk list, recording
+// them as warnings instead of failing
+func WithLenient() DecoderOption {
+	return func(o *decoderOptions) {
+		o.lenient = true
+	}
+}
+
+// WithRawData makes the decoder accept formats it can't decode into samples, so that
+// the data chunk can still be read with ReadRaw and handed to an external decoder
+// together with the complete Format
+func WithRawData() DecoderOption {
+	return func(o *decoderOptions) {
+		o.raw = true
+	}
+}
+
+// WithStats makes the decoder count its work and time each stage, as returned by Stats
+func WithStats() DecoderOption {
+	return func(o *decoderOptions) {
+		o.stats = true
+	}
+}
+
+// WithProgressive makes the decoder suited to a file which is still being downloaded
+// or recorded. A data chunk with a streaming placeholder size runs to the end of the
+// source, a data chunk which isn't followed by another chunk where its declared size
+// ends is read on to the end of the source, and Frames only becomes final once the
+// end of the source is reached. See SizeKnown.
+func WithProgressive() DecoderOption {
+	return func(o *decoderOptions) {
+		o.progressive = true
+	}
+}
+
+func applyDecoderOptions(opts []DecoderOption) decoderOptions {
+	var result decoderOptions
+
+	for _, opt := range opts {
+		opt(&result)
+	}
+
+	return result
+}
+
+// Decoder lazily decodes the data chunk of a RIFF/WAVE file into 16-bit samples.
+// Chunk headers are read on demand and audio bytes are only pulled from the
+// source as decoding progresses. Once created, reading samples into caller
+// provided buffers reuses internal buffers and doesn't allocate.
+type Decoder struct {
+	src    io.Reader
+	seeker io.Seeker
+	// srcBase is the offset of the RIFF header in a seekable source, which positions
+	// in the file are relative to
+	srcBase    int64
+	srcPos     int64
+	srcSize    int64
+	options    decoderOptions
+	format     Format
+	dataOffset int64
+	dataSize   int64
+	// open is set while the size of the data chunk is provisional, until the end of
+	// the source is reached
+	open bool
+	// segments holds the parts of the audio when it is split across a wave list
+	segments []dataSegment
+	position int64
+	buf      []byte
+	// pending holds the decoded samples which haven't been read yet, from pendingAt on:
+	// the rest of a block codec's block, or the samples Next decoded ahead
+	pending   []int16
+	pendingAt int
+	// coefficients are the Microsoft ADPCM predictor coefficients of the fmt chunk
+	coefficients [][2]int
+	out          []byte
+	stats        statsRecorder
+	markers      markerList
+	tags         map[string]string
+	instrument   *Instrument
+	acid         *Acid
+	cart         *Cart
+	bext         *Bext
+	peaks        *PeakEnvelope
+	adm          *ADM
+	dolby        *DolbyMetadata
+	warnings     []error
+	release      func() error
+}
+
+// CreateDecoder parses the RIFF/WAVE headers of data and creates a Decoder for its
+// data chunk. No audio is decoded until samples are read.
+func CreateDecoder(data []byte, opts ...DecoderOption) (*Decoder, error) {
+	return CreateStreamDecoder(bytes.NewReader(data), opts...)
+}
+
+// CreateStreamDecoder reads the RIFF/WAVE headers from r up to the start of the
+// data chunk and creates a Decoder which reads the audio from r as needed.
+// Seeking, such as DecodeRange moving backwards, requires r to implement io.Seeker.
+func CreateStreamDecoder(r io.Reader, opts ...DecoderOption) (*Decoder, error) {
+	result := &Decoder{options: applyDecoderOptions(opts)}
+
+	if err := result.Reset(r); err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
+
+// Reset discards the state of the decoder and starts decoding the RIFF/WAVE file
+// read from r, which starts at its current position, reusing the internal buffers
+// and options. Resources held for the previous source, such as a memory mapping,
+// are released.
+func (v *Decoder) Reset(r io.Reader) error {
+	if v.release != nil {
+		if err := v.release(); err != nil {
+			return err
+		}
+	}
+
+	*v = Decoder{
+		src:     r,
+		srcSize: -1,
+		options: v.options,
+		buf:     v.buf,
+		pending: v.pending[:0],
+		out:     v.out,
+		stats:   statsRecorder{enabled: v.options.stats, stats: v.stats.stats},
+	}
+
+	if seeker, ok := r.(io.Seeker); ok {
+		// the file starts at the current position, which needn't be the start of r
+		base, err := seeker.Seek(0, io.SeekCurrent)
+		if err != nil {
+			return err
+		}
+
+		end, err := seeker.Seek(0, io.SeekEnd)
+		if err != nil {
+			return err
+		}
+
+		if _, err = seeker.Seek(base, io.SeekStart); err != nil {
+			return err
+		}
+
+		v.seeker = seeker
+		v.srcBase = base
+		v.srcSize = end - base
+	}
+
+	if err := v.readHeaders(); err != nil {
+		return err
+	}
+
+	v.coefficients = v.format.msCoefficients()
+
+	return nil
+}
+
+// readSource reads exactly len(p) bytes from the source
+func (v *Decoder) readSource(p []byte) (int, error) {
+	start := v.stats.start()
+	n, err := io.ReadFull(v.src, p)
+	v.srcPos += int64(n)
+
+	v.stats.stop(&v.stats.stats.ReadTime, start)
+	v.stats.add(&v.stats.stats.BytesIn, n)
+
+	return n, err
+}
+
+// skipTo moves the source to the offset in the file, discarding input if it can't seek
+func (v *Decoder) skipTo(offset int64) error {
+	if offset == v.srcPos {
+		return nil
+	}
+
+	switch {
+	case v.seeker != nil:
+		if _, err := v.seeker.Seek(v.srcBase+offset, io.SeekStart); err != nil {
+			return err
+		}
+	case offset > v.srcPos:
+		if _, err := io.CopyN(io.Discard, v.src, offset-v.srcPos); err != nil {
+			return err
+		}
+	default:
+		return ErrNotSeekable
+	}
+
+	v.srcPos = offset
+
+	return nil
+}
+
+// readHeaders walks the chunks up to the data chunk. If the source can seek back to
+// the data chunk, the metadata chunks following it are read as well.
+//
+//nolint:funlen,gocognit,gocyclo // chunk walking with recovery
+func (v *Decoder) readHeaders() error {
+	header := make([]byte, riffHeaderSize)
+	if n, err := v.readSource(header); err != nil {
+		return headerError(n, err)
+	}
+
+	if readFourCC(header) != ChunkRIFF && !isRF64(readFourCC(header)) {
+		return ErrNotRIFF
+	}
+
+	if readFourCC(header[8:]) != ChunkWAVE {
+		return ErrNotWAVE
+	}
+
+	rf64 := isRF64(readFourCC(header))
+	riffSize := int64(binary.LittleEndian.Uint32(header[4:8]))
+
+	if !rf64 {
+		v.checkRIFFSize(riffSize)
+	}
+
+	var sizes *ds64
+
+	offset := int64(riffHeaderSize)
+	haveFormat, haveData := false, false
+	padded := false
+
+walk:
+	for v.srcSize < 0 || offset+chunkHeaderSize <= v.srcSize {
+		if _, err := v.readSource(header[:chunkHeaderSize]); err != nil {
+			break
+		}
+
+		// some writers omit the pad byte after odd-sized chunks
+		if padded && !isChunkID(header) && v.seeker != nil {
+			if err := v.unpad(&offset, header); err != nil {
+				return err
+			}
+		}
+
+		if !isChunkID(header) {
+			err := fmt.Errorf("%w at offset %d", ErrCorruptChunk, offset)
+
+			// garbage following the data chunk doesn't prevent decoding
+			if haveData {
+				v.warn(err)
+				break
+			}
+
+			if err = v.tolerate(err); err != nil {
+				return err
+			}
+
+			break
+		}
+
+		offset += chunkHeaderSize
+		size := readChunkSize(header, sizes)
+		id := readFourCC(header)
+
+		if v.srcSize >= 0 && size > v.srcSize-offset {
+			v.warn(fmt.Errorf("%w: %q claims %d bytes, %d remain", ErrChunkSize, id, size, v.srcSize-offset))
+			size = v.srcSize - offset
+		}
+
+		switch id {
+		case ChunkDs64:
+			body := make([]byte, size)
+			if _, err := v.readSource(body); err != nil {
+				return err
+			}
+
+			table, err := parseDS64(body)
+			if err != nil {
+				return err
+			}
+
+			if rf64 {
+				sizes = table
+				v.checkRIFFSize(sizes.RIFFSize)
+			}
+		case ChunkFmt:
+			body := make([]byte, size)
+			if _, err := v.readSource(body); err != nil {
+				return err
+			}
+
+			format, err := v.parseFormat(body)
+			if err != nil {
+				return err
+			}
+
+			v.format = format
+			haveFormat = true
+		case ChunkData:
+			if !haveFormat {
+				// a fmt chunk following the data chunk can only be reached by seeking
+				if err := v.tolerate(ErrMissingFormat); err != nil || v.seeker == nil {
+					return ErrMissingFormat
+				}
+			}
+
+			v.dataOffset = offset
+			v.dataSize = size
+			haveData = true
+
+			if v.seeker == nil {
+				break walk
+			}
+		case ChunkLIST:
+			body := make([]byte, size)
+			if size >= bytesPerint32 {
+				if _, err := v.readSource(body[:bytesPerint32]); err != nil {
+					return err
+				}
+			}
+
+			if size >= bytesPerint32 && readFourCC(body) == ChunkWavl && !haveData {
+				if err := v.parseWaveList(offset+bytesPerint32, offset+size); err != nil {
+					return err
+				}
+
+				haveData = true
+
+				break
+			}
+
+			if size >= bytesPerint32 {
+				if _, err := v.readSource(body[bytesPerint32:]); err != nil {
+					return err
+				}
+			} else if _, err := v.readSource(body); err != nil {
+				return err
+			}
+
+			if err := v.readMetadata(id, body); err != nil {
+				return err
+			}
+		case ChunkCue, ChunkSmpl, ChunkInst, ChunkAcid, ChunkCart, ChunkBext, ChunkLevl, ChunkChna, ChunkAXML, ChunkDbmd:
+			body := make([]byte, size)
+			if _, err := v.readSource(body); err != nil {
+				return err
+			}
+
+			if err := v.readMetadata(id, body); err != nil {
+				return err
+			}
+		}
+
+		offset += size + chunkPadding(size)
+		padded = chunkPadding(size) != 0
+
+		if err := v.skipTo(offset); err != nil {
+			break
+		}
+	}
+
+	if !haveFormat {
+		return ErrMissingFormat
+	}
+
+	if !haveData {
+		return ErrMissingData
+	}
+
+	if err := v.format.validate(); err != nil && !v.options.raw && lookupCodec(v.format) == nil {
+		return err
+	}
+
+	if v.segments != nil {
+		v.sizeSegments()
+		return nil
+	}
+
+	v.fixDataSize()
+
+	return nil
+}
+
+// parseFormat parses a fmt chunk, repairing short chunks and inconsistent block
+// alignment in lenient mode
+func (v *Decoder) parseFormat(body []byte) (Format, error) {
+	// WAVEFORMAT without the bits per sample field
+	const shortFormatSize = fmtChunkMinSize - bytesPerint16
+
+	if len(body) >= shortFormatSize && len(body) < fmtChunkMinSize && v.options.lenient {
+		v.warn(fmt.Errorf("%w: %d bytes", ErrShortFormat, len(body)))
+		body = append(append([]byte(nil), body[:shortFormatSize]...), 0, 0)
+
+		format, err := parseWaveFormat(body)
+		if err == nil && format.Channels > 0 {
+			format.BitsPerSample = format.BlockAlign / format.Channels * bitsPerByte
+		}
+
+		return format, err
+	}
+
+	format, err := parseWaveFormat(body)
+	if err != nil {
+		return format, err
+	}
+
+	if format.validate() == nil && int(format.BlockAlign) != format.blockSize() {
+		v.warn(fmt.Errorf("%w: %d, expected %d", ErrBlockAlign, format.BlockAlign, format.blockSize()))
+
+		if v.options.lenient {
+			format.BlockAlign = uint16(format.blockSize())
+			format.AvgBytesPerSec = format.SampleRate * uint32(format.BlockAlign)
+		}
+	}
+
+	return format, nil
+}
+
+// fixDataSize treats the data chunk as running to the end of the source when its
+// size is a streaming placeholder, which lenient and progressive modes allow
+func (v *Decoder) fixDataSize() {
+	const placeholder = 0xFFFFFFFF
+
+	v.open = v.options.progressive
+
+	if !(v.options.lenient || v.options.progressive) || (v.dataSize != 0 && v.dataSize != placeholder) {
+		return
+	}
+
+	end := int64(math.MaxInt64)
+	if v.srcSize >= 0 {
+		end = v.srcSize
+	} else {
+		v.open = true
+	}
+
+	if end-v.dataOffset > v.dataSize {
+		v.warn(fmt.Errorf("%w: data chunk size %d treated as running to the end of the file", ErrChunkSize, v.dataSize))
+		v.dataSize = end - v.dataOffset
+	}
+}
+
+// checkRIFFSize warns when the size of the RIFF chunk doesn't match the source
+func (v *Decoder) checkRIFFSize(riffSize int64) {
+	if v.srcSize >= 0 && riffSize+chunkHeaderSize != v.srcSize {
+		v.warn(fmt.Errorf("%w: RIFF size %d, file size %d", ErrRIFFSize, riffSize, v.srcSize))
+	}
+}
+
+// warn records a problem which decoding can continue past
+func (v *Decoder) warn(err error) {
+	v.warnings = append(v.warnings, err)
+}
+
+// tolerate records err as a warning in lenient mode and returns it otherwise
+func (v *Decoder) tolerate(err error) error {
+	if !v.options.lenient {
+		return err
+	}
+
+	v.warn(err)
+
+	return nil
+}
+
+// Warnings returns the problems found in the file which were recovered from
+func (v *Decoder) Warnings() []error {
+	return append([]error(nil), v.warnings...)
+}
+
+// unpad re-reads the chunk header found one byte before offset, replacing header
+// if the unpadded position holds a valid chunk ID
+func (v *Decoder) unpad(offset *int64, header []byte) error {
+	if err := v.skipTo(*offset - 1); err != nil {
+		return err
+	}
+
+	unpadded := make([]byte, chunkHeaderSize)
+	if _, err := v.readSource(unpadded); err != nil {
+		return err
+	}
+
+	if isChunkID(unpadded) {
+		copy(header, unpadded)
+		*offset--
+
+		return nil
+	}
+
+	return v.skipTo(*offset + chunkHeaderSize)
+}
+
+// readMetadata parses the body of a metadata chunk, tolerating malformed chunks in
+// lenient mode
+func (v *Decoder) readMetadata(id FourCC, body []byte) error {
+	if err := v.parseMetadata(id, body); err != nil {
+		return v.tolerate(fmt.Errorf("%w: %q chunk", err, id))
+	}
+
+	return nil
+}
+
+// parseMetadata parses the body of a metadata chunk
+func (v *Decoder) parseMetadata(id FourCC, body []byte) error {
+	switch id {
+	case ChunkCue:
+		return v.markers.parseCue(body)
+	case ChunkLIST:
+		if len(body) < bytesPerint32 {
+			return nil
+		}
+
+		switch readFourCC(body) {
+		case ChunkAdtl:
+			return v.markers.parseAdtl(body[bytesPerint32:])
+		case ChunkINFO:
+			tags, err := parseInfo(body[bytesPerint32:])
+			v.tags = tags
+
+			return err
+		}
+	case ChunkSmpl:
+		return v.markers.parseSmpl(body)
+	case ChunkInst:
+		instrument, err := parseInstrument(body)
+		if err != nil {
+			return err
+		}
+
+		v.instrument = instrument
+	case ChunkAcid:
+		acid, err := parseAcid(body)
+		if err != nil {
+			return err
+		}
+
+		v.acid = acid
+	case ChunkCart:
+		cart, err := parseCart(body)
+		if err != nil {
+			return err
+		}
+
+		v.cart = cart
+	case ChunkBext:
+		bext, err := parseBext(body)
+		if err != nil {
+			return err
+		}
+
+		v.bext = bext
+	case ChunkLevl:
+		peaks, err := parsePeakEnvelope(body)
+		if err != nil {
+			return err
+		}
+
+		v.peaks = peaks
+	case ChunkChna:
+		if v.adm == nil {
+			v.adm = &ADM{}
+		}
+
+		return v.adm.parseChna(body)
+	case ChunkAXML:
+		if v.adm == nil {
+			v.adm = &ADM{}
+		}
+
+		v.adm.XML = trimString(body)
+	case ChunkDbmd:
+		dolby, err := parseDolbyMetadata(body)
+		if err != nil {
+			return err
+		}
+
+		v.dolby = dolby
+	}
+
+	return nil
+}
+
+// Markers returns the cue points of the file, named and described by the entries of
+// its associated data list, merged with the loops of its smpl chunk
+func (v *Decoder) Markers() []Marker {
+	return append([]Marker(nil), v.markers.markers...)
+}
+
+// Tags returns the text of the LIST INFO chunk keyed by ID, such as INAM or IART
+func (v *Decoder) Tags() map[string]string {
+	result := make(map[string]string, len(v.tags))
+	for id, text := range v.tags {
+		result[id] = text
+	}
+
+	return result
+}
+
+// Metadata returns the INFO tags, the bext chunk and the markers of the file
+// together, or nil if it has none of them. The result is a copy the caller may edit.
+func (v *Decoder) Metadata() *Metadata {
+	metadata := &Metadata{Tags: v.tags, Bext: v.bext, Markers: v.markers.markers}
+	if metadata.IsEmpty() {
+		return nil
+	}
+
+	return metadata.Clone()
+}
+
+// Instrument returns the contents of the inst chunk, or nil if the file has none
+func (v *Decoder) Instrument() *Instrument {
+	return v.instrument
+}
+
+// Acid returns the contents of the acid chunk, or nil if the file has none
+func (v *Decoder) Acid() *Acid {
+	return v.acid
+}
+
+// Cart returns the contents of the cart chunk, or nil if the file has none
+func (v *Decoder) Cart() *Cart {
+	return v.cart
+}
+
+// Bext returns the contents of the bext chunk, or nil if the file has none
+func (v *Decoder) Bext() *Bext {
+	return v.bext
+}
+
+// StartTimecode returns the timecode of the first sample from the TimeReference of
+// the bext chunk, or false if the file has none
+func (v *Decoder) StartTimecode(rate FrameRate) (Timecode, bool) {
+	if v.bext == nil {
+		return Timecode{}, false
+	}
+
+	return v.bext.StartTimecode(int(v.format.SampleRate), rate), true
+}
+
+// PeakEnvelope returns the contents of the levl chunk, or nil if the file has none
+func (v *Decoder) PeakEnvelope() *PeakEnvelope {
+	return v.peaks
+}
+
+// ADM returns the contents of the chna and axml chunks, or nil if the file has neither
+func (v *Decoder) ADM() *ADM {
+	return v.adm
+}
+
+// DolbyMetadata returns the contents of the dbmd chunk, or nil if the file has none
+func (v *Decoder) DolbyMetadata() *DolbyMetadata {
+	return v.dolby
+}
+
+// Stats returns the work done by the decoder so far, which is only counted for
+// decoders created WithStats. The counts accumulate across Reset; clones start
+// their own.
+func (v *Decoder) Stats() Stats {
+	return v.stats.stats
+}
+
+// Format returns the format described by the fmt chunk
+func (v *Decoder) Format() Format {
+	return v.format
+}
+
+// SampleRate returns the number of sample frames per second
+func (v *Decoder) SampleRate() int {
+	return int(v.format.SampleRate)
+}
+
+// Channels returns the number of interleaved channels
+func (v *Decoder) Channels() int {
+	return int(v.format.Channels)
+}
+
+// BitsPerSample returns the bit depth of the encoded samples
+func (v *Decoder) BitsPerSample() int {
+	return int(v.format.BitsPerSample)
+}
+
+// readEncoded reads the encoded bytes of up to count samples from the data chunk
+func (v *Decoder) readEncoded(count int) ([]byte, error) {
+	if err := v.format.validateSamples(); err != nil {
+		return nil, err
+	}
+
+	sampleSize := int64(v.format.bytesPerSample())
+
+	return v.readData(int64(count)*sampleSize, sampleSize)
+}
+
+// readData reads up to want bytes of the data chunk at the read position into the
+// internal buffer, ending at a multiple of unit bytes
+func (v *Decoder) readData(want, unit int64) ([]byte, error) {
+	remaining := v.dataSize - v.position
+	if remaining < unit && v.open {
+		if err := v.extendData(); err != nil {
+			return nil, err
+		}
+
+		remaining = v.dataSize - v.position
+	}
+
+	if want > remaining {
+		want = remaining - remaining%unit
+	}
+
+	if want <= 0 {
+		return nil, io.EOF
+	}
+
+	if int64(cap(v.buf)) < want {
+		v.buf = make([]byte, want)
+	}
+
+	var n int
+
+	var err error
+
+	if v.segments != nil {
+		n, err = v.readSegment(want)
+	} else {
+		if err = v.skipTo(v.dataOffset + v.position); err != nil {
+			return nil, err
+		}
+
+		n, err = v.readSource(v.buf[:want])
+	}
+
+	if err != nil && !errors.Is(err, io.ErrUnexpectedEOF) && !errors.Is(err, io.EOF) {
+		return nil, err
+	}
+
+	// a truncated data chunk ends at the last complete sample
+	n -= n % int(unit)
+
+	if err != nil {
+		// the end of the source settles the size of the data chunk, which a truncated
+		// file overstates
+		v.dataSize = v.position + int64(n)
+		v.open = false
+	}
+
+	if n == 0 {
+		return nil, io.EOF
+	}
+
+	v.position += int64(n)
+	v.stats.addBlocks(n, int(v.format.BlockAlign))
+
+	return v.buf[:n], nil
+}
+
+// extendData is called by a progressive decoder reaching the declared end of the data
+// chunk. Unless the next chunk header follows, the size was understated by a writer
+// which hadn't finished, and the data chunk is taken to run to the end of the source.
+func (v *Decoder) extendData() error {
+	v.open = false
+
+	if v.segments != nil || v.dataSize >= math.MaxInt64-v.dataOffset {
+		return nil
+	}
+
+	start := v.dataOffset + v.position
+	if err := v.skipTo(start); err != nil {
+		return err
+	}
+
+	end := v.dataOffset + v.dataSize + chunkPadding(v.dataSize)
+	peek := make([]byte, end-start+chunkHeaderSize)
+
+	n, err := v.readSource(peek)
+	if err != nil && !errors.Is(err, io.ErrUnexpectedEOF) && !errors.Is(err, io.EOF) {
+		return err
+	}
+
+	// put the peeked bytes back
+	if v.seeker != nil {
+		if err = v.skipTo(start); err != nil {
+			return err
+		}
+	} else {
+		v.src = io.MultiReader(bytes.NewReader(peek[:n]), v.src)
+		v.srcPos = start
+	}
+
+	if n == len(peek) && isChunkID(peek[len(peek)-chunkHeaderSize:]) {
+		return nil
+	}
+
+	if n <= int(end-start) {
+		// the source ends within the declared data chunk
+		return nil
+	}
+
+	v.warn(fmt.Errorf("%w: data chunk size %d understated, reading to the end of the source", ErrChunkSize, v.dataSize))
+	v.dataSize = math.MaxInt64 - v.dataOffset
+	v.open = true
+
+	return nil
+}
+
+// SizeKnown reports whether Frames and Duration are final. They are provisional while
+// a progressive decoder hasn't reached the end of its source, as the data chunk may
+// turn out to be longer than declared.
+func (v *Decoder) SizeKnown() bool {
+	return !v.open
+}
+
+// ReadRaw reads up to len(p) bytes of the data chunk as stored, without decoding
+// them, which together with Format lets callers decode formats this package doesn't
+// support. It shares the read position with the sample reading methods; the rest of
+// an ADPCM block whose samples were partly read is skipped, and samples Next decoded
+// ahead are read again, which fails with ErrNotSeekable if the source can't seek.
+func (v *Decoder) ReadRaw(p []byte) (int, error) {
+	if len(p) == 0 {
+		return 0, nil
+	}
+
+	v.dropPending()
+
+	data, err := v.readData(int64(len(p)), 1)
+	if err != nil {
+		return 0, err
+	}
+
+	return copy(p, data), nil
+}
+
+// ReadSamples decodes up to len(dst) interleaved samples into dst
+func (v *Decoder) ReadSamples(dst []int16) (int, error) {
+	if len(dst) == 0 {
+		return 0, nil
+	}
+
+	samples, err := v.takePending(len(dst))
+	if err != nil {
+		return 0, err
+	}
+
+	if samples != nil {
+		return copy(dst, samples), nil
+	}
+
+	return v.decodeSamples(dst)
+}
+
+// decodeSamples decodes up to len(dst) samples of a format storing every sample on
+// its own straight from the data chunk
+func (v *Decoder) decodeSamples(dst []int16) (int, error) {
+	encoded, err := v.readEncoded(len(dst))
+	if err != nil {
+		return 0, err
+	}
+
+	start := v.stats.start()
+	sampleSize := v.format.bytesPerSample()
+
+	n := 0
+	for ; n*sampleSize < len(encoded); n++ {
+		dst[n] = v.format.decodeSample(encoded[n*sampleSize:])
+	}
+
+	v.stats.stop(&v.stats.stats.DecodeTime, start)
+	v.stats.add(&v.stats.stats.BytesOut, n*bytesPerint16)
+
+	return n, nil
+}
+
+// takePending returns up to count of the decoded samples which haven't been read yet,
+// first decoding the next block of a block codec when none are left, and marks them
+// as read. It returns nil for other formats once none are left.
+func (v *Decoder) takePending(count int) ([]int16, error) {
+	if v.pendingAt == len(v.pending) {
+		if !v.format.isBlockCodec() {
+			return nil, nil
+		}
+
+		v.pending, v.pendingAt = v.pending[:0], 0
+
+		if err := v.decodeBlock(); err != nil {
+			return nil, err
+		}
+	}
+
+	end := v.pendingAt + count
+	if end > len(v.pending) {
+		end = len(v.pending)
+	}
+
+	result := v.pending[v.pendingAt:end]
+	v.pendingAt = end
+
+	return result, nil
+}
+
+// decodeBlock decodes the next block of a block codec, appending its samples to the
+// pending ones. The final block may be short, and ends the data if it doesn't hold a
+// whole block header.
+func (v *Decoder) decodeBlock() error {
+	block, err := v.readData(int64(v.format.BlockAlign), 1)
+	if err != nil {
+		return err
+	}
+
+	if v.format.blockFrames(len(block)) == 0 {
+		return io.EOF
+	}
+
+	start := v.stats.start()
+	count := len(v.pending)
+
+	v.pending, err = v.format.decodeBlock(v.pending, block, v.coefficients)
+
+	v.stats.stop(&v.stats.stats.DecodeTime, start)
+	v.stats.add(&v.stats.stats.BytesOut, (len(v.pending)-count)*bytesPerint16)
+
+	return err
+}
+
+// fillPending decodes samples after the pending ones until at least count are
+// pending, a block at a time for block codecs and nextChunkFrames frames at a time
+// for other formats. Ending with fewer than count fails with io.ErrUnexpectedEOF.
+func (v *Decoder) fillPending(count int) error {
+	// move the unread samples to the front so the buffer doesn't grow
+	v.pending = v.pending[:copy(v.pending, v.pending[v.pendingAt:])]
+	v.pendingAt = 0
+
+	for len(v.pending) < count {
+		var err error
+
+		if v.format.isBlockCodec() {
+			err = v.decodeBlock()
+		} else {
+			err = v.decodeChunk()
+		}
+
+		if errors.Is(err, io.EOF) && len(v.pending) > 0 {
+			return io.ErrUnexpectedEOF
+		}
+
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// decodeChunk decodes up to nextChunkFrames frames of a format without blocks,
+// appending them to the pending samples
+func (v *Decoder) decodeChunk() error {
+	start := len(v.pending)
+	count := nextChunkFrames * int(v.format.Channels)
+
+	if cap(v.pending)-start < count {
+		v.pending = append(v.pending, make([]int16, count)...)
+	}
+
+	n, err := v.decodeSamples(v.pending[start : start+count])
+	v.pending = v.pending[:start+n]
+
+	return err
+}
+
+// dropPending discards the decoded samples which haven't been read yet. Those of
+// formats without blocks are read again from the data chunk.
+func (v *Decoder) dropPending() {
+	if !v.format.isBlockCodec() {
+		v.position -= int64((len(v.pending) - v.pendingAt) * v.format.bytesPerSample())
+	}
+
+	v.pending, v.pendingAt = v.pending[:0], 0
+}
+
+// DecodeAll decodes the remainder of the data chunk into interleaved samples, using
+// the decoder registered with RegisterCodec for formats this package can't decode
+func (v *Decoder) DecodeAll() ([]int16, error) {
+	if v.format.validate() != nil {
+		if codec := lookupCodec(v.format); codec != nil {
+			start := v.stats.start()
+			samples, err := codec(v.format, v.RawReader())
+
+			v.stats.stop(&v.stats.stats.DecodeTime, start)
+			v.stats.add(&v.stats.stats.BytesOut, len(samples)*bytesPerint16)
+
+			return samples, err
+		}
+	}
+
+	var capacity int64
+	if !v.open {
+		capacity = v.Frames() * int64(v.format.Channels)
+	}
+
+	if v.srcSize < 0 && capacity > maxStreamCapacity {
+		capacity = maxStreamCapacity
+	}
+
+	result := make([]int16, 0, capacity)
+	buf := make([]int16, decodeChunkSamples)
+
+	for {
+		n, err := v.ReadSamples(buf)
+		result = append(result, buf[:n]...)
+
+		if errors.Is(err, io.EOF) {
+			return result, nil
+		}
+
+		if err != nil {
+			return nil, err
+		}
+	}
+}
+
+// Read implements io.Reader, producing little-endian 16-bit samples
+func (v *Decoder) Read(p []byte) (int, error) {
+	if len(p) < bytesPerint16 {
+		return 0, nil
+	}
+
+	samples, err := v.takePending(len(p) / bytesPerint16)
+	if err != nil {
+		return 0, err
+	}
+
+	if samples != nil {
+		for i, sample := range samples {
+			p[i*2] = byte(sample)
+			p[i*2+1] = byte(uint16(sample) >> bitsPerByte)
+		}
+
+		return len(samples) * bytesPerint16, nil
+	}
+
+	encoded, err := v.readEncoded(len(p) / bytesPerint16)
+	if err != nil {
+		return 0, err
+	}
+
+	start := v.stats.start()
+	sampleSize := v.format.bytesPerSample()
+
+	n := 0
+	for i := 0; i < len(encoded); i += sampleSize {
+		sample := v.format.decodeSample(encoded[i:])
+		p[n] = byte(sample)
+		p[n+1] = byte(uint16(sample) >> bitsPerByte)
+		n += bytesPerint16
+	}
+
+	v.stats.stop(&v.stats.stats.DecodeTime, start)
+	v.stats.add(&v.stats.stats.BytesOut, n)
+
+	return n, nil
+}
+
+// WriteTo implements io.WriterTo, writing the remainder of the data chunk to w as the
+// little-endian 16-bit samples Read produces. io.Copy uses it to move audio in large
+// chunks, and 16-bit PCM is written straight from the source without conversion.
+func (v *Decoder) WriteTo(w io.Writer) (int64, error) {
+	direct := v.format.effectiveTag() == FormatPCM && v.format.BitsPerSample == d2BitsPerSample
+
+	if (!direct || v.pendingAt < len(v.pending)) && len(v.out) < writeToChunkBytes {
+		v.out = make([]byte, writeToChunkBytes)
+	}
+
+	var total int64
+
+	for {
+		var chunk []byte
+
+		var err error
+
+		if direct && v.pendingAt == len(v.pending) {
+			chunk, err = v.readEncoded(writeToChunkBytes / bytesPerint16)
+			v.stats.add(&v.stats.stats.BytesOut, len(chunk))
+		} else {
+			var n int
+			n, err = v.Read(v.out)
+			chunk = v.out[:n]
+		}
+
+		if errors.Is(err, io.EOF) {
+			return total, nil
+		}
+
+		if err != nil {
+			return total, err
+		}
+
+		n, err := w.Write(chunk)
+		total += int64(n)
+
+		if err != nil {
+			return total, err
+		}
+	}
+}
+
+// Next returns the next sample frame, holding one sample per channel. Frames are
+// decoded a block at a time into an internal buffer the returned slice points into,
+// so it is only valid until the next read. io.EOF is returned after the last frame.
+func (v *Decoder) Next() ([]int16, error) {
+	channels := int(v.format.Channels)
+
+	if len(v.pending)-v.pendingAt < channels {
+		if err := v.fillPending(channels); err != nil {
+			return nil, err
+		}
+	}
+
+	frame := v.pending[v.pendingAt : v.pendingAt+channels]
+	v.pendingAt += channels
+
+	return frame, nil
+}
+
+// Clone returns a Decoder sharing the read-only source of v but with its own read
+// position, buffers and copy of the metadata, so several goroutines can decode
+// different regions of the same file at once. The source must implement io.ReaderAt,
+// which byte slices, memory mappings and *os.File all do. Clones must not be used
+// after v is closed.
+func (v *Decoder) Clone() (*Decoder, error) {
+	readerAt, ok := v.src.(io.ReaderAt)
+	if !ok || v.srcSize < 0 {
+		return nil, ErrNotCloneable
+	}
+
+	section := io.NewSectionReader(readerAt, v.srcBase, v.srcSize)
+
+	result := &Decoder{
+		src:          section,
+		seeker:       section,
+		srcSize:      v.srcSize,
+		options:      v.options,
+		format:       v.format,
+		dataOffset:   v.dataOffset,
+		dataSize:     v.dataSize,
+		open:         v.open,
+		position:     v.position,
+		pending:      append([]int16(nil), v.pending[v.pendingAt:]...),
+		coefficients: append([][2]int(nil), v.coefficients...),
+		stats:        statsRecorder{enabled: v.options.stats},
+	}
+
+	result.format.Extension = append([]byte(nil), v.format.Extension...)
+	result.segments = append([]dataSegment(nil), v.segments...)
+	result.copyMetadata(v)
+
+	return result, nil
+}
+
+// copyMetadata sets the metadata of v to deep copies of that parsed by from, so
+// changes made through either decoder's accessors don't show up in the other
+func (v *Decoder) copyMetadata(from *Decoder) {
+	metadata := (&Metadata{Bext: from.bext, Markers: from.markers.markers}).Clone()
+	v.bext = metadata.Bext
+
+	if from.markers.markers != nil {
+		v.markers.markers = metadata.Markers
+	}
+
+	if from.tags != nil {
+		v.tags = from.Tags()
+	}
+
+	if from.instrument != nil {
+		instrument := *from.instrument
+		v.instrument = &instrument
+	}
+
+	if from.acid != nil {
+		acid := *from.acid
+		v.acid = &acid
+	}
+
+	if from.cart != nil {
+		cart := *from.cart
+		v.cart = &cart
+	}
+
+	if from.peaks != nil {
+		peaks := *from.peaks
+		peaks.Peaks = append([]uint16(nil), peaks.Peaks...)
+		v.peaks = &peaks
+	}
+
+	if from.adm != nil {
+		adm := *from.adm
+		adm.Entries = append([]ADMTrack(nil), adm.Entries...)
+		v.adm = &adm
+	}
+
+	if from.dolby != nil {
+		dolby := *from.dolby
+		dolby.Raw = append([]byte(nil), dolby.Raw...)
+		dolby.Segments = append([]DolbySegment(nil), dolby.Segments...)
+
+		for i := range dolby.Segments {
+			dolby.Segments[i].Payload = append([]byte(nil), dolby.Segments[i].Payload...)
+		}
+
+		v.dolby = &dolby
+	}
+
+	v.warnings = append([]error(nil), from.warnings...)
+}
+
+// Close releases the resources held by the decoder, such as a memory mapping
+func (v *Decoder) Close() error {
+	v.src = bytes.NewReader(nil)
+	v.seeker = nil
+	v.dataSize = 0
+	v.open = false
+
+	if v.release == nil {
+		return nil
+	}
+
+	release := v.release
+	v.release = nil
+
+	return release()
+}
@@ -0,0 +1,136 @@
+package pkg
+
+import (
+	"errors"
+	"io"
+)
+
+// ErrChannelMismatch is returned when a buffer doesn't have one slice per channel
+var ErrChannelMismatch = errors.New("buffer channel count doesn't match the format")
+
+// ReadPlanar decodes up to len(dst[0]) frames into dst, which must hold one slice per
+// channel. Samples are scaled to the range [-1, 1) using the full source bit depth.
+func (v *Decoder) ReadPlanar(dst [][]float64) (int, error) {
+	channels := int(v.format.Channels)
+	if len(dst) != channels {
+		return 0, ErrChannelMismatch
+	}
+
+	frames := len(dst[0])
+	for _, ch := range dst[1:] {
+		if len(ch) < frames {
+			frames = len(ch)
+		}
+	}
+
+	if frames == 0 {
+		return 0, nil
+	}
+
+	if !v.format.isBlockCodec() && v.seeker != nil {
+		// read samples Next decoded ahead again at the full source bit depth
+		v.dropPending()
+	}
+
+	if rest := len(v.pending) - v.pendingAt; rest > 0 && rest < channels {
+		// a sample read stopped inside the last pending frame, which is completed from
+		// the samples following it as Next does, and fails with io.ErrUnexpectedEOF if
+		// the data ends first
+		if err := v.fillPending(channels); err != nil {
+			return 0, err
+		}
+	}
+
+	samples, err := v.takePending(frames * channels)
+	if err != nil {
+		return 0, err
+	}
+
+	if samples != nil {
+		// leave a partial frame for the next read
+		n := len(samples) / channels
+		v.pendingAt -= len(samples) - n*channels
+
+		for i := 0; i < n; i++ {
+			for ch := 0; ch < channels; ch++ {
+				dst[ch][i] = int16ToFloat(samples[i*channels+ch])
+			}
+		}
+
+		return n, nil
+	}
+
+	encoded, err := v.readEncoded(frames * channels)
+	if err != nil {
+		return 0, err
+	}
+
+	start := v.stats.start()
+	sampleSize := v.format.bytesPerSample()
+	frameSize := sampleSize * channels
+
+	n := 0
+	for ; (n+1)*frameSize <= len(encoded); n++ {
+		for ch := 0; ch < channels; ch++ {
+			dst[ch][n] = v.format.decodeFloat(encoded[n*frameSize+ch*sampleSize:])
+		}
+	}
+
+	v.stats.stop(&v.stats.stats.DecodeTime, start)
+	v.stats.add(&v.stats.stats.BytesOut, n*channels*bytesPerint64)
+
+	return n, nil
+}
+
+// DecodePlanar decodes the remainder of the data chunk into one float64 slice per channel
+func (v *Decoder) DecodePlanar() ([][]float64, error) {
+	if err := v.format.validate(); err != nil {
+		return nil, err
+	}
+
+	channels := int(v.format.Channels)
+	remaining := v.Frames() - v.format.OffsetFrames(v.position) + int64(len(v.pending)-v.pendingAt)/int64(channels)
+	size := remaining
+
+	if v.open {
+		// the size is unknown until the end of the source, so the output grows
+		size = decodeChunkSamples
+	}
+
+	result := make([][]float64, channels)
+	for ch := range result {
+		result[ch] = make([]float64, size)
+	}
+
+	n := 0
+	for v.open || int64(n) < remaining {
+		if n == len(result[0]) {
+			for ch := range result {
+				result[ch] = append(result[ch], make([]float64, n)...)
+			}
+		}
+
+		window := make([][]float64, channels)
+		for ch := range window {
+			window[ch] = result[ch][n:]
+		}
+
+		read, err := v.ReadPlanar(window)
+		n += read
+
+		// a read without progress holds less than a frame, and ends the data
+		if errors.Is(err, io.EOF) || (read == 0 && err == nil) {
+			break
+		}
+
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	for ch := range result {
+		result[ch] = result[ch][:n]
+	}
+
+	return result, nil
+}
@@ -0,0 +1,105 @@
+package pkg_test
+
+import (
+	"errors"
+	"io"
+	"testing"
+	"time"
+
+	"github.com/gravestench/wav/pkg"
+	"github.com/gravestench/wav/pkg/adpcm"
+)
+
+// planarTimeout bounds the planar reads of a test, which used to spin forever
+const planarTimeout = 5 * time.Second
+
+// readSamples reads count samples of decoder, whatever the size of each read
+func readSamples(t *testing.T, decoder *pkg.Decoder, count int) {
+	t.Helper()
+
+	dst := make([]int16, count)
+
+	for n := 0; n < count; {
+		read, err := decoder.ReadSamples(dst[n:])
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		n += read
+	}
+}
+
+// withTimeout fails the test if f doesn't return within planarTimeout. f runs on
+// another goroutine, so it must report failures with t.Error.
+func withTimeout(t *testing.T, f func()) {
+	t.Helper()
+
+	done := make(chan struct{})
+
+	go func() {
+		defer close(done)
+		f()
+	}()
+
+	select {
+	case <-done:
+	case <-time.After(planarTimeout):
+		t.Fatal("planar decoding didn't return")
+	}
+}
+
+// TestReadPlanarPartialFrame reads planar frames after a sample read stopped inside the
+// last decoded frame of an ADPCM block, which must complete the frame from the next
+// block rather than make no progress, or fail if the data ends first
+func TestReadPlanarPartialFrame(t *testing.T) {
+	for _, tag := range []uint16{pkg.FormatIMAADPCM, pkg.FormatADPCM} {
+		data := adpcmFile(tag, 2, time.Second/10)
+
+		decoder, err := pkg.CreateDecoder(data)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		whole, err := decoder.DecodeAll()
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if decoder, err = pkg.CreateDecoder(data); err != nil {
+			t.Fatal(err)
+		}
+
+		blockFrames := adpcm.IMAFramesPerBlock(adpcmBlockSize, 2)
+		if tag == pkg.FormatADPCM {
+			blockFrames = adpcm.MSFramesPerBlock(adpcmBlockSize, 2)
+		}
+
+		readSamples(t, decoder, blockFrames*2-1)
+
+		withTimeout(t, func() {
+			planar, err := decoder.DecodePlanar()
+			if err != nil {
+				t.Error(err)
+				return
+			}
+
+			if want := len(whole)/2 - blockFrames; len(planar[0]) != want {
+				t.Errorf("format %#x: decoded %d frames, want %d", tag, len(planar[0]), want)
+			}
+		})
+
+		if decoder, err = pkg.CreateDecoder(data); err != nil {
+			t.Fatal(err)
+		}
+
+		readSamples(t, decoder, len(whole)-1)
+
+		withTimeout(t, func() {
+			planar := [][]float64{make([]float64, 8), make([]float64, 8)}
+
+			if _, err := decoder.ReadPlanar(planar); !errors.Is(err, io.ErrUnexpectedEOF) {
+				t.Errorf("format %#x: reading the rest of a frame returned %v, want %v", tag, err, io.ErrUnexpectedEOF)
+			}
+		})
+	}
+}
@@ -0,0 +1,75 @@
+package pkg
+
+import (
+	"errors"
+	"io"
+	"time"
+)
+
+// ErrInvalidRange is returned when a requested time range is empty or negative
+var ErrInvalidRange = errors.New("invalid time range")
+
+// Frames returns the total number of sample frames in the data chunk
+func (v *Decoder) Frames() int64 {
+	return v.format.dataFrames(v.dataSize)
+}
+
+// Duration returns the playing time of the data chunk
+func (v *Decoder) Duration() time.Duration {
+	return v.format.FramesToDuration(v.Frames())
+}
+
+// seekFrame moves the read position to the start of the block containing frame,
+// dropping the samples decoded from the current block, and returns the number of
+// frames which must be decoded and discarded to reach frame itself
+func (v *Decoder) seekFrame(frame int64) int64 {
+	v.dropPending()
+	v.position = v.format.FrameOffset(frame)
+	if v.position > v.dataSize {
+		v.position = v.dataSize
+	}
+
+	return frame - v.format.OffsetFrames(v.position)
+}
+
+// DecodeRange decodes the interleaved samples between start and end, seeking
+// straight to the block containing start and decoding forward from its first frame,
+// which for ADPCM is the only place decoding can resume. The read position is left
+// at end.
+func (v *Decoder) DecodeRange(start, end time.Duration) ([]int16, error) {
+	if start < 0 || end <= start {
+		return nil, ErrInvalidRange
+	}
+
+	first := v.format.DurationToFrames(start)
+	last := v.format.DurationToFrames(end)
+
+	if total := v.Frames(); last > total {
+		last = total
+	}
+
+	if first >= last {
+		return nil, nil
+	}
+
+	channels := int64(v.format.Channels)
+	skip := v.seekFrame(first) * channels
+
+	result := make([]int16, (last-first)*channels+skip)
+
+	n := 0
+	for n < len(result) {
+		read, err := v.ReadSamples(result[n:])
+		n += read
+
+		if errors.Is(err, io.EOF) {
+			break
+		}
+
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return result[skip:n], nil
+}
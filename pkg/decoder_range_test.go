@@ -0,0 +1,67 @@
+package pkg_test
+
+import (
+	"errors"
+	"io"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/gravestench/wav/pkg"
+)
+
+// TestDecodeRangeADPCM decodes ranges starting and ending inside ADPCM blocks, in any
+// order and after partial reads, which must match the same frames of the whole file
+func TestDecodeRangeADPCM(t *testing.T) {
+	ranges := [][2]time.Duration{
+		{10 * time.Millisecond, 20 * time.Millisecond},
+		{123 * time.Millisecond, 456 * time.Millisecond},
+		{time.Millisecond, 2 * time.Millisecond},
+		{900 * time.Millisecond, 2 * time.Second},
+		{0, 5 * time.Millisecond},
+	}
+
+	for _, tag := range []uint16{pkg.FormatIMAADPCM, pkg.FormatADPCM} {
+		data := adpcmFile(tag, 2, time.Second)
+
+		decoder, err := pkg.CreateDecoder(data)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		whole, err := decoder.DecodeAll()
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if decoder, err = pkg.CreateDecoder(data); err != nil {
+			t.Fatal(err)
+		}
+
+		format := decoder.Format()
+		channels := int64(format.Channels)
+		frames := int64(len(whole)) / channels
+
+		for _, r := range ranges {
+			// leave decoded samples of the current block unread, unless the last range
+			// ended the data
+			if _, err = decoder.ReadSamples(make([]int16, 6)); err != nil && !errors.Is(err, io.EOF) {
+				t.Fatal(err)
+			}
+
+			got, err := decoder.DecodeRange(r[0], r[1])
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			first, last := format.DurationToFrames(r[0]), format.DurationToFrames(r[1])
+			if last > frames {
+				last = frames
+			}
+
+			if want := whole[first*channels : last*channels]; !reflect.DeepEqual(got, want) {
+				t.Errorf("format %#x: %v to %v differs from the whole file", tag, r[0], r[1])
+			}
+		}
+	}
+}
@@ -0,0 +1,357 @@
+package pkg_test
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"io"
+	"math"
+	"math/rand"
+	"testing"
+	"time"
+
+	"github.com/gravestench/wav/pkg"
+)
+
+const (
+	benchmarkDuration = 10 * time.Second
+	benchmarkSamples  = 4096
+	adpcmBlockSize    = 1024
+)
+
+// decoderFixture is a WAV file the decoder benchmarks read
+type decoderFixture struct {
+	name string
+	data []byte
+}
+
+// decoderFixtures returns stereo files of every kind of format the decoder reads
+// differently: samples of each bit depth, floats and ADPCM blocks
+func decoderFixtures() []decoderFixture {
+	samples := sineSamples(2, benchmarkDuration)
+
+	return []decoderFixture{
+		{"pcm16", encodeSamples(pkg.CreatePCMFormat(testRate, 16, 2), samples)},
+		{"pcm24", encodeSamples(pkg.CreatePCMFormat(testRate, 24, 2), samples)},
+		{"float32", encodeSamples(floatFormat(testRate, 2), samples)},
+		{"ima-adpcm", adpcmFile(pkg.FormatIMAADPCM, 2, benchmarkDuration)},
+		{"ms-adpcm", adpcmFile(pkg.FormatADPCM, 2, benchmarkDuration)},
+	}
+}
+
+// floatFormat returns the Format of 32-bit IEEE float samples
+func floatFormat(sampleRate, channels int) pkg.Format {
+	format := pkg.CreatePCMFormat(sampleRate, 32, channels)
+	format.FormatTag = pkg.FormatIEEEFloat
+
+	return format
+}
+
+// encodeSamples returns a WAV file of samples in format
+func encodeSamples(format pkg.Format, samples []int16) []byte {
+	var result bytes.Buffer
+
+	encoder, err := pkg.CreateFormatEncoder(&result, format)
+	if err == nil {
+		err = encoder.WriteSamples(samples)
+	}
+
+	if err == nil {
+		err = encoder.Close()
+	}
+
+	if err != nil {
+		panic(err)
+	}
+
+	return result.Bytes()
+}
+
+// adpcmFile returns a WAV file holding d of random IMA or Microsoft ADPCM blocks.
+// Decoding costs the same whatever the codes, so there is no need to encode real audio.
+func adpcmFile(tag uint16, channels int, d time.Duration) []byte {
+	format := pkg.Format{
+		FormatTag:     tag,
+		Channels:      uint16(channels),
+		SampleRate:    testRate,
+		BlockAlign:    adpcmBlockSize,
+		BitsPerSample: 4,
+	}
+	format.AvgBytesPerSec = format.SampleRate / 2 * uint32(channels)
+
+	data := make([]byte, int64(format.AvgBytesPerSec)*int64(d)/int64(time.Second))
+	data = data[:len(data)/adpcmBlockSize*adpcmBlockSize]
+
+	random := rand.New(rand.NewSource(1))
+	random.Read(data)
+
+	if tag == pkg.FormatADPCM {
+		for block := 0; block < len(data); block += adpcmBlockSize {
+			for ch := 0; ch < channels; ch++ {
+				// a standard predictor and a delta of at least 16
+				data[block+ch] %= 7
+				data[block+channels+ch*2+1] = 1
+			}
+		}
+	}
+
+	var result bytes.Buffer
+
+	encoder, err := pkg.CreateFormatEncoder(&result, format)
+	if err == nil {
+		_, err = encoder.Write(data)
+	}
+
+	if err == nil {
+		err = encoder.Close()
+	}
+
+	if err != nil {
+		panic(err)
+	}
+
+	return result.Bytes()
+}
+
+// benchmarkDecoder calls read for every iteration, starting the decoder over when it
+// reaches the end of the data. The first read, which sizes the internal buffers, and
+// restarting are left out of the measurements, so they only show the steady state,
+// which must not allocate.
+func benchmarkDecoder(b *testing.B, data []byte, read func(decoder *pkg.Decoder) error) {
+	b.Helper()
+
+	source := bytes.NewReader(data)
+
+	decoder, err := pkg.CreateStreamDecoder(source)
+	if err != nil {
+		b.Fatal(err)
+	}
+
+	if err = read(decoder); err != nil {
+		b.Fatal(err)
+	}
+
+	b.ReportAllocs()
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i++ {
+		err = read(decoder)
+		if err == nil {
+			continue
+		}
+
+		if !errors.Is(err, io.EOF) {
+			b.Fatal(err)
+		}
+
+		b.StopTimer()
+
+		if _, err = source.Seek(0, io.SeekStart); err == nil {
+			err = decoder.Reset(source)
+		}
+
+		if err != nil {
+			b.Fatal(err)
+		}
+
+		b.StartTimer()
+	}
+}
+
+func BenchmarkDecoderReadSamples(b *testing.B) {
+	for _, fixture := range decoderFixtures() {
+		b.Run(fixture.name, func(b *testing.B) {
+			samples := make([]int16, benchmarkSamples)
+
+			benchmarkDecoder(b, fixture.data, func(decoder *pkg.Decoder) error {
+				_, err := decoder.ReadSamples(samples)
+				return err
+			})
+		})
+	}
+}
+
+func BenchmarkDecoderRead(b *testing.B) {
+	for _, fixture := range decoderFixtures() {
+		b.Run(fixture.name, func(b *testing.B) {
+			p := make([]byte, benchmarkSamples*2)
+
+			benchmarkDecoder(b, fixture.data, func(decoder *pkg.Decoder) error {
+				_, err := decoder.Read(p)
+				return err
+			})
+		})
+	}
+}
+
+func BenchmarkDecoderReadPlanar(b *testing.B) {
+	for _, fixture := range decoderFixtures() {
+		b.Run(fixture.name, func(b *testing.B) {
+			planar := [][]float64{make([]float64, benchmarkSamples/2), make([]float64, benchmarkSamples/2)}
+
+			benchmarkDecoder(b, fixture.data, func(decoder *pkg.Decoder) error {
+				_, err := decoder.ReadPlanar(planar)
+				return err
+			})
+		})
+	}
+}
+
+func BenchmarkDecoderNext(b *testing.B) {
+	for _, fixture := range decoderFixtures() {
+		b.Run(fixture.name, func(b *testing.B) {
+			benchmarkDecoder(b, fixture.data, func(decoder *pkg.Decoder) error {
+				_, err := decoder.Next()
+				return err
+			})
+		})
+	}
+}
+
+func BenchmarkDecodeBlockTo(b *testing.B) {
+	samples := sineSamples(1, time.Second/10)
+
+	state, err := pkg.CreateADPCMState(4, samples[0])
+	if err != nil {
+		b.Fatal(err)
+	}
+
+	start := state
+
+	block, err := pkg.EncodeBlock(samples[1:], &state)
+	if err != nil {
+		b.Fatal(err)
+	}
+
+	dst := make([]int16, 0, len(samples))
+
+	b.ReportAllocs()
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i++ {
+		state = start
+
+		if dst, err = pkg.DecodeBlockTo(dst[:0], block, &state); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+// onlyReader hides every method of its reader but Read, such as io.Seeker, like a
+// network stream
+type onlyReader struct {
+	io.Reader
+}
+
+// TestStreamDecoderBoundedMemory decodes files from a source which can't seek, and
+// checks that a file 16 times as long allocates about as much as a short one, and
+// less than its size
+func TestStreamDecoderBoundedMemory(t *testing.T) {
+	var counts [2]float64
+
+	var sizes [2]uint64
+
+	for i, d := range []time.Duration{time.Second, 16 * time.Second} {
+		samples := noiseSamples(2, d)
+		data := encodeSamples(pkg.CreatePCMFormat(testRate, 16, 2), samples)
+
+		counts[i], sizes[i] = allocations(func() {
+			decoder, err := pkg.CreateStreamDecoder(onlyReader{bytes.NewReader(data)})
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if n := drain(t, decoder); n != len(samples)*2 {
+				t.Fatalf("%v: read %d bytes, want %d", d, n, len(samples)*2)
+			}
+		})
+
+		if i == 1 && sizes[i] >= uint64(len(data)) {
+			t.Errorf("allocated %d bytes streaming a %d byte file", sizes[i], len(data))
+		}
+	}
+
+	if grew(counts, sizes) {
+		t.Errorf("a 16 times longer file made %v allocations of %d bytes, up from %v of %d",
+			counts[1], sizes[1], counts[0], sizes[0])
+	}
+}
+
+// TestDecodeMalformedDS64 decodes RF64 files whose ds64 chunk gives the data chunk a
+// size which is negative as a signed integer or larger than the file, which must fail
+// rather than panic, whether or not the source can seek
+func TestDecodeMalformedDS64(t *testing.T) {
+	// the data size follows the RF64 header, the ds64 chunk header and the RIFF size
+	const dataSizeAt = 28
+
+	var output bytes.Buffer
+
+	encoder, err := pkg.CreateFormatEncoder(&output, pkg.CreatePCMFormat(testRate, 16, 2))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	encoder.SetRF64(true)
+
+	if err = encoder.WriteSamples(sineSamples(2, time.Second/100)); err != nil {
+		t.Fatal(err)
+	}
+
+	if err = encoder.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, size := range []uint64{1 << 63, math.MaxUint64, math.MaxInt64} {
+		data := append([]byte(nil), output.Bytes()...)
+		binary.LittleEndian.PutUint64(data[dataSizeAt:], size)
+
+		sources := map[string]io.Reader{
+			"seekable": bytes.NewReader(data),
+			"stream":   onlyReader{bytes.NewReader(data)},
+		}
+
+		for name, source := range sources {
+			decoder, err := pkg.CreateStreamDecoder(source, pkg.WithLenient())
+			if err == nil {
+				_, err = decoder.DecodeAll()
+			}
+
+			if !errors.Is(err, pkg.ErrChunkSize) {
+				t.Errorf("%s, data size %#x: decoding returned %v, want %v", name, size, err, pkg.ErrChunkSize)
+			}
+		}
+	}
+}
+
+// errorReader fails every read with err
+type errorReader struct {
+	err error
+}
+
+func (v errorReader) Read([]byte) (int, error) {
+	return 0, v.err
+}
+
+// TestDecoderHeaderErrors reads sources which end before the RIFF header, don't start
+// with one or fail, which must each be reported as what went wrong
+func TestDecoderHeaderErrors(t *testing.T) {
+	errRead := errors.New("read failed")
+
+	sources := []struct {
+		name string
+		r    io.Reader
+		want error
+	}{
+		{"empty", bytes.NewReader(nil), io.ErrUnexpectedEOF},
+		{"short", bytes.NewReader([]byte("RIFF\x04")), io.ErrUnexpectedEOF},
+		{"not RIFF", bytes.NewReader([]byte("FORM\x04\x00\x00\x00AIFF")), pkg.ErrNotRIFF},
+		{"not WAVE", bytes.NewReader([]byte("RIFF\x04\x00\x00\x00AVI ")), pkg.ErrNotWAVE},
+		{"failing", onlyReader{errorReader{errRead}}, errRead},
+	}
+
+	for _, source := range sources {
+		if _, err := pkg.CreateStreamDecoder(source.r); !errors.Is(err, source.want) {
+			t.Errorf("%s: returned %v, want %v", source.name, err, source.want)
+		}
+	}
+}
@@ -0,0 +1,128 @@
+package pkg
+
+import (
+	"encoding/binary"
+	"fmt"
+)
+
+// dataSegment is a part of the audio of a wave list: a data chunk, or a slnt chunk
+// standing for a run of silence
+type dataSegment struct {
+	// offset is the position of the data chunk body in the source
+	offset int64
+	// size is the size of the data chunk, or of the silence once the format is known
+	size int64
+	// silentFrames is the length of a slnt chunk in sample frames
+	silentFrames int64
+	silent       bool
+}
+
+// parseWaveList records the data and slnt chunks of a LIST wavl chunk whose chunks
+// lie between the offsets start and end. Reading them needs a seekable source.
+func (v *Decoder) parseWaveList(start, end int64) error {
+	if v.seeker == nil {
+		return fmt.Errorf("%w: wave lists need a seekable source", ErrNotSeekable)
+	}
+
+	header := make([]byte, chunkHeaderSize)
+
+	for offset := start; offset+chunkHeaderSize <= end; {
+		if err := v.skipTo(offset); err != nil {
+			return err
+		}
+
+		if _, err := v.readSource(header); err != nil {
+			return err
+		}
+
+		id := readFourCC(header)
+		size := int64(binary.LittleEndian.Uint32(header[4:]))
+		offset += chunkHeaderSize
+
+		if size > end-offset {
+			v.warn(fmt.Errorf("%w: %q claims %d bytes, %d remain in the wave list", ErrChunkSize, id, size, end-offset))
+			size = end - offset
+		}
+
+		switch id {
+		case ChunkData:
+			v.segments = append(v.segments, dataSegment{offset: offset, size: size})
+		case ChunkSlnt:
+			frames := make([]byte, bytesPerint32)
+			if _, err := v.readSource(frames); err != nil {
+				return err
+			}
+
+			v.segments = append(v.segments, dataSegment{silentFrames: int64(binary.LittleEndian.Uint32(frames)), silent: true})
+		}
+
+		offset += size + chunkPadding(size)
+	}
+
+	return nil
+}
+
+// sizeSegments sizes the silence of the wave list from the format and totals the
+// size of the audio
+func (v *Decoder) sizeSegments() {
+	v.dataSize = 0
+
+	for i := range v.segments {
+		if v.segments[i].silent {
+			v.segments[i].size = v.segments[i].silentFrames * int64(v.format.blockSize())
+		}
+
+		v.dataSize += v.segments[i].size
+	}
+}
+
+// segmentAt returns the wave list segment holding the byte at position of the audio,
+// and the position its audio starts at
+func (v *Decoder) segmentAt(position int64) (dataSegment, int64) {
+	start := int64(0)
+
+	for _, segment := range v.segments {
+		if position < start+segment.size {
+			return segment, start
+		}
+
+		start += segment.size
+	}
+
+	return dataSegment{}, start
+}
+
+// readSegment reads up to want bytes of audio of a wave list into v.buf, synthesizing
+// silence for slnt chunks
+func (v *Decoder) readSegment(want int64) (int, error) {
+	segment, start := v.segmentAt(v.position)
+
+	if remaining := start + segment.size - v.position; want > remaining {
+		want = remaining
+	}
+
+	if !segment.silent {
+		if err := v.skipTo(segment.offset + v.position - start); err != nil {
+			return 0, err
+		}
+
+		return v.readSource(v.buf[:want])
+	}
+
+	silence := byte(0)
+
+	switch tag := v.format.effectiveTag(); {
+	case tag == FormatPCM && v.format.BitsPerSample == bitsPerByte:
+		silence = pcm8Offset
+	case tag == FormatMuLaw:
+		silence = muLawSilence
+	case tag == FormatALaw:
+		silence = aLawSilence
+	}
+
+	for i := range v.buf[:want] {
+		v.buf[i] = silence
+	}
+
+	return int(want), nil
+}
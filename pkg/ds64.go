@@ -0,0 +1,30 @@
+package pkg
+
+import (
+	"github.com/gravestench/wav/pkg/riff"
+)
+
+const (
+	ds64MinSize     = riff.DS64MinSize
+	sizePlaceholder = riff.SizePlaceholder
+)
+
+// ds64 holds the contents of the ds64 chunk of an RF64 or BW64 file, which carries
+// the sizes that don't fit the 32-bit size fields
+type ds64 = riff.DS64
+
+// isRF64 reports whether the code identifies an RF64 or BW64 file
+func isRF64(id FourCC) bool {
+	return riff.IsRF64(id)
+}
+
+// parseDS64 parses the body of a ds64 chunk
+func parseDS64(body []byte) (*ds64, error) {
+	return riff.ParseDS64(body)
+}
+
+// readChunkSize returns the size in the header of a chunk, resolved through the ds64
+// chunk of an RF64 file
+func readChunkSize(header []byte, table *ds64) int64 {
+	return riff.ReadChunkSize(header, table)
+}
@@ -0,0 +1,625 @@
+package pkg
+
+import (
+	"errors"
+	"hash"
+	"io"
+	"math"
+)
+
+// Errors returned by the Encoder
+var (
+	ErrEncoderClosed   = errors.New("encoder is closed")
+	ErrBlockAlign      = errors.New("block align doesn't match the format")
+	ErrMisalignedFrame = errors.New("data doesn't end on a whole sample frame")
+	ErrNotPCM          = errors.New("samples can only be written to PCM formats")
+)
+
+// Encoder writes 16-bit samples into a RIFF/WAVE file. The data chunk is collected
+// in memory and the complete file is written out by Close.
+type Encoder struct {
+	w          io.Writer
+	format     Format
+	data       *streamWriter
+	pending    []int16
+	markers    []Marker
+	tags       map[string]string
+	instrument *Instrument
+	acid       *Acid
+	cart       *Cart
+	bext       *Bext
+	peaks      *PeakEnvelope
+	adm        *ADM
+	dolby      *DolbyMetadata
+	junkSize   int
+	rf64       bool
+	// peakBlockSize is the block size of the peak envelope generated by Close, or 0
+	peakBlockSize int
+	// leadingChunks hold raw chunks written before the fmt chunk, and headerChunks and
+	// trailerChunks those written before and after the data chunk
+	leadingChunks []rawChunk
+	headerChunks  []rawChunk
+	trailerChunks []rawChunk
+	stats         statsRecorder
+	closed        bool
+}
+
+// rawChunk is a chunk written verbatim
+type rawChunk struct {
+	id   FourCC
+	body []byte
+}
+
+// CreateEncoder creates an Encoder writing PCM with the given layout to w.
+// Samples are converted to bitsPerSample, which may be 8, 16, 24 or 32.
+func CreateEncoder(w io.Writer, sampleRate, bitsPerSample, channels int) (*Encoder, error) {
+	return CreateFormatEncoder(w, CreatePCMFormat(sampleRate, bitsPerSample, channels))
+}
+
+// CreateFormatEncoder creates an Encoder writing audio in the given format to w.
+// Formats other than PCM and IEEE float only accept pre-encoded blocks via Write.
+func CreateFormatEncoder(w io.Writer, format Format) (*Encoder, error) {
+	if format.Channels == 0 || format.BlockAlign == 0 {
+		return nil, ErrBlockAlign
+	}
+
+	if format.validate() == nil && int(format.BlockAlign) != format.blockSize() {
+		return nil, ErrBlockAlign
+	}
+
+	result := &Encoder{
+		w:      w,
+		format: format,
+		data:   CreateStreamWriter(),
+	}
+
+	return result, nil
+}
+
+// Format returns the format of the encoded audio
+func (v *Encoder) Format() Format {
+	return v.format
+}
+
+// Reset discards any pending output and prepares the encoder to write a new file
+// with the same format to w, reusing its internal buffers
+func (v *Encoder) Reset(w io.Writer) {
+	v.w = w
+	v.data.Reset()
+	v.data.SetHash(nil)
+	v.pending = v.pending[:0]
+	v.markers = nil
+	v.tags = nil
+	v.instrument = nil
+	v.acid = nil
+	v.cart = nil
+	v.bext = nil
+	v.peaks = nil
+	v.peakBlockSize = 0
+	v.adm = nil
+	v.dolby = nil
+	v.junkSize = 0
+	v.rf64 = false
+	v.leadingChunks = nil
+	v.headerChunks = nil
+	v.trailerChunks = nil
+	v.closed = false
+}
+
+// AddLeadingChunk adds a chunk written verbatim ahead of the fmt chunk, where some
+// files keep a bext or LIST chunk
+func (v *Encoder) AddLeadingChunk(id FourCC, body []byte) {
+	v.leadingChunks = append(v.leadingChunks, rawChunk{id: id, body: body})
+}
+
+// AddHeaderChunk adds a chunk written verbatim between the fmt and data chunks, such
+// as an iXML chunk
+func (v *Encoder) AddHeaderChunk(id FourCC, body []byte) {
+	v.headerChunks = append(v.headerChunks, rawChunk{id: id, body: body})
+}
+
+// AddChunk adds a chunk written verbatim after the data chunk and the metadata chunks
+func (v *Encoder) AddChunk(id FourCC, body []byte) {
+	v.trailerChunks = append(v.trailerChunks, rawChunk{id: id, body: body})
+}
+
+// SetInstrument sets the inst chunk written after the data chunk, or removes it if nil
+func (v *Encoder) SetInstrument(instrument *Instrument) {
+	v.instrument = instrument
+}
+
+// SetAcid sets the acid chunk written after the data chunk, or removes it if nil
+func (v *Encoder) SetAcid(acid *Acid) {
+	v.acid = acid
+}
+
+// SetCart sets the cart chunk written before the data chunk, or removes it if nil
+func (v *Encoder) SetCart(cart *Cart) {
+	v.cart = cart
+}
+
+// SetBext sets the bext chunk written before the data chunk, or removes it if nil
+func (v *Encoder) SetBext(bext *Bext) {
+	v.bext = bext
+}
+
+// SetTags sets the text tags of the LIST INFO chunk written before the data chunk,
+// keyed by ID such as INAM or IART, or removes it if tags is empty
+func (v *Encoder) SetTags(tags map[string]string) {
+	v.tags = make(map[string]string, len(tags))
+	for id, text := range tags {
+		v.tags[id] = text
+	}
+}
+
+// SetMetadata sets the INFO tags, the bext chunk and the markers together, replacing
+// any set before. A nil metadata removes them.
+func (v *Encoder) SetMetadata(metadata *Metadata) {
+	if metadata == nil {
+		metadata = &Metadata{}
+	}
+
+	metadata = metadata.Clone()
+
+	v.SetTags(metadata.Tags)
+	v.SetBext(metadata.Bext)
+	v.SetMarkers(metadata.Markers)
+}
+
+// SetStartTimecode sets the TimeReference of the bext chunk to the timecode of the
+// first sample, adding an empty bext chunk if none was set
+func (v *Encoder) SetStartTimecode(timecode Timecode) {
+	if v.bext == nil {
+		v.bext = &Bext{}
+	}
+
+	v.bext.SetStartTimecode(timecode, int(v.format.SampleRate))
+}
+
+// SetPeakEnvelope sets the levl chunk written before the data chunk, or removes it if nil
+func (v *Encoder) SetPeakEnvelope(peaks *PeakEnvelope) {
+	v.peaks = peaks
+	v.peakBlockSize = 0
+}
+
+// GeneratePeakEnvelope makes Close compute a levl chunk from the samples written, with
+// peaks over blocks of blockSize frames (256 if blockSize is 0). It has no effect on
+// formats other than PCM and IEEE float.
+func (v *Encoder) GeneratePeakEnvelope(blockSize int) {
+	if blockSize <= 0 {
+		blockSize = levlDefaultBlockSize
+	}
+
+	v.peaks = nil
+	v.peakBlockSize = blockSize
+}
+
+// SetADM sets the chna chunk written before the data chunk and the axml chunk written
+// after it, or removes them if nil. Either is left out when it has no contents.
+func (v *Encoder) SetADM(adm *ADM) {
+	v.adm = adm
+}
+
+// SetDolbyMetadata sets the dbmd chunk written after the data chunk, or removes it if nil
+func (v *Encoder) SetDolbyMetadata(dolby *DolbyMetadata) {
+	v.dolby = dolby
+}
+
+// ReserveJunk reserves size bytes in a JUNK chunk ahead of the fmt chunk, or removes
+// it if size is 0. A JUNK chunk of 28 bytes leaves room to turn the file into RF64
+// in place by replacing it with a ds64 chunk.
+func (v *Encoder) ReserveJunk(size int) {
+	v.junkSize = size
+}
+
+// SetRF64 makes Close write an RF64 file with a ds64 chunk even when the audio would
+// fit a RIFF file. Files over 4 GiB are always written as RF64.
+func (v *Encoder) SetRF64(rf64 bool) {
+	v.rf64 = rf64
+}
+
+// SetDataHash makes the encoder add the bytes of the data chunk to h as they are
+// written, so that h holds their checksum, such as the MD5 BWF files may carry or a
+// CRC32 for an archive record, once Close returns. It must be set before the first
+// write; the pad byte following odd-sized data isn't included.
+func (v *Encoder) SetDataHash(h hash.Hash) {
+	v.data.SetHash(h)
+}
+
+// SetMarkers sets the cue points written after the data chunk, along with a LIST adtl
+// chunk holding their labels, notes and region texts, and a smpl chunk holding the
+// markers which are loops
+func (v *Encoder) SetMarkers(markers []Marker) {
+	v.markers = append([]Marker(nil), markers...)
+}
+
+// WriteSamples appends interleaved samples to the data chunk. Only whole frames are
+// written; the samples of a trailing partial frame are held until the next call.
+func (v *Encoder) WriteSamples(samples []int16) error {
+	if v.closed {
+		return ErrEncoderClosed
+	}
+
+	if v.format.validateSamples() != nil {
+		return ErrNotPCM
+	}
+
+	start := v.stats.start()
+	defer v.stats.stop(&v.stats.stats.EncodeTime, start)
+
+	v.stats.add(&v.stats.stats.BytesIn, len(samples)*bytesPerint16)
+
+	channels := int(v.format.Channels)
+
+	if len(v.pending) > 0 {
+		take := channels - len(v.pending)
+		if take > len(samples) {
+			take = len(samples)
+		}
+
+		v.pending = append(v.pending, samples[:take]...)
+		samples = samples[take:]
+
+		if len(v.pending) < channels {
+			return nil
+		}
+
+		for _, sample := range v.pending {
+			v.format.encodeSample(v.data, sample)
+		}
+
+		v.pending = v.pending[:0]
+	}
+
+	whole := len(samples) - len(samples)%channels
+	for _, sample := range samples[:whole] {
+		v.format.encodeSample(v.data, sample)
+	}
+
+	v.pending = append(v.pending, samples[whole:]...)
+
+	return nil
+}
+
+// Generate repeatedly calls fill with a buffer of whole sample frames and writes the
+// samples it produces, until it returns io.EOF, then closes the encoder. It suits
+// synthesis and capture sources which produce audio on demand.
+func (v *Encoder) Generate(fill SampleSourceFunc) error {
+	channels := int(v.format.Channels)
+
+	frames := copySamplesSize / channels
+	if frames == 0 {
+		frames = 1
+	}
+
+	buf := make([]int16, frames*channels)
+
+	for {
+		n, err := fill(buf)
+		if n > 0 {
+			if writeErr := v.WriteSamples(buf[:n]); writeErr != nil {
+				return writeErr
+			}
+		}
+
+		if errors.Is(err, io.EOF) {
+			return v.Close()
+		}
+
+		if err != nil {
+			return err
+		}
+	}
+}
+
+// Write appends already encoded audio to the data chunk, which is how compressed
+// formats are written. The final block is padded to BlockAlign on Close.
+func (v *Encoder) Write(p []byte) (int, error) {
+	if v.closed {
+		return 0, ErrEncoderClosed
+	}
+
+	if len(v.pending) > 0 {
+		return 0, ErrMisalignedFrame
+	}
+
+	v.data.PushBytes(p...)
+	v.stats.add(&v.stats.stats.BytesIn, len(p))
+
+	return len(p), nil
+}
+
+// ReadFrom implements io.ReaderFrom, appending everything read from r to the data
+// chunk like Write, without copying it through an intermediate buffer
+func (v *Encoder) ReadFrom(r io.Reader) (int64, error) {
+	if v.closed {
+		return 0, ErrEncoderClosed
+	}
+
+	if len(v.pending) > 0 {
+		return 0, ErrMisalignedFrame
+	}
+
+	n, err := v.data.ReadFrom(r)
+	v.stats.add(&v.stats.stats.BytesIn, int(n))
+
+	return n, err
+}
+
+// alignData pads the data chunk to a whole number of blocks. Uncompressed data must
+// already be aligned, as padding would insert a partial frame of silence.
+func (v *Encoder) alignData() error {
+	if len(v.pending) > 0 {
+		return ErrMisalignedFrame
+	}
+
+	partial := v.data.Len() % int(v.format.BlockAlign)
+	if partial == 0 {
+		return nil
+	}
+
+	if v.format.validateSamples() == nil {
+		return ErrMisalignedFrame
+	}
+
+	v.data.PushBytes(make([]byte, int(v.format.BlockAlign)-partial)...)
+
+	return nil
+}
+
+// Close writes the RIFF header, fmt chunk and data chunk to the underlying writer.
+// Nothing is written if the data doesn't end on a block boundary, and the encoder
+// stays open so the rest of the frame can be written. Otherwise it is closed before
+// writing starts, so a failed write isn't repeated on top of the partial output.
+func (v *Encoder) Close() error {
+	if v.closed {
+		return ErrEncoderClosed
+	}
+
+	start := v.stats.start()
+
+	if err := v.alignData(); err != nil {
+		return err
+	}
+
+	v.closed = true
+
+	data := v.data.GetBytes()
+	v.stats.addBlocks(len(data), int(v.format.BlockAlign))
+
+	trailer := CreateStreamWriter()
+
+	if len(v.markers) > 0 {
+		pushChunk(trailer, ChunkCue, marshalCue(v.markers))
+
+		if adtl := marshalAdtl(v.markers); adtl != nil {
+			pushChunk(trailer, ChunkLIST, adtl)
+		}
+
+		unityNote := uint8(smplUnityNote)
+		if v.instrument != nil {
+			unityNote = v.instrument.UnshiftedNote
+		}
+
+		if smpl := marshalSmpl(v.markers, v.format.SampleRate, unityNote); smpl != nil {
+			pushChunk(trailer, ChunkSmpl, smpl)
+		}
+	}
+
+	if v.instrument != nil {
+		pushChunk(trailer, ChunkInst, v.instrument.marshal())
+	}
+
+	if v.acid != nil {
+		pushChunk(trailer, ChunkAcid, v.acid.marshal())
+	}
+
+	if v.adm != nil && v.adm.XML != "" {
+		pushChunk(trailer, ChunkAXML, []byte(v.adm.XML))
+	}
+
+	if v.dolby != nil {
+		pushChunk(trailer, ChunkDbmd, v.dolby.marshal())
+	}
+
+	for _, chunk := range v.trailerChunks {
+		pushChunk(trailer, chunk.id, chunk.body)
+	}
+
+	fmtBody := v.format.marshalFmt()
+
+	if v.peakBlockSize > 0 && v.format.validateSamples() == nil {
+		v.peaks = ComputePeakEnvelope(v.decodeData(data), int(v.format.Channels), v.peakBlockSize)
+	}
+
+	headerChunks := CreateStreamWriter()
+	if fact := v.factChunk(len(data)); fact != nil {
+		pushChunk(headerChunks, ChunkFact, fact)
+	}
+
+	if v.bext != nil {
+		pushChunk(headerChunks, ChunkBext, v.bext.marshal())
+	}
+
+	if v.cart != nil {
+		pushChunk(headerChunks, ChunkCart, v.cart.marshal())
+	}
+
+	if v.peaks != nil {
+		pushChunk(headerChunks, ChunkLevl, v.peaks.marshal())
+	}
+
+	if v.adm != nil && len(v.adm.Entries) > 0 {
+		pushChunk(headerChunks, ChunkChna, v.adm.marshalChna())
+	}
+
+	if info := marshalInfo(v.tags); info != nil {
+		pushChunk(headerChunks, ChunkLIST, info)
+	}
+
+	for _, chunk := range v.headerChunks {
+		pushChunk(headerChunks, chunk.id, chunk.body)
+	}
+
+	leadingChunks := CreateStreamWriter()
+	for _, chunk := range v.leadingChunks {
+		pushChunk(leadingChunks, chunk.id, chunk.body)
+	}
+
+	// the chunks other than the data chunk are always small enough for 32-bit sizes
+	chunksSize := int64(bytesPerint32 + leadingChunks.Len() + paddedChunkSize(len(fmtBody)) +
+		headerChunks.Len() + paddedChunkSize(len(data)) + trailer.Len())
+
+	junk := CreateStreamWriter()
+	if v.junkSize > 0 {
+		pushChunk(junk, ChunkJUNK, make([]byte, v.junkSize))
+	}
+
+	header := CreateStreamWriter()
+
+	if v.rf64 || chunksSize+int64(junk.Len()) > math.MaxUint32 {
+		// the ds64 chunk takes the place of the reserved space
+		sizes := &ds64{
+			RIFFSize:    chunksSize + chunkHeaderSize + ds64MinSize,
+			DataSize:    int64(len(data)),
+			SampleCount: v.format.dataFrames(int64(len(data))),
+		}
+
+		header.PushFourCC(ChunkRF64)
+		header.PushUint32(sizePlaceholder)
+		header.PushFourCC(ChunkWAVE)
+		pushChunk(header, ChunkDs64, sizes.Bytes())
+		header.PushBytes(leadingChunks.GetBytes()...)
+		pushChunk(header, ChunkFmt, fmtBody)
+		header.PushBytes(headerChunks.GetBytes()...)
+		header.PushFourCC(ChunkData)
+		header.PushUint32(sizePlaceholder)
+	} else {
+		header.PushFourCC(ChunkRIFF)
+		header.PushUint32(uint32(chunksSize + int64(junk.Len())))
+		header.PushFourCC(ChunkWAVE)
+		header.PushBytes(junk.GetBytes()...)
+		header.PushBytes(leadingChunks.GetBytes()...)
+		pushChunk(header, ChunkFmt, fmtBody)
+		header.PushBytes(headerChunks.GetBytes()...)
+		header.PushFourCC(ChunkData)
+		header.PushUint32(uint32(len(data)))
+	}
+
+	v.stats.stop(&v.stats.stats.EncodeTime, start)
+
+	if err := v.write(header.GetBytes()); err != nil {
+		return err
+	}
+
+	if err := v.write(data); err != nil {
+		return err
+	}
+
+	if len(data)%2 == 1 {
+		if err := v.write([]byte{0}); err != nil {
+			return err
+		}
+	}
+
+	return v.write(trailer.GetBytes())
+}
+
+// factChunk returns the body of the fact chunk holding the number of sample frames,
+// which formats other than integer PCM require. It is nil for integer PCM, for
+// compressed formats whose frame count isn't known, or if a fact chunk was added
+// with AddHeaderChunk.
+func (v *Encoder) factChunk(dataSize int) []byte {
+	if v.format.effectiveTag() == FormatPCM || v.format.validate() != nil {
+		return nil
+	}
+
+	for _, chunk := range v.headerChunks {
+		if chunk.id == ChunkFact {
+			return nil
+		}
+	}
+
+	frames := v.format.dataFrames(int64(dataSize))
+	if frames > math.MaxUint32 {
+		// the ds64 chunk holds the sample count
+		frames = sizePlaceholder
+	}
+
+	w := CreateStreamWriterSize(bytesPerint32)
+	w.PushUint32(uint32(frames))
+
+	return w.GetBytes()
+}
+
+// write writes p to the destination, counting it in the stats
+func (v *Encoder) write(p []byte) error {
+	start := v.stats.start()
+	n, err := v.w.Write(p)
+
+	v.stats.stop(&v.stats.stats.WriteTime, start)
+	v.stats.add(&v.stats.stats.BytesOut, n)
+
+	return err
+}
+
+// EnableStats makes the encoder count its work and time each stage, as returned by
+// Stats
+func (v *Encoder) EnableStats() {
+	v.stats.enabled = true
+}
+
+// Stats returns the work done by the encoder so far, which is only counted after
+// EnableStats. The counts accumulate across Reset.
+func (v *Encoder) Stats() Stats {
+	return v.stats.stats
+}
+
+// decodeData converts the encoded PCM data back to 16-bit samples
+func (v *Encoder) decodeData(data []byte) []int16 {
+	size := v.format.bytesPerSample()
+	result := make([]int16, len(data)/size)
+
+	for i := range result {
+		result[i] = v.format.decodeSample(data[i*size:])
+	}
+
+	return result
+}
+
+// encodeSample converts a 16-bit sample to the sample format and writes it to w
+//
+//nolint:gomnd // binary encode magic
+func (v Format) encodeSample(w *streamWriter, sample int16) {
+	switch v.effectiveTag() {
+	case FormatMuLaw:
+		w.PushBytes(linearToMuLaw(sample))
+		return
+	case FormatALaw:
+		w.PushBytes(linearToALaw(sample))
+		return
+	case FormatIEEEFloat:
+		f := int16ToFloat(sample)
+
+		if v.BitsPerSample == 64 {
+			w.PushUint64(math.Float64bits(f))
+			return
+		}
+
+		w.PushUint32(math.Float32bits(float32(f)))
+
+		return
+	}
+
+	switch v.BitsPerSample {
+	case 8:
+		w.PushBytes(byte(int(sample>>8) + pcm8Offset))
+	case 24:
+		w.PushInt24(int32(sample) << 8)
+	case 32:
+		w.PushUint16(0)
+		w.PushInt16(sample)
+	default:
+		w.PushInt16(sample)
+	}
+}
@@ -0,0 +1,114 @@
+package pkg_test
+
+import (
+	"bytes"
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/gravestench/wav/pkg"
+)
+
+// failingWriter accepts limit bytes, then fails every write
+type failingWriter struct {
+	limit   int
+	written int
+	calls   int
+}
+
+// errWrite is the error of failingWriter
+//
+//nolint:gochecknoglobals // sentinel error
+var errWrite = errors.New("write failed")
+
+func (v *failingWriter) Write(p []byte) (int, error) {
+	v.calls++
+
+	if v.written+len(p) > v.limit {
+		n := v.limit - v.written
+		v.written = v.limit
+
+		return n, errWrite
+	}
+
+	v.written += len(p)
+
+	return len(p), nil
+}
+
+// TestEncoderCloseMisaligned closes an encoder holding part of a frame, which must
+// write nothing and leave it open to write the rest of the frame
+func TestEncoderCloseMisaligned(t *testing.T) {
+	samples := sineSamples(2, time.Second/100)
+
+	var output bytes.Buffer
+
+	encoder, err := pkg.CreateFormatEncoder(&output, pkg.CreatePCMFormat(testRate, 16, 2))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err = encoder.WriteSamples(samples[:len(samples)-1]); err != nil {
+		t.Fatal(err)
+	}
+
+	if err = encoder.Close(); !errors.Is(err, pkg.ErrMisalignedFrame) {
+		t.Fatalf("closing with a partial frame returned %v, want %v", err, pkg.ErrMisalignedFrame)
+	}
+
+	if output.Len() != 0 {
+		t.Fatalf("wrote %d bytes of a misaligned file", output.Len())
+	}
+
+	if err = encoder.WriteSamples(samples[len(samples)-1:]); err != nil {
+		t.Fatal(err)
+	}
+
+	if err = encoder.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	decoder, err := pkg.CreateDecoder(output.Bytes())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	got, err := decoder.DecodeAll()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !reflect.DeepEqual(got, samples) {
+		t.Errorf("decoded %d samples differing from the %d written", len(got), len(samples))
+	}
+}
+
+// TestEncoderCloseWriteError fails writing the file part way, after which Close
+// must not write again
+func TestEncoderCloseWriteError(t *testing.T) {
+	w := &failingWriter{limit: 100}
+
+	encoder, err := pkg.CreateFormatEncoder(w, pkg.CreatePCMFormat(testRate, 16, 2))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err = encoder.WriteSamples(sineSamples(2, time.Second/100)); err != nil {
+		t.Fatal(err)
+	}
+
+	if err = encoder.Close(); !errors.Is(err, errWrite) {
+		t.Fatalf("closing returned %v, want %v", err, errWrite)
+	}
+
+	calls := w.calls
+
+	if err = encoder.Close(); !errors.Is(err, pkg.ErrEncoderClosed) {
+		t.Errorf("closing again returned %v, want %v", err, pkg.ErrEncoderClosed)
+	}
+
+	if w.calls != calls {
+		t.Errorf("closing again wrote %d more times", w.calls-calls)
+	}
+}
@@ -0,0 +1,40 @@
+package pkg
+
+import (
+	"math"
+	"math/cmplx"
+)
+
+// fft transforms x in place with an iterative radix-2 FFT. len(x) must be a power of two.
+func fft(x []complex128) {
+	n := len(x)
+
+	for i, j := 1, 0; i < n; i++ {
+		bit := n >> 1
+		for ; j&bit != 0; bit >>= 1 {
+			j ^= bit
+		}
+
+		j ^= bit
+
+		if i < j {
+			x[i], x[j] = x[j], x[i]
+		}
+	}
+
+	for size := 2; size <= n; size <<= 1 {
+		step := cmplx.Exp(complex(0, -2*math.Pi/float64(size)))
+
+		for start := 0; start < n; start += size {
+			w := complex(1, 0)
+
+			for k := 0; k < size/2; k++ {
+				even := x[start+k]
+				odd := x[start+k+size/2] * w
+				x[start+k] = even + odd
+				x[start+k+size/2] = even - odd
+				w *= step
+			}
+		}
+	}
+}
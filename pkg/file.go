@@ -0,0 +1,106 @@
+package pkg
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+)
+
+// ErrUnsupportedFile is returned by DecodeFile for audio files of a kind it can't decode
+var ErrUnsupportedFile = errors.New("unsupported audio file")
+
+// WithMmap makes OpenFile memory-map the file where the platform supports it, so the
+// data chunk is decoded straight from the mapping instead of being read into memory
+func WithMmap() DecoderOption {
+	return func(o *decoderOptions) {
+		o.mmap = true
+	}
+}
+
+// OpenFile opens a WAV file for decoding. The returned Decoder must be closed.
+func OpenFile(path string, opts ...DecoderOption) (*Decoder, error) {
+	options := applyDecoderOptions(opts)
+
+	f, err := os.Open(path) //nolint:gosec // opening caller supplied paths is the point
+	if err != nil {
+		return nil, err
+	}
+
+	defer f.Close() //nolint:errcheck // read only
+
+	var data []byte
+
+	release := func() error { return nil }
+
+	if options.mmap {
+		data, release, err = mmapFile(f)
+	} else {
+		data, err = io.ReadAll(f)
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	decoder, err := CreateDecoder(data, opts...)
+	if err != nil {
+		_ = release()
+		return nil, err
+	}
+
+	decoder.release = release
+
+	return decoder, nil
+}
+
+// DecodeFile reads and decodes all of the audio of a WAV file, or of a compressed
+// MPQ sector saved to a file. Files of other kinds, such as AIFF, are identified
+// by Sniff and rejected with ErrUnsupportedFile before being read.
+func DecodeFile(path string, opts ...Option) (*Buffer, error) {
+	f, err := os.Open(path) //nolint:gosec // opening caller supplied paths is the point
+	if err != nil {
+		return nil, err
+	}
+
+	defer f.Close() //nolint:errcheck // read only
+
+	input := bufio.NewReader(f)
+
+	// a short file gives a short prefix, which Sniff handles
+	prefix, _ := input.Peek(SniffSize)
+
+	switch kind := Sniff(prefix); kind {
+	case KindRIFX, KindAIFF, KindAU, KindWave64:
+		return nil, fmt.Errorf("%w: %s is %s", ErrUnsupportedFile, path, kind)
+	}
+
+	return DecodeBuffer(input, opts...)
+}
+
+// EncodeFile writes the buffer to a PCM WAV file like EncodeBuffer, creating or
+// truncating it. The file is removed if encoding fails.
+func EncodeFile(path string, buffer *Buffer, opts ...Option) error {
+	f, err := os.Create(path) //nolint:gosec // creating caller supplied paths is the point
+	if err != nil {
+		return err
+	}
+
+	output := bufio.NewWriter(f)
+
+	err = EncodeBuffer(output, buffer, opts...)
+	if err == nil {
+		err = output.Flush()
+	}
+
+	if closeErr := f.Close(); err == nil {
+		err = closeErr
+	}
+
+	if err != nil {
+		_ = os.Remove(path)
+	}
+
+	return err
+}
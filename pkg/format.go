@@ -0,0 +1,358 @@
+package pkg
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"math"
+
+	"github.com/gravestench/wav/pkg/adpcm"
+)
+
+// Wave format tags
+const (
+	FormatPCM           = 0x0001
+	FormatADPCM         = 0x0002
+	FormatIEEEFloat     = 0x0003
+	FormatALaw          = 0x0006
+	FormatMuLaw         = 0x0007
+	FormatIMAADPCM      = 0x0011
+	FormatGSM610        = 0x0031
+	FormatMPEG          = 0x0050
+	FormatMPEGLayer3    = 0x0055
+	FormatDolbyAC3SPDIF = 0x0092
+	FormatWMAv2         = 0x0161
+	FormatWMAPro        = 0x0162
+	FormatWMALossless   = 0x0163
+	FormatAAC           = 0x00FF
+	FormatFLAC          = 0xF1AC
+	FormatExtensible    = 0xFFFE
+)
+
+const (
+	fmtChunkMinSize         = 16
+	adpcmBitsPerSample      = 4
+	msCoefficientCountAt    = 2
+	msCoefficientsAt        = 4
+	extensibleValidBitsAt   = 0
+	extensibleChannelMaskAt = 2
+	extensibleSubFormatAt   = 6
+	extensibleSize          = 22
+)
+
+// extensibleGUIDSuffix follows the format tag in the sub format GUID of WAVE_FORMAT_EXTENSIBLE
+//
+//nolint:gochecknoglobals // GUID bytes
+var extensibleGUIDSuffix = []byte{0x00, 0x00, 0x00, 0x00, 0x10, 0x00, 0x80, 0x00, 0x00, 0xaa, 0x00, 0x38, 0x9b, 0x71}
+
+// Errors returned while parsing a fmt chunk
+var (
+	ErrShortFormat       = errors.New("fmt chunk is too short")
+	ErrUnsupportedFormat = errors.New("unsupported wave format")
+)
+
+// Format describes the audio in a fmt chunk, carrying every WAVEFORMATEX field
+type Format struct {
+	FormatTag      uint16
+	Channels       uint16
+	SampleRate     uint32
+	AvgBytesPerSec uint32
+	BlockAlign     uint16
+	BitsPerSample  uint16
+	// Extension holds the cbSize bytes following the WAVEFORMAT fields
+	Extension []byte
+}
+
+// CreatePCMFormat creates the Format of uncompressed integer PCM with the given layout
+func CreatePCMFormat(sampleRate, bitsPerSample, channels int) Format {
+	result := Format{
+		FormatTag:     FormatPCM,
+		Channels:      uint16(channels),
+		SampleRate:    uint32(sampleRate),
+		BitsPerSample: uint16(bitsPerSample),
+	}
+
+	result.BlockAlign = uint16(result.blockSize())
+	result.AvgBytesPerSec = result.SampleRate * uint32(result.BlockAlign)
+
+	return result
+}
+
+// CreateExtensibleFormat creates the WAVE_FORMAT_EXTENSIBLE Format of integer PCM with
+// the given speaker layout, which also sets the channel count
+func CreateExtensibleFormat(sampleRate, bitsPerSample int, layout Layout) Format {
+	result := CreatePCMFormat(sampleRate, bitsPerSample, len(layout))
+	result.FormatTag = FormatExtensible
+
+	w := CreateStreamWriterSize(extensibleSize)
+	w.PushUint16(uint16(bitsPerSample))
+	w.PushUint32(layout.Mask())
+	w.PushUint16(FormatPCM)
+	w.PushBytes(extensibleGUIDSuffix...)
+
+	result.Extension = w.GetBytes()
+
+	return result
+}
+
+// withCodec returns the format with its format tag, or the sub format of a
+// WAVE_FORMAT_EXTENSIBLE format, replaced by tag
+func (v Format) withCodec(tag uint16) Format {
+	if v.FormatTag != FormatExtensible || len(v.Extension) < extensibleSubFormatAt+bytesPerint16 {
+		v.FormatTag = tag
+		return v
+	}
+
+	extension := make([]byte, len(v.Extension))
+	copy(extension, v.Extension)
+	binary.LittleEndian.PutUint16(extension[extensibleSubFormatAt:], tag)
+	v.Extension = extension
+
+	return v
+}
+
+// ChannelMask returns the dwChannelMask of a WAVE_FORMAT_EXTENSIBLE format, or 0 for
+// other formats
+func (v Format) ChannelMask() uint32 {
+	if v.FormatTag != FormatExtensible || len(v.Extension) < extensibleChannelMaskAt+bytesPerint32 {
+		return 0
+	}
+
+	return binary.LittleEndian.Uint32(v.Extension[extensibleChannelMaskAt:])
+}
+
+// Layout returns the speaker of each channel. WAVE_FORMAT_EXTENSIBLE formats take them
+// from the channel mask, with channels beyond it left unassigned as 0. Other formats
+// use the standard layout for their channel count, or the first speaker positions.
+func (v Format) Layout() Layout {
+	channels := int(v.Channels)
+
+	if mask := v.ChannelMask(); mask != 0 {
+		result := make(Layout, channels)
+		copy(result, LayoutFromMask(mask))
+
+		return result
+	}
+
+	for _, layout := range []Layout{LayoutMono, LayoutStereo, LayoutQuad, LayoutSurround51, LayoutSurround71} {
+		if len(layout) == channels {
+			return layout
+		}
+	}
+
+	result := make(Layout, channels)
+	copy(result, LayoutFromMask(math.MaxUint32))
+
+	return result
+}
+
+// parseWaveFormat parses the body of a fmt chunk
+func parseWaveFormat(body []byte) (Format, error) {
+	var result Format
+
+	if len(body) < fmtChunkMinSize {
+		return result, ErrShortFormat
+	}
+
+	r := CreateStreamReader(body)
+
+	result.FormatTag, _ = r.ReadUInt16()
+	result.Channels, _ = r.ReadUInt16()
+	result.SampleRate, _ = r.ReadUInt32()
+	result.AvgBytesPerSec, _ = r.ReadUInt32()
+	result.BlockAlign, _ = r.ReadUInt16()
+	result.BitsPerSample, _ = r.ReadUInt16()
+
+	cbSize, err := r.ReadUInt16()
+	if err != nil {
+		return result, nil
+	}
+
+	// keep whatever extension bytes are present when cbSize overstates them
+	if remaining := int(r.Size() - r.Position()); int(cbSize) > remaining {
+		cbSize = uint16(remaining)
+	}
+
+	result.Extension, _ = r.ReadBytesCopy(int(cbSize))
+
+	return result, nil
+}
+
+// marshalFmt returns the body of the fmt chunk describing the format. The cbSize field
+// is written for every format other than plain PCM, followed by the extension bytes.
+func (v Format) marshalFmt() []byte {
+	w := CreateStreamWriter()
+
+	w.PushUint16(v.FormatTag)
+	w.PushUint16(v.Channels)
+	w.PushUint32(v.SampleRate)
+	w.PushUint32(v.AvgBytesPerSec)
+	w.PushUint16(v.BlockAlign)
+	w.PushUint16(v.BitsPerSample)
+
+	if v.FormatTag != FormatPCM || len(v.Extension) > 0 {
+		w.PushUint16(uint16(len(v.Extension)))
+		w.PushBytes(v.Extension...)
+	}
+
+	return w.GetBytes()
+}
+
+// MarshalBinary implements encoding.BinaryMarshaler, returning the body of a fmt chunk
+func (v Format) MarshalBinary() ([]byte, error) {
+	return v.marshalFmt(), nil
+}
+
+// UnmarshalBinary implements encoding.BinaryUnmarshaler, parsing the body of a fmt chunk
+func (v *Format) UnmarshalBinary(data []byte) error {
+	parsed, err := parseWaveFormat(data)
+	if err != nil {
+		return err
+	}
+
+	*v = parsed
+
+	return nil
+}
+
+// effectiveTag returns the format tag, resolving WAVE_FORMAT_EXTENSIBLE to its sub format
+func (v Format) effectiveTag() uint16 {
+	if v.FormatTag != FormatExtensible || len(v.Extension) < extensibleSubFormatAt+bytesPerint16 {
+		return v.FormatTag
+	}
+
+	return uint16(v.Extension[extensibleSubFormatAt]) | uint16(v.Extension[extensibleSubFormatAt+1])<<8
+}
+
+// bytesPerSample returns the size in bytes of a single sample of one channel
+func (v Format) bytesPerSample() int {
+	return (int(v.BitsPerSample) + bitsPerByte - 1) / bitsPerByte
+}
+
+// validate checks that the format can be decoded into 16-bit samples
+func (v Format) validate() error {
+	if v.Channels == 0 {
+		return ErrUnsupportedFormat
+	}
+
+	switch v.effectiveTag() {
+	case FormatPCM:
+		switch v.BitsPerSample {
+		case 8, 16, 24, 32: //nolint:gomnd // supported bit depths
+			return nil
+		}
+	case FormatIEEEFloat:
+		switch v.BitsPerSample {
+		case 32, 64: //nolint:gomnd // supported bit depths
+			return nil
+		}
+	case FormatMuLaw, FormatALaw:
+		if v.BitsPerSample == bitsPerByte {
+			return nil
+		}
+	case FormatIMAADPCM:
+		// the codes of each channel come in words of 4 bytes
+		codes := int(v.BlockAlign) - adpcm.IMAHeaderSize*int(v.Channels)
+		if v.BitsPerSample == adpcmBitsPerSample && codes >= 0 && codes%(bytesPerint32*int(v.Channels)) == 0 {
+			return nil
+		}
+	case FormatADPCM:
+		if v.BitsPerSample == adpcmBitsPerSample && v.framesPerBlock() > 0 && v.hasCoefficients() {
+			return nil
+		}
+	}
+
+	return fmt.Errorf("%w: %s", ErrUnsupportedFormat, v)
+}
+
+// validateSamples checks that the format can be decoded and stores every sample on
+// its own, so samples can be encoded to it or decoded one by one
+func (v Format) validateSamples() error {
+	if err := v.validate(); err != nil {
+		return err
+	}
+
+	if v.isBlockCodec() {
+		return fmt.Errorf("%w: %s", ErrUnsupportedFormat, v)
+	}
+
+	return nil
+}
+
+// isBlockCodec reports whether the format compresses samples into blocks of
+// nBlockAlign bytes, which can only be decoded whole
+func (v Format) isBlockCodec() bool {
+	tag := v.effectiveTag()
+
+	return tag == FormatIMAADPCM || tag == FormatADPCM
+}
+
+// framesPerBlock returns the number of sample frames stored in one block of nBlockAlign bytes
+func (v Format) framesPerBlock() int {
+	if v.isBlockCodec() {
+		return v.blockFrames(int(v.BlockAlign))
+	}
+
+	return 1
+}
+
+// blockFrames returns the number of sample frames decoded from a block of size bytes,
+// which may be the short final block of a block codec
+func (v Format) blockFrames(size int) int {
+	switch v.effectiveTag() {
+	case FormatIMAADPCM:
+		return adpcm.IMAFramesPerBlock(size, int(v.Channels))
+	case FormatADPCM:
+		return adpcm.MSFramesPerBlock(size, int(v.Channels))
+	}
+
+	if blockSize := v.blockSize(); blockSize > 0 {
+		return size / blockSize
+	}
+
+	return 0
+}
+
+// blockSize returns the size in bytes of one independently decodable block
+func (v Format) blockSize() int {
+	if v.isBlockCodec() {
+		return int(v.BlockAlign)
+	}
+
+	return v.bytesPerSample() * int(v.Channels)
+}
+
+// msCoefficients returns the predictor coefficients of Microsoft ADPCM listed in the
+// fmt extension after the samples per block, or nil if it lists none and the
+// standard ones apply
+func (v Format) msCoefficients() [][2]int {
+	if v.effectiveTag() != FormatADPCM || len(v.Extension) < msCoefficientsAt {
+		return nil
+	}
+
+	count := int(binary.LittleEndian.Uint16(v.Extension[msCoefficientCountAt:]))
+	if count == 0 || len(v.Extension) < msCoefficientsAt+count*bytesPerint32 {
+		return nil
+	}
+
+	result := make([][2]int, count)
+	for i := range result {
+		pair := v.Extension[msCoefficientsAt+i*bytesPerint32:]
+		result[i][0] = int(int16(binary.LittleEndian.Uint16(pair)))
+		result[i][1] = int(int16(binary.LittleEndian.Uint16(pair[bytesPerint16:])))
+	}
+
+	return result
+}
+
+// hasCoefficients reports whether the fmt extension of Microsoft ADPCM is missing its
+// coefficient list, or holds all of it
+func (v Format) hasCoefficients() bool {
+	if len(v.Extension) < msCoefficientsAt {
+		return true
+	}
+
+	count := int(binary.LittleEndian.Uint16(v.Extension[msCoefficientCountAt:]))
+
+	return len(v.Extension) >= msCoefficientsAt+count*bytesPerint32
+}
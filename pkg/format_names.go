@@ -0,0 +1,67 @@
+package pkg
+
+import (
+	"fmt"
+)
+
+// FormatNames maps wave format tags to the names of their codecs
+//
+//nolint:gochecknoglobals // name table
+var FormatNames = map[uint16]string{
+	0x0000:              "Unknown",
+	FormatPCM:           "PCM",
+	FormatADPCM:         "Microsoft ADPCM",
+	FormatIEEEFloat:     "IEEE float",
+	0x0005:              "IBM CVSD",
+	FormatALaw:          "A-law",
+	FormatMuLaw:         "µ-law",
+	0x0010:              "OKI ADPCM",
+	FormatIMAADPCM:      "IMA ADPCM",
+	0x0014:              "G.723 ADPCM",
+	0x0020:              "Yamaha ADPCM",
+	0x0022:              "DSP Group TrueSpeech",
+	FormatGSM610:        "GSM 6.10",
+	0x0040:              "G.721 ADPCM",
+	0x0042:              "MSN Audio (G.723.1)",
+	FormatMPEG:          "MPEG",
+	FormatMPEGLayer3:    "MPEG Layer-3",
+	0x0064:              "G.726 ADPCM",
+	0x0065:              "G.722 ADPCM",
+	FormatDolbyAC3SPDIF: "Dolby AC-3 S/PDIF",
+	FormatAAC:           "AAC",
+	0x0160:              "Windows Media Audio v1",
+	FormatWMAv2:         "Windows Media Audio v2",
+	FormatWMAPro:        "Windows Media Audio Professional",
+	FormatWMALossless:   "Windows Media Audio Lossless",
+	0x0164:              "Windows Media Audio S/PDIF",
+	0x1600:              "MPEG-4 AAC (ADTS)",
+	0x2000:              "Dolby AC-3",
+	0x2001:              "DTS",
+	0x674F:              "Ogg Vorbis mode 1",
+	0x6750:              "Ogg Vorbis mode 2",
+	0x6751:              "Ogg Vorbis mode 3",
+	0x676F:              "Ogg Vorbis mode 1+",
+	0x6770:              "Ogg Vorbis mode 2+",
+	0x6771:              "Ogg Vorbis mode 3+",
+	FormatFLAC:          "FLAC",
+	FormatExtensible:    "Extensible",
+}
+
+// FormatName returns the name of the codec of a wave format tag
+func FormatName(tag uint16) string {
+	if name, ok := FormatNames[tag]; ok {
+		return name
+	}
+
+	return fmt.Sprintf("format 0x%04x", tag)
+}
+
+// CodecName returns the name of the codec, resolving WAVE_FORMAT_EXTENSIBLE to its sub format
+func (v Format) CodecName() string {
+	return FormatName(v.effectiveTag())
+}
+
+// String describes the format, such as "PCM, 16-bit, 2 channels, 44100 Hz"
+func (v Format) String() string {
+	return fmt.Sprintf("%s, %d-bit, %d channels, %d Hz", v.CodecName(), v.BitsPerSample, v.Channels, v.SampleRate)
+}
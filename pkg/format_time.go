@@ -0,0 +1,80 @@
+package pkg
+
+import (
+	"time"
+)
+
+// FramesToDuration returns the playing time of the given number of sample frames
+func (v Format) FramesToDuration(frames int64) time.Duration {
+	return framesToDuration(frames, int64(v.SampleRate))
+}
+
+// DurationToFrames returns the number of whole sample frames played in d
+func (v Format) DurationToFrames(d time.Duration) int64 {
+	return durationToFrames(d, int64(v.SampleRate))
+}
+
+// FrameOffset returns the byte offset in the data chunk of the block holding frame:
+// its block index times the block size. For uncompressed formats that is the frame
+// itself; ADPCM frames can only be reached by decoding from the start of their block
+// of nBlockAlign bytes.
+func (v Format) FrameOffset(frame int64) int64 {
+	framesPerBlock := int64(v.framesPerBlock())
+	if framesPerBlock == 0 {
+		return 0
+	}
+
+	return frame / framesPerBlock * int64(v.blockSize())
+}
+
+// OffsetFrames returns the number of sample frames held by the whole blocks in the
+// first offset bytes of the data chunk, the inverse of FrameOffset at block starts
+func (v Format) OffsetFrames(offset int64) int64 {
+	blockSize := int64(v.blockSize())
+	if blockSize == 0 {
+		// undecodable formats, such as compressed ones read with WithRawData
+		return 0
+	}
+
+	return offset / blockSize * int64(v.framesPerBlock())
+}
+
+// dataFrames returns the number of sample frames in a data chunk of size bytes,
+// including those of a short final block of a block codec
+func (v Format) dataFrames(size int64) int64 {
+	frames := v.OffsetFrames(size)
+
+	if blockSize := int64(v.blockSize()); v.isBlockCodec() && blockSize > 0 {
+		frames += int64(v.blockFrames(int(size % blockSize)))
+	}
+
+	return frames
+}
+
+// DurationToOffset returns the byte offset in the data chunk of the block playing at d
+func (v Format) DurationToOffset(d time.Duration) int64 {
+	return v.FrameOffset(v.DurationToFrames(d))
+}
+
+// OffsetToDuration returns the playing time of the whole blocks in the first offset
+// bytes of the data chunk
+func (v Format) OffsetToDuration(offset int64) time.Duration {
+	return v.FramesToDuration(v.OffsetFrames(offset))
+}
+
+// framesToDuration returns the playing time of frames at the given sample rate
+func framesToDuration(frames, rate int64) time.Duration {
+	if rate == 0 {
+		return 0
+	}
+
+	// split into whole seconds so long durations don't overflow
+	return time.Duration(frames/rate)*time.Second + time.Duration(frames%rate*int64(time.Second)/rate)
+}
+
+// durationToFrames returns the number of whole sample frames played in d at the given
+// sample rate
+func durationToFrames(d time.Duration, rate int64) int64 {
+	// split into whole seconds so long durations don't overflow
+	return int64(d/time.Second)*rate + int64(d%time.Second)*rate/int64(time.Second)
+}
@@ -0,0 +1,61 @@
+package pkg
+
+import (
+	"github.com/gravestench/wav/pkg/riff"
+)
+
+// ErrFourCC is returned when unmarshaling text which isn't four bytes long
+var ErrFourCC = riff.ErrFourCC
+
+// FourCC is a four character code, identifying RIFF chunks, list and form types
+type FourCC = riff.FourCC
+
+// Known chunk IDs and list types
+//
+//nolint:gochecknoglobals // four character codes can't be constants
+var (
+	ChunkRIFF = riff.ChunkRIFF
+	ChunkRF64 = riff.ChunkRF64
+	ChunkBW64 = riff.ChunkBW64
+	ChunkWAVE = riff.ChunkWAVE
+	ChunkFmt  = FourCC{'f', 'm', 't', ' '}
+	ChunkData = riff.ChunkData
+	ChunkFact = FourCC{'f', 'a', 'c', 't'}
+	ChunkDs64 = riff.ChunkDs64
+	ChunkLIST = riff.ChunkLIST
+	ChunkINFO = FourCC{'I', 'N', 'F', 'O'}
+	ChunkAdtl = FourCC{'a', 'd', 't', 'l'}
+	ChunkLabl = FourCC{'l', 'a', 'b', 'l'}
+	ChunkNote = FourCC{'n', 'o', 't', 'e'}
+	ChunkLtxt = FourCC{'l', 't', 'x', 't'}
+	ChunkCue  = FourCC{'c', 'u', 'e', ' '}
+	ChunkPlst = FourCC{'p', 'l', 's', 't'}
+	ChunkInst = FourCC{'i', 'n', 's', 't'}
+	ChunkSmpl = FourCC{'s', 'm', 'p', 'l'}
+	ChunkAcid = FourCC{'a', 'c', 'i', 'd'}
+	ChunkBext = FourCC{'b', 'e', 'x', 't'}
+	ChunkIXML = FourCC{'i', 'X', 'M', 'L'}
+	ChunkAXML = FourCC{'a', 'x', 'm', 'l'}
+	ChunkCart = FourCC{'c', 'a', 'r', 't'}
+	ChunkLevl = FourCC{'l', 'e', 'v', 'l'}
+	ChunkChna = FourCC{'c', 'h', 'n', 'a'}
+	ChunkDbmd = FourCC{'d', 'b', 'm', 'd'}
+	ChunkWavl = FourCC{'w', 'a', 'v', 'l'}
+	ChunkSlnt = FourCC{'s', 'l', 'n', 't'}
+	ChunkDISP = FourCC{'D', 'I', 'S', 'P'}
+	ChunkID3  = FourCC{'i', 'd', '3', ' '}
+	ChunkJUNK = riff.ChunkJUNK
+	ChunkPAD  = riff.ChunkPAD
+	ChunkFake = riff.ChunkFake
+	ChunkFLLR = riff.ChunkFLLR
+)
+
+// CreateFourCC creates a FourCC from a string, truncating it or padding it with spaces
+func CreateFourCC(s string) FourCC {
+	return riff.CreateFourCC(s)
+}
+
+// readFourCC returns the four character code at the start of b
+func readFourCC(b []byte) FourCC {
+	return riff.ReadFourCC(b)
+}
@@ -0,0 +1,157 @@
+package pkg
+
+const (
+	// TelephonySampleRate is the sample rate of G.711 telephony audio
+	TelephonySampleRate = 8000
+
+	muLawBias  = 0x84
+	muLawClip  = 8159
+	muLawMask  = 0x7F
+	aLawMask   = 0x55
+	g711Levels = 256
+
+	// muLawSilence and aLawSilence encode a 0 sample
+	muLawSilence = 0xFF
+	aLawSilence  = 0xD5
+)
+
+// segment ends of the 14-bit µ-law and 13-bit A-law magnitudes
+//
+//nolint:gochecknoglobals // lookup tables
+var (
+	muLawSegmentEnds = [8]int{0x3F, 0x7F, 0xFF, 0x1FF, 0x3FF, 0x7FF, 0xFFF, 0x1FFF}
+	aLawSegmentEnds  = [8]int{0x1F, 0x3F, 0x7F, 0xFF, 0x1FF, 0x3FF, 0x7FF, 0xFFF}
+)
+
+// muLawTable and aLawTable hold the 16-bit sample of every G.711 code
+//
+//nolint:gochecknoglobals // lookup tables
+var (
+	muLawTable = g711Table(muLawToLinear)
+	aLawTable  = g711Table(aLawToLinear)
+)
+
+// g711Table returns the 16-bit sample of every code
+func g711Table(decode func(code byte) int16) [g711Levels]int16 {
+	var result [g711Levels]int16
+
+	for code := range result {
+		result[code] = decode(byte(code))
+	}
+
+	return result
+}
+
+// segment returns the index of the first segment end at or above value, or 8 if
+// value exceeds them all
+func segment(value int, ends *[8]int) int {
+	for i, end := range ends {
+		if value <= end {
+			return i
+		}
+	}
+
+	return len(ends)
+}
+
+// linearToMuLaw compands a 16-bit sample to a G.711 µ-law code
+//
+//nolint:gomnd // G.711 bit layout
+func linearToMuLaw(sample int16) byte {
+	value := int(sample) >> 2
+	mask := 0xFF
+
+	if value < 0 {
+		value = -value
+		mask = muLawMask
+	}
+
+	if value > muLawClip {
+		value = muLawClip
+	}
+
+	value += muLawBias >> 2
+
+	seg := segment(value, &muLawSegmentEnds)
+	if seg >= len(muLawSegmentEnds) {
+		return byte(muLawMask ^ mask)
+	}
+
+	return byte((seg<<4 | (value>>(seg+1))&0x0F) ^ mask)
+}
+
+// muLawToLinear expands a G.711 µ-law code to a 16-bit sample
+//
+//nolint:gomnd // G.711 bit layout
+func muLawToLinear(code byte) int16 {
+	code = ^code
+	value := (int(code&0x0F)<<3 + muLawBias) << ((code & 0x70) >> 4)
+
+	if code&0x80 != 0 {
+		return int16(muLawBias - value)
+	}
+
+	return int16(value - muLawBias)
+}
+
+// linearToALaw compands a 16-bit sample to a G.711 A-law code
+//
+//nolint:gomnd // G.711 bit layout
+func linearToALaw(sample int16) byte {
+	value := int(sample) >> 3
+	mask := aLawMask | 0x80
+
+	if value < 0 {
+		value = -value - 1
+		mask = aLawMask
+	}
+
+	seg := segment(value, &aLawSegmentEnds)
+	if seg >= len(aLawSegmentEnds) {
+		return byte(0x7F ^ mask)
+	}
+
+	code := seg << 4
+	if seg < 2 {
+		code |= (value >> 1) & 0x0F
+	} else {
+		code |= (value >> seg) & 0x0F
+	}
+
+	return byte(code ^ mask)
+}
+
+// aLawToLinear expands a G.711 A-law code to a 16-bit sample
+//
+//nolint:gomnd // G.711 bit layout
+func aLawToLinear(code byte) int16 {
+	code ^= aLawMask
+	value := int(code&0x0F) << 4
+
+	switch seg := int(code&0x70) >> 4; seg {
+	case 0:
+		value += 8
+	case 1:
+		value += 0x108
+	default:
+		value = (value + 0x108) << (seg - 1)
+	}
+
+	if code&0x80 != 0 {
+		return int16(value)
+	}
+
+	return int16(-value)
+}
+
+// WithTelephony converts the output to what telephony systems such as IVR and SIP
+// expect: 8 kHz mono G.711, companded with tag FormatMuLaw or FormatALaw. The input
+// is mixed down and low-pass filtered as it is resampled.
+func WithTelephony(tag uint16) Option {
+	return func(o *options) {
+		o.sampleRate = TelephonySampleRate
+		o.channels = 1
+		o.codec = tag
+		o.telephony = true
+	}
+}
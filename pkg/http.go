@@ -0,0 +1,30 @@
+package pkg
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+)
+
+// ErrHTTPStatus is returned by CreateHTTPDecoder for an unsuccessful response
+var ErrHTTPStatus = errors.New("unsuccessful HTTP response")
+
+// CreateHTTPDecoder creates a progressive Decoder reading the WAV file in the body of
+// resp as it downloads, as WithProgressive describes. Samples can be read as soon as
+// the start of the data chunk has arrived. Closing the decoder closes the body.
+func CreateHTTPDecoder(resp *http.Response, opts ...DecoderOption) (*Decoder, error) {
+	if resp.StatusCode < http.StatusOK || resp.StatusCode >= http.StatusMultipleChoices {
+		resp.Body.Close() //nolint:errcheck // read only
+		return nil, fmt.Errorf("%w: %s", ErrHTTPStatus, resp.Status)
+	}
+
+	result, err := CreateStreamDecoder(resp.Body, append([]DecoderOption{WithProgressive()}, opts...)...)
+	if err != nil {
+		resp.Body.Close() //nolint:errcheck // read only
+		return nil, err
+	}
+
+	result.release = resp.Body.Close
+
+	return result, nil
+}
@@ -0,0 +1,526 @@
+// Package huffman implements the adaptive huffman coding used by MPQ archives
+package huffman
+
+// MpqHuffman.go based on the original CS file
+//
+// Authors:
+//		Foole (fooleau@gmail.com)
+//      Tim Sarbin (tim.sarbin@gmail.com) (go translation)
+//
+// (C) 2006 Foole (fooleau@gmail.com)
+// Based on code from StormLib by Ladislav Zezula and ShadowFlare
+//
+// Permission is hereby granted, free of charge, to any person obtaining
+// a copy of this software and associated documentation files (the
+// "Software"), to deal in the Software without restriction, including
+// without limitation the rights to use, copy, modify, merge, publish,
+// distribute, sublicense, and/or sell copies of the Software, and to
+// permit persons to whom the Software is furnished to do so, subject to
+// the following conditions:
+//
+// The above copyright notice and this permission notice shall be
+// included in all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND,
+// EXPRESS OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF
+// MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND
+// NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS BE
+// LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER IN AN ACTION
+// OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN CONNECTION
+// WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+//
+
+import (
+	"errors"
+	"fmt"
+	"io"
+
+	"github.com/gravestench/wav/pkg/bitio"
+)
+
+// ErrTree is returned when malformed data would leave the adaptive tree inconsistent
+var ErrTree = errors.New("corrupt huffman tree")
+
+// linkedNode is a node which is both hierachcical (parent/child) and doubly linked (next/prev)
+type linkedNode struct {
+	decompressedValue int
+	weight            int
+	parent            *linkedNode
+	child0            *linkedNode
+	prev              *linkedNode
+	next              *linkedNode
+}
+
+// createLinkedNode creates a linked node
+func createLinkedNode(decompVal, weight int) *linkedNode {
+	result := &linkedNode{
+		decompressedValue: decompVal,
+		weight:            weight,
+	}
+
+	return result
+}
+
+func (v *linkedNode) getChild1() *linkedNode {
+	return v.child0.prev
+}
+
+func (v *linkedNode) insert(other *linkedNode) *linkedNode {
+	// 'next' should have a lower weight we should return the lower weight
+	if other.weight <= v.weight {
+		// insert before
+		if v.next != nil {
+			v.next.prev = other
+			other.next = v.next
+		}
+
+		v.next = other
+
+		other.prev = v
+
+		return other
+	}
+
+	if v.prev == nil {
+		// insert after
+		other.prev = nil
+		v.prev = other
+		other.next = v
+	} else {
+		v.prev.insert(other)
+	}
+
+	return v
+}
+
+//nolint:funlen,dupl // it's ok to have duplicates and a long func here
+func getPrimes() [][]byte {
+	return [][]byte{
+		{
+			// Compression type 0
+			0x0A, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00,
+			0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00,
+			0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00,
+			0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00,
+			0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00,
+			0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00,
+			0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00,
+			0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00,
+			0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00,
+			0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00,
+			0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00,
+			0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00,
+			0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00,
+			0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00,
+			0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00,
+			0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02,
+		},
+		{ //nolint:dupl // it doesnt matter here
+			// Compression type 1
+			0x54, 0x16, 0x16, 0x0D, 0x0C, 0x08, 0x06, 0x05, 0x06, 0x05, 0x06, 0x03, 0x04, 0x04, 0x03, 0x05,
+			0x0E, 0x0B, 0x14, 0x13, 0x13, 0x09, 0x0B, 0x06, 0x05, 0x04, 0x03, 0x02, 0x03, 0x02, 0x02, 0x02,
+			0x0D, 0x07, 0x09, 0x06, 0x06, 0x04, 0x03, 0x02, 0x04, 0x03, 0x03, 0x03, 0x03, 0x03, 0x02, 0x02,
+			0x09, 0x06, 0x04, 0x04, 0x04, 0x04, 0x03, 0x02, 0x03, 0x02, 0x02, 0x02, 0x02, 0x03, 0x02, 0x04,
+			0x08, 0x03, 0x04, 0x07, 0x09, 0x05, 0x03, 0x03, 0x03, 0x03, 0x02, 0x02, 0x02, 0x03, 0x02, 0x02,
+			0x03, 0x02, 0x02, 0x02, 0x02, 0x02, 0x02, 0x02, 0x02, 0x01, 0x01, 0x01, 0x02, 0x01, 0x02, 0x02,
+			0x06, 0x0A, 0x08, 0x08, 0x06, 0x07, 0x04, 0x03, 0x04, 0x04, 0x02, 0x02, 0x04, 0x02, 0x03, 0x03,
+			0x04, 0x03, 0x07, 0x07, 0x09, 0x06, 0x04, 0x03, 0x03, 0x02, 0x01, 0x02, 0x02, 0x02, 0x02, 0x02,
+			0x0A, 0x02, 0x02, 0x03, 0x02, 0x02, 0x01, 0x01, 0x02, 0x02, 0x02, 0x06, 0x03, 0x05, 0x02, 0x03,
+			0x02, 0x01, 0x01, 0x01, 0x01, 0x01, 0x01, 0x01, 0x01, 0x01, 0x01, 0x02, 0x03, 0x01, 0x01, 0x01,
+			0x02, 0x01, 0x01, 0x01, 0x01, 0x01, 0x01, 0x02, 0x04, 0x04, 0x04, 0x07, 0x09, 0x08, 0x0C, 0x02,
+			0x01, 0x01, 0x01, 0x01, 0x01, 0x01, 0x01, 0x01, 0x01, 0x01, 0x01, 0x01, 0x02, 0x01, 0x01, 0x03,
+			0x04, 0x01, 0x02, 0x04, 0x05, 0x01, 0x01, 0x01, 0x01, 0x01, 0x01, 0x01, 0x02, 0x01, 0x01, 0x01,
+			0x04, 0x01, 0x01, 0x01, 0x01, 0x01, 0x02, 0x01, 0x01, 0x01, 0x01, 0x01, 0x01, 0x01, 0x01, 0x01,
+			0x02, 0x01, 0x01, 0x01, 0x01, 0x01, 0x01, 0x01, 0x03, 0x01, 0x01, 0x01, 0x01, 0x01, 0x01, 0x01,
+			0x02, 0x01, 0x01, 0x01, 0x01, 0x01, 0x01, 0x02, 0x02, 0x01, 0x01, 0x02, 0x02, 0x02, 0x06, 0x4B,
+		}, {
+			// Compression type 2 //nolint:dupl // it doesnt matter here
+			0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x03, 0x27, 0x00, 0x00, 0x23, 0x00, 0x00,
+			0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00,
+			0xFF, 0x01, 0x01, 0x01, 0x01, 0x01, 0x01, 0x01, 0x02, 0x02, 0x01, 0x01, 0x06, 0x0E, 0x10, 0x04,
+			0x06, 0x08, 0x05, 0x04, 0x04, 0x03, 0x03, 0x02, 0x02, 0x03, 0x03, 0x01, 0x01, 0x02, 0x01, 0x01,
+			0x01, 0x04, 0x02, 0x04, 0x02, 0x02, 0x02, 0x01, 0x01, 0x04, 0x01, 0x01, 0x02, 0x03, 0x03, 0x02,
+			0x03, 0x01, 0x03, 0x06, 0x04, 0x01, 0x01, 0x01, 0x01, 0x01, 0x01, 0x02, 0x01, 0x02, 0x01, 0x01,
+			0x01, 0x29, 0x07, 0x16, 0x12, 0x40, 0x0A, 0x0A, 0x11, 0x25, 0x01, 0x03, 0x17, 0x10, 0x26, 0x2A,
+			0x10, 0x01, 0x23, 0x23, 0x2F, 0x10, 0x06, 0x07, 0x02, 0x09, 0x01, 0x01, 0x01, 0x01, 0x01,
+		}, { //nolint:dupl // it doesnt matter here
+			// Compression type 3 //nolint:dupl // it doesnt matter here
+			0xFF, 0x0B, 0x07, 0x05, 0x0B, 0x02, 0x02, 0x02, 0x06, 0x02, 0x02, 0x01, 0x04, 0x02, 0x01, 0x03,
+			0x09, 0x01, 0x01, 0x01, 0x03, 0x04, 0x01, 0x01, 0x02, 0x01, 0x01, 0x01, 0x02, 0x01, 0x01, 0x01,
+			0x05, 0x01, 0x01, 0x01, 0x0D, 0x01, 0x01, 0x01, 0x01, 0x01, 0x01, 0x01, 0x01, 0x01, 0x01, 0x01,
+			0x02, 0x01, 0x01, 0x03, 0x01, 0x01, 0x01, 0x01, 0x01, 0x01, 0x01, 0x02, 0x01, 0x01, 0x01, 0x01,
+			0x0A, 0x04, 0x02, 0x01, 0x06, 0x03, 0x02, 0x01, 0x01, 0x01, 0x01, 0x01, 0x03, 0x01, 0x01, 0x01,
+			0x05, 0x02, 0x03, 0x04, 0x03, 0x03, 0x03, 0x02, 0x01, 0x01, 0x01, 0x02, 0x01, 0x02, 0x03, 0x03,
+			0x01, 0x03, 0x01, 0x01, 0x02, 0x05, 0x01, 0x01, 0x04, 0x03, 0x05, 0x01, 0x03, 0x01, 0x03, 0x03,
+			0x02, 0x01, 0x04, 0x03, 0x0A, 0x06, 0x01, 0x01, 0x01, 0x01, 0x01, 0x01, 0x01, 0x01, 0x01, 0x01,
+			0x02, 0x02, 0x01, 0x0A, 0x02, 0x05, 0x01, 0x01, 0x02, 0x07, 0x02, 0x17, 0x01, 0x05, 0x01, 0x01,
+			0x0E, 0x01, 0x01, 0x01, 0x01, 0x01, 0x01, 0x01, 0x01, 0x01, 0x01, 0x01, 0x01, 0x01, 0x01, 0x01,
+			0x01, 0x01, 0x01, 0x01, 0x01, 0x01, 0x01, 0x01, 0x01, 0x01, 0x01, 0x01, 0x01, 0x01, 0x01, 0x01,
+			0x01, 0x01, 0x01, 0x01, 0x01, 0x01, 0x01, 0x01, 0x01, 0x01, 0x01, 0x01, 0x01, 0x01, 0x01, 0x01,
+			0x06, 0x02, 0x01, 0x04, 0x05, 0x01, 0x01, 0x02, 0x01, 0x01, 0x01, 0x01, 0x02, 0x01, 0x01, 0x01,
+			0x01, 0x01, 0x01, 0x01, 0x01, 0x01, 0x01, 0x01, 0x01, 0x01, 0x01, 0x01, 0x01, 0x01, 0x01, 0x01,
+			0x01, 0x01, 0x01, 0x01, 0x01, 0x01, 0x01, 0x01, 0x07, 0x01, 0x01, 0x02, 0x01, 0x01, 0x01, 0x01,
+			0x02, 0x01, 0x01, 0x01, 0x01, 0x01, 0x01, 0x01, 0x02, 0x01, 0x01, 0x01, 0x01, 0x01, 0x01, 0x11,
+		}, { // Compression type 4 //nolint:dupl // it doesnt matter here
+			0xFF, 0xFB, 0x98, 0x9A, 0x84, 0x85, 0x63, 0x64, 0x3E, 0x3E, 0x22, 0x22, 0x13, 0x13, 0x18, 0x17,
+		}, { // Compression type 5 //nolint:dupl // it doesnt matter here
+			0xFF, 0xF1, 0x9D, 0x9E, 0x9A, 0x9B, 0x9A, 0x97, 0x93, 0x93, 0x8C, 0x8E, 0x86, 0x88, 0x80, 0x82,
+			0x7C, 0x7C, 0x72, 0x73, 0x69, 0x6B, 0x5F, 0x60, 0x55, 0x56, 0x4A, 0x4B, 0x40, 0x41, 0x37, 0x37,
+			0x2F, 0x2F, 0x27, 0x27, 0x21, 0x21, 0x1B, 0x1C, 0x17, 0x17, 0x13, 0x13, 0x10, 0x10, 0x0D, 0x0D,
+			0x0B, 0x0B, 0x09, 0x09, 0x08, 0x08, 0x07, 0x07, 0x06, 0x05, 0x05, 0x04, 0x04, 0x04, 0x19, 0x18,
+		}, { //nolint:dupl // it doesnt matter here
+			// Compression type 6
+			0xC3, 0xCB, 0xF5, 0x41, 0xFF, 0x7B, 0xF7, 0x21, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00,
+			0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00,
+			0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00,
+			0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00,
+			0xBF, 0xCC, 0xF2, 0x40, 0xFD, 0x7C, 0xF7, 0x22, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00,
+			0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00,
+			0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00,
+			0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00,
+			0x7A, 0x46,
+		}, { //nolint:dupl // it doesnt matter here
+			// Compression type 7
+			0xC3, 0xD9, 0xEF, 0x3D, 0xF9, 0x7C, 0xE9, 0x1E, 0xFD, 0xAB, 0xF1, 0x2C, 0xFC, 0x5B, 0xFE, 0x17,
+			0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00,
+			0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00,
+			0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00,
+			0xBD, 0xD9, 0xEC, 0x3D, 0xF5, 0x7D, 0xE8, 0x1D, 0xFB, 0xAE, 0xF0, 0x2C, 0xFB, 0x5C, 0xFF, 0x18,
+			0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00,
+			0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00,
+			0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00,
+			0x70, 0x6C,
+		}, { // Compression type 8
+			0xBA, 0xC5, 0xDA, 0x33, 0xE3, 0x6D, 0xD8, 0x18, 0xE5, 0x94, 0xDA, 0x23, 0xDF, 0x4A, 0xD1, 0x10,
+			0xEE, 0xAF, 0xE4, 0x2C, 0xEA, 0x5A, 0xDE, 0x15, 0xF4, 0x87, 0xE9, 0x21, 0xF6, 0x43, 0xFC, 0x12,
+			0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00,
+			0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00,
+			0xB0, 0xC7, 0xD8, 0x33, 0xE3, 0x6B, 0xD6, 0x18, 0xE7, 0x95, 0xD8, 0x23, 0xDB, 0x49, 0xD0, 0x11,
+			0xE9, 0xB2, 0xE2, 0x2B, 0xE8, 0x5C, 0xDD, 0x15, 0xF1, 0x87, 0xE7, 0x20, 0xF7, 0x44, 0xFF, 0x13,
+			0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00,
+			0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00,
+			0x5F, 0x9E,
+		},
+	}
+}
+
+func decode(input *bitio.BitStream, head *linkedNode) (*linkedNode, error) {
+	node := head
+
+	for node.child0 != nil {
+		bit := input.ReadBits(1)
+		if bit == -1 {
+			return nil, io.ErrUnexpectedEOF
+		}
+
+		if bit == 0 {
+			node = node.child0
+			continue
+		}
+
+		node = node.getChild1()
+	}
+
+	return node, nil
+}
+
+// checkCompressionType returns an error for compression types without initial weights
+func checkCompressionType(comptype byte, primes [][]byte) error {
+	if comptype == 0 || int(comptype) >= len(primes) {
+		return fmt.Errorf("%w: %d", ErrCompressionType, comptype)
+	}
+
+	return nil
+}
+
+const (
+	decompVal1 = 256
+	decompVal2 = 257
+)
+
+func buildList(primeData []byte) *linkedNode {
+	root := createLinkedNode(decompVal1, 1)
+	root = root.insert(createLinkedNode(decompVal2, 1))
+
+	for i := 0; i < len(primeData); i++ {
+		if primeData[i] != 0 {
+			root = root.insert(createLinkedNode(i, int(primeData[i])))
+		}
+	}
+
+	return root
+}
+
+func insertNode(tail *linkedNode, decomp int) (*linkedNode, error) {
+	parent := tail
+	result := tail.prev // This will be the new tail after the tree is updated
+
+	temp := createLinkedNode(parent.decompressedValue, parent.weight)
+	temp.parent = parent
+
+	newnode := createLinkedNode(decomp, 0)
+	newnode.parent = parent
+
+	parent.child0 = newnode
+
+	tail.next = temp
+	temp.prev = tail
+	newnode.prev = temp
+	temp.next = newnode
+
+	if err := adjustTree(newnode); err != nil {
+		return nil, err
+	}
+
+	// ISSUE #680: For compression type 0, adjustTree should be
+	// called once for every value written and only once here
+	if err := adjustTree(newnode); err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
+
+// This increases the weight of the new node and its antecendants
+// and adjusts the tree if needed
+func adjustTree(newNode *linkedNode) error {
+	current := newNode
+
+	for current != nil {
+		current.weight++
+
+		var insertpoint *linkedNode
+
+		var prev *linkedNode
+
+		// Go backwards thru the list looking for the insertion point
+		insertpoint = current
+
+		for {
+			prev = insertpoint.prev
+			if prev == nil {
+				break
+			}
+
+			if prev.weight >= current.weight {
+				break
+			}
+
+			insertpoint = prev
+		}
+
+		// No insertion point found
+		if insertpoint == current {
+			current = current.parent
+			continue
+		}
+
+		// current can only move behind a node, which malformed data can break
+		if prev == nil {
+			return ErrTree
+		}
+
+		// The following code basically swaps insertpoint with current
+
+		// remove insert point
+		if insertpoint.prev != nil {
+			insertpoint.prev.next = insertpoint.next
+		}
+
+		insertpoint.next.prev = insertpoint.prev
+
+		// insert insertpoint after current
+		insertpoint.next = current.next
+		insertpoint.prev = current
+
+		if current.next != nil {
+			current.next.prev = insertpoint
+		}
+
+		current.next = insertpoint
+
+		// remove current
+		current.prev.next = current.next
+		current.next.prev = current.prev
+
+		// insert current after prev
+		temp := prev.next
+		current.next = temp
+		current.prev = prev
+		temp.prev = current
+		prev.next = current
+
+		// Set up parent/child links
+		currentparent := current.parent
+		insertparent := insertpoint.parent
+
+		if currentparent.child0 == current {
+			currentparent.child0 = insertpoint
+		}
+
+		if currentparent != insertparent && insertparent.child0 == insertpoint {
+			insertparent.child0 = current
+		}
+
+		current.parent = insertparent
+		insertpoint.parent = currentparent
+
+		current = current.parent
+	}
+
+	return nil
+}
+
+func buildTree(tail *linkedNode) *linkedNode {
+	current := tail
+
+	for current != nil {
+		child0 := current
+		child1 := current.prev
+
+		if child1 == nil {
+			break
+		}
+
+		parent := createLinkedNode(0, child0.weight+child1.weight)
+		parent.child0 = child0
+		child0.parent = parent
+		child1.parent = parent
+
+		current.insert(parent)
+		current = current.prev.prev
+	}
+
+	return current
+}
+
+// Decompress decompresses huffman-compressed data
+func Decompress(data []byte) ([]byte, error) {
+	return DecompressSize(data, 0)
+}
+
+// DecompressSize decompresses huffman-compressed data into a buffer allocated
+// for the expected decompressed size, such as the sector size from an MPQ sector table.
+// An unknown compression type fails with ErrCompressionType, and data ending before
+// its end marker with io.ErrUnexpectedEOF.
+//
+//nolint:gomnd // binary decode magic
+func DecompressSize(data []byte, expectedSize int) ([]byte, error) {
+	if len(data) == 0 {
+		return nil, io.ErrUnexpectedEOF
+	}
+
+	comptype := data[0]
+	primes := getPrimes()
+
+	if err := checkCompressionType(comptype, primes); err != nil {
+		return nil, err
+	}
+
+	tail := buildList(primes[comptype])
+	head := buildTree(tail)
+
+	outputstream := bitio.CreateStreamWriterSize(expectedSize)
+	bitstream := bitio.CreateBitStream(data[1:])
+
+	var decoded int
+
+Loop:
+	for {
+		node, err := decode(bitstream, head)
+		if err != nil {
+			return nil, err
+		}
+
+		decoded = node.decompressedValue
+		switch decoded {
+		case 256:
+			break Loop
+		case 257:
+			newvalue := bitstream.ReadBits(8)
+			if newvalue == -1 {
+				return nil, io.ErrUnexpectedEOF
+			}
+
+			outputstream.PushBytes(byte(newvalue))
+
+			if tail, err = insertNode(tail, newvalue); err != nil {
+				return nil, err
+			}
+		default:
+			outputstream.PushBytes(byte(decoded))
+		}
+	}
+
+	return outputstream.GetBytes(), nil
+}
+
+// huffmanCodes returns the bit path to every leaf as decode walks the tree, where
+// a set bit selects child0.prev
+func huffmanCodes(head *linkedNode) map[int][]bool {
+	result := make(map[int][]bool)
+
+	var walk func(node *linkedNode, path []bool)
+
+	walk = func(node *linkedNode, path []bool) {
+		if node.child0 == nil {
+			result[node.decompressedValue] = path
+			return
+		}
+
+		walk(node.child0, append(path[:len(path):len(path)], false))
+		walk(node.getChild1(), append(path[:len(path):len(path)], true))
+	}
+
+	walk(head, nil)
+
+	return result
+}
+
+// encode writes the bit path of a leaf
+func encode(output *bitio.BitWriter, path []bool) {
+	for _, bit := range path {
+		output.PushBit(bit)
+	}
+}
+
+// Compress compresses data with the adaptive huffman coding used by MPQ
+// archives, starting from the weights of the given compression type, 1 to 8. Other
+// compression types fail with ErrCompressionType.
+//
+//nolint:gomnd // binary encode magic
+func Compress(data []byte, comptype byte) ([]byte, error) {
+	primes := getPrimes()
+
+	if err := checkCompressionType(comptype, primes); err != nil {
+		return nil, err
+	}
+
+	tail := buildList(primes[comptype])
+	head := buildTree(tail)
+	codes := huffmanCodes(head)
+
+	outputstream := bitio.CreateBitWriter(bitio.LSBFirst)
+	outputstream.PushBytes(comptype)
+
+	for _, value := range data {
+		if path, ok := codes[int(value)]; ok {
+			encode(outputstream, path)
+			continue
+		}
+
+		encode(outputstream, codes[decompVal2])
+		outputstream.PushBits(uint64(value), 8)
+
+		var err error
+		if tail, err = insertNode(tail, int(value)); err != nil {
+			return nil, err
+		}
+
+		codes = huffmanCodes(head)
+	}
+
+	encode(outputstream, codes[decompVal1])
+
+	return outputstream.GetBytes(), nil
+}
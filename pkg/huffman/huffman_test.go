@@ -0,0 +1,76 @@
+package huffman_test
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"math/rand"
+	"testing"
+
+	"github.com/gravestench/wav/pkg/huffman"
+)
+
+// inputs returns data with very different byte distributions, so the
+// adaptive tree both keeps and rebuilds its initial weights
+func inputs() map[string][]byte {
+	random := make([]byte, 4096)
+	rand.New(rand.NewSource(1)).Read(random)
+
+	ramp := make([]byte, 1024)
+	for i := range ramp {
+		ramp[i] = byte(i)
+	}
+
+	return map[string][]byte{
+		"byte":   {0x42},
+		"text":   []byte("the quick brown fox jumps over the lazy dog, again and again and again"),
+		"zeros":  make([]byte, 2048),
+		"ramp":   ramp,
+		"random": random,
+	}
+}
+
+func TestRoundTrip(t *testing.T) {
+	for name, data := range inputs() {
+		for comptype := byte(1); comptype <= 8; comptype++ {
+			compressed, err := huffman.Compress(data, comptype)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			got, err := huffman.Decompress(compressed)
+			if err != nil {
+				t.Fatalf("%s, type %d: %v", name, comptype, err)
+			}
+
+			if !bytes.Equal(got, data) {
+				t.Errorf("%s, type %d: decompressed %d bytes, want the %d compressed", name, comptype, len(got), len(data))
+			}
+		}
+	}
+}
+
+func TestCompressionType(t *testing.T) {
+	for _, comptype := range []byte{0, 9, 0xff} {
+		if _, err := huffman.Compress([]byte("data"), comptype); !errors.Is(err, huffman.ErrCompressionType) {
+			t.Errorf("compressing with type %d returned %v, want %v", comptype, err, huffman.ErrCompressionType)
+		}
+
+		if _, err := huffman.Decompress([]byte{comptype, 0}); !errors.Is(err, huffman.ErrCompressionType) {
+			t.Errorf("decompressing type %d returned %v, want %v", comptype, err, huffman.ErrCompressionType)
+		}
+	}
+}
+
+func TestDecompressTruncated(t *testing.T) {
+	compressed, err := huffman.Compress(inputs()["text"], 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, data := range [][]byte{nil, compressed[:len(compressed)/2]} {
+		if _, err = huffman.Decompress(data); !errors.Is(err, io.ErrUnexpectedEOF) {
+			t.Errorf("decompressing %d of %d bytes returned %v, want %v", len(data), len(compressed), err, io.ErrUnexpectedEOF)
+		}
+	}
+}
@@ -0,0 +1,129 @@
+package huffman
+
+import (
+	"bufio"
+	"errors"
+	"io"
+)
+
+const bitsPerByte = 8
+
+// ErrCompressionType is returned for a compression type without initial weights
+var ErrCompressionType = errors.New("unsupported huffman compression type")
+
+// huffmanReader decodes a huffman stream as it is read, holding only the adaptive
+// tree and a small input buffer
+type huffmanReader struct {
+	src  io.ByteReader
+	head *linkedNode
+	tail *linkedNode
+	// current holds bitCount unread bits, least significant first
+	current  int
+	bitCount int
+	done     bool
+}
+
+// CreateReader creates a reader decompressing the huffman-compressed data read
+// from r, like Decompress but streaming, so memory use doesn't grow with the
+// length of the data. A stream ending before its end marker fails with
+// io.ErrUnexpectedEOF.
+func CreateReader(r io.Reader) io.Reader {
+	src, ok := r.(io.ByteReader)
+	if !ok {
+		src = bufio.NewReader(r)
+	}
+
+	return &huffmanReader{src: src}
+}
+
+// start reads the compression type and builds the initial tree
+func (v *huffmanReader) start() error {
+	comptype, err := v.src.ReadByte()
+	if err != nil {
+		return err
+	}
+
+	primes := getPrimes()
+
+	if err = checkCompressionType(comptype, primes); err != nil {
+		return err
+	}
+
+	v.tail = buildList(primes[comptype])
+	v.head = buildTree(v.tail)
+
+	return nil
+}
+
+// readBits reads up to 16 bits, least significant first
+func (v *huffmanReader) readBits(bitCount int) (int, error) {
+	for v.bitCount < bitCount {
+		next, err := v.src.ReadByte()
+		if err != nil {
+			return 0, io.ErrUnexpectedEOF
+		}
+
+		v.current |= int(next) << uint(v.bitCount)
+		v.bitCount += bitsPerByte
+	}
+
+	result := v.current & (1<<uint(bitCount) - 1)
+	v.current >>= uint(bitCount)
+	v.bitCount -= bitCount
+
+	return result, nil
+}
+
+// Read implements io.Reader
+func (v *huffmanReader) Read(p []byte) (int, error) {
+	if v.head == nil && !v.done {
+		if err := v.start(); err != nil {
+			return 0, err
+		}
+	}
+
+	n := 0
+
+	for n < len(p) && !v.done {
+		node := v.head
+
+		for node.child0 != nil {
+			bit, err := v.readBits(1)
+			if err != nil {
+				return n, err
+			}
+
+			if bit == 0 {
+				node = node.child0
+			} else {
+				node = node.getChild1()
+			}
+		}
+
+		switch node.decompressedValue {
+		case decompVal1:
+			v.done = true
+		case decompVal2:
+			value, err := v.readBits(bitsPerByte)
+			if err != nil {
+				return n, err
+			}
+
+			p[n] = byte(value)
+			n++
+
+			if v.tail, err = insertNode(v.tail, value); err != nil {
+				return n, err
+			}
+		default:
+			p[n] = byte(node.decompressedValue)
+			n++
+		}
+	}
+
+	if n == 0 && v.done {
+		return 0, io.EOF
+	}
+
+	return n, nil
+}
@@ -0,0 +1,148 @@
+package pkg
+
+import (
+	"encoding/json"
+	"io"
+	"os"
+	"time"
+)
+
+// Info describes a WAV file without decoding its audio
+type Info struct {
+	Format   Format
+	Frames   int64
+	Duration time.Duration
+	// Chunks lists every chunk in file order, with the chunks nested in a LIST
+	// following the LIST itself
+	Chunks []*Chunk
+	// Tags holds the text of the LIST INFO chunk, keyed by ID such as INAM or IART
+	Tags map[string]string
+}
+
+// Probe indexes the chunks of the WAV file of the given size held by r, reading
+// only the fmt chunk and the INFO tags
+func Probe(r io.ReaderAt, size int64) (*Info, error) {
+	file, err := CreateFile(r, size)
+	if err != nil {
+		return nil, err
+	}
+
+	body, err := file.Chunk(ChunkFmt.String()).Bytes()
+	if err != nil {
+		return nil, ErrMissingFormat
+	}
+
+	format, err := parseWaveFormat(body)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &Info{
+		Format: format,
+		Chunks: file.Chunks(),
+		Tags:   map[string]string{},
+	}
+
+	if data := file.Chunk(ChunkData.String()); data != nil {
+		result.Frames = format.OffsetFrames(data.Size)
+		result.Duration = format.FramesToDuration(result.Frames)
+	}
+
+	for _, chunk := range result.Chunks {
+		if chunk.List != ChunkINFO {
+			continue
+		}
+
+		text, err := chunk.Bytes()
+		if err != nil {
+			return nil, err
+		}
+
+		result.Tags[chunk.ID.String()] = trimString(text)
+	}
+
+	return result, nil
+}
+
+// ProbeFile describes the WAV file at path, like Probe
+func ProbeFile(path string) (*Info, error) {
+	f, err := os.Open(path) //nolint:gosec // opening caller supplied paths is the point
+	if err != nil {
+		return nil, err
+	}
+
+	defer f.Close() //nolint:errcheck // read only
+
+	stat, err := f.Stat()
+	if err != nil {
+		return nil, err
+	}
+
+	return Probe(f, stat.Size())
+}
+
+// infoJSON is the JSON form of Info
+type infoJSON struct {
+	Format   formatJSON        `json:"format"`
+	Frames   int64             `json:"frames"`
+	Duration float64           `json:"duration"`
+	Chunks   []chunkJSON       `json:"chunks"`
+	Tags     map[string]string `json:"tags"`
+}
+
+type formatJSON struct {
+	Tag            uint16   `json:"tag"`
+	Codec          string   `json:"codec"`
+	Channels       uint16   `json:"channels"`
+	SampleRate     uint32   `json:"sampleRate"`
+	BitsPerSample  uint16   `json:"bitsPerSample"`
+	BlockAlign     uint16   `json:"blockAlign"`
+	AvgBytesPerSec uint32   `json:"avgBytesPerSec"`
+	Layout         []string `json:"layout"`
+}
+
+type chunkJSON struct {
+	ID     FourCC  `json:"id"`
+	List   *FourCC `json:"list,omitempty"`
+	Offset int64   `json:"offset"`
+	Size   int64   `json:"size"`
+}
+
+// MarshalJSON implements json.Marshaler, describing the format, the duration in
+// seconds, the chunks and the INFO tags
+func (v *Info) MarshalJSON() ([]byte, error) {
+	result := infoJSON{
+		Format: formatJSON{
+			Tag:            v.Format.effectiveTag(),
+			Codec:          v.Format.CodecName(),
+			Channels:       v.Format.Channels,
+			SampleRate:     v.Format.SampleRate,
+			BitsPerSample:  v.Format.BitsPerSample,
+			BlockAlign:     v.Format.BlockAlign,
+			AvgBytesPerSec: v.Format.AvgBytesPerSec,
+		},
+		Frames:   v.Frames,
+		Duration: v.Duration.Seconds(),
+		Chunks:   make([]chunkJSON, len(v.Chunks)),
+		Tags:     v.Tags,
+	}
+
+	for _, speaker := range v.Format.Layout() {
+		result.Format.Layout = append(result.Format.Layout, speaker.String())
+	}
+
+	for i, chunk := range v.Chunks {
+		result.Chunks[i] = chunkJSON{ID: chunk.ID, Offset: chunk.Offset, Size: chunk.Size}
+
+		if chunk.List != (FourCC{}) {
+			list := chunk.List
+			result.Chunks[i].List = &list
+		}
+	}
+
+	if result.Tags == nil {
+		result.Tags = map[string]string{}
+	}
+
+	return json.Marshal(result)
+}
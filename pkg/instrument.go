@@ -0,0 +1,69 @@
+package pkg
+
+const instChunkSize = 7
+
+// Instrument holds the contents of an inst chunk, describing how a sampler should
+// map the sound onto notes and velocities
+type Instrument struct {
+	// UnshiftedNote is the MIDI note played back at the original pitch
+	UnshiftedNote uint8
+	// FineTune is the pitch adjustment in cents, from -50 to 50
+	FineTune int8
+	// Gain is the playback gain in decibels
+	Gain int8
+	// LowNote and HighNote bound the MIDI note range the sound is used for
+	LowNote  uint8
+	HighNote uint8
+	// LowVelocity and HighVelocity bound the MIDI velocity range the sound is used for
+	LowVelocity  uint8
+	HighVelocity uint8
+}
+
+// parseInstrument parses the body of an inst chunk
+func parseInstrument(body []byte) (*Instrument, error) {
+	if len(body) < instChunkSize {
+		return nil, ErrShortMetadata
+	}
+
+	result := &Instrument{
+		UnshiftedNote: body[0],
+		FineTune:      int8(body[1]),
+		Gain:          int8(body[2]),
+		LowNote:       body[3],
+		HighNote:      body[4],
+		LowVelocity:   body[5],
+		HighVelocity:  body[6],
+	}
+
+	return result, nil
+}
+
+// marshal returns the body of an inst chunk
+func (v *Instrument) marshal() []byte {
+	return []byte{
+		v.UnshiftedNote,
+		byte(v.FineTune),
+		byte(v.Gain),
+		v.LowNote,
+		v.HighNote,
+		v.LowVelocity,
+		v.HighVelocity,
+	}
+}
+
+// MarshalBinary implements encoding.BinaryMarshaler, returning the body of an inst chunk
+func (v *Instrument) MarshalBinary() ([]byte, error) {
+	return v.marshal(), nil
+}
+
+// UnmarshalBinary implements encoding.BinaryUnmarshaler, parsing the body of an inst chunk
+func (v *Instrument) UnmarshalBinary(data []byte) error {
+	parsed, err := parseInstrument(data)
+	if err != nil {
+		return err
+	}
+
+	*v = *parsed
+
+	return nil
+}
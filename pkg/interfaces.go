@@ -0,0 +1,77 @@
+package pkg
+
+import (
+	"errors"
+	"io"
+
+	"github.com/gravestench/wav/pkg/bitio"
+)
+
+const copySamplesSize = 4096
+
+// ByteReader16 reads little-endian 16-bit words, as the stream reader does
+type ByteReader16 = bitio.ByteReader16
+
+// ByteReader32 reads little-endian 32-bit dwords, as the stream reader does
+type ByteReader32 = bitio.ByteReader32
+
+// ChunkSink accepts chunks to be written to a RIFF file, as Encoder does
+type ChunkSink interface {
+	AddChunk(id FourCC, body []byte)
+}
+
+// SampleSource produces interleaved 16-bit samples, as Decoder does. ReadSamples
+// returns io.EOF once the samples are exhausted.
+type SampleSource interface {
+	ReadSamples(dst []int16) (int, error)
+}
+
+// SampleSourceFunc adapts a function filling dst with samples to the SampleSource
+// interface, for audio generated on demand
+type SampleSourceFunc func(dst []int16) (int, error)
+
+// ReadSamples calls the function
+func (v SampleSourceFunc) ReadSamples(dst []int16) (int, error) {
+	return v(dst)
+}
+
+// SampleSink consumes interleaved 16-bit samples, as Encoder does
+type SampleSink interface {
+	WriteSamples(samples []int16) error
+}
+
+var (
+	_ ChunkSink    = (*Encoder)(nil)
+	_ SampleSource = (*Decoder)(nil)
+	_ SampleSource = SampleSourceFunc(nil)
+	_ SampleSource = (*ChainDecoder)(nil)
+	_ SampleSink   = (*Encoder)(nil)
+	_ Resampler    = LinearResampler{}
+)
+
+// CopySamples copies samples from src to dst until src is exhausted, returning the
+// number of samples copied. Like io.Copy, reaching the end of src isn't an error.
+func CopySamples(dst SampleSink, src SampleSource) (int64, error) {
+	buf := make([]int16, copySamplesSize)
+
+	var total int64
+
+	for {
+		n, err := src.ReadSamples(buf)
+		if n > 0 {
+			if writeErr := dst.WriteSamples(buf[:n]); writeErr != nil {
+				return total, writeErr
+			}
+
+			total += int64(n)
+		}
+
+		if errors.Is(err, io.EOF) {
+			return total, nil
+		}
+
+		if err != nil {
+			return total, err
+		}
+	}
+}
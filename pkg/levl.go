@@ -0,0 +1,208 @@
+package pkg
+
+const (
+	levlHeaderSize       = 120
+	levlTimestampSize    = 28
+	levlVersion          = 1
+	levlDefaultBlockSize = 256
+	levlPointsPerValue   = 2
+	levlUnknownPeak      = 0xFFFFFFFF
+)
+
+// Peak envelope value formats
+const (
+	PeakFormat8  = 1
+	PeakFormat16 = 2
+)
+
+// PeakEnvelope holds the contents of a levl chunk (EBU Tech 3285 supplement 3), a
+// precomputed overview of the audio that editors draw waveforms from
+type PeakEnvelope struct {
+	// Format is PeakFormat8 or PeakFormat16
+	Format uint32
+	// PointsPerValue is 1 when only positive peaks are stored, or 2 for positive and
+	// negative peak pairs
+	PointsPerValue uint32
+	// BlockSize is the number of sample frames each peak covers
+	BlockSize uint32
+	Channels  uint32
+	// PeakOfPeaks is the frame of the highest absolute sample, or 0xFFFFFFFF if unknown
+	PeakOfPeaks uint32
+	// Timestamp is the creation time, formatted as yyyy:mm:dd:hh:mm:ss:uuu
+	Timestamp string
+	// Peaks holds the absolute peak values of each block, with PointsPerValue values
+	// for each channel in turn
+	Peaks []uint16
+}
+
+// Frames returns the number of peak frames in the envelope
+func (v *PeakEnvelope) Frames() int {
+	if v.Channels == 0 || v.PointsPerValue == 0 {
+		return 0
+	}
+
+	return len(v.Peaks) / int(v.Channels*v.PointsPerValue)
+}
+
+// ComputePeakEnvelope computes a 16-bit positive and negative peak envelope of
+// interleaved samples, over blocks of blockSize frames (256 if blockSize is 0)
+func ComputePeakEnvelope(samples []int16, channels, blockSize int) *PeakEnvelope {
+	if blockSize <= 0 {
+		blockSize = levlDefaultBlockSize
+	}
+
+	result := &PeakEnvelope{
+		Format:         PeakFormat16,
+		PointsPerValue: levlPointsPerValue,
+		BlockSize:      uint32(blockSize),
+		Channels:       uint32(channels),
+		PeakOfPeaks:    levlUnknownPeak,
+	}
+
+	if channels <= 0 {
+		return result
+	}
+
+	frames := len(samples) / channels
+	highest := -1
+
+	for start := 0; start < frames; start += blockSize {
+		end := start + blockSize
+		if end > frames {
+			end = frames
+		}
+
+		for ch := 0; ch < channels; ch++ {
+			positive, negative := 0, 0
+
+			for frame := start; frame < end; frame++ {
+				sample := int(samples[frame*channels+ch])
+
+				if sample > positive {
+					positive = sample
+				}
+
+				if -sample > negative {
+					negative = -sample
+				}
+
+				if peak := clampInt(abs(sample), 0, maxInt16); peak > highest {
+					highest = peak
+					result.PeakOfPeaks = uint32(frame)
+				}
+			}
+
+			result.Peaks = append(result.Peaks, uint16(positive), uint16(clampInt(negative, 0, maxInt16)))
+		}
+	}
+
+	return result
+}
+
+// parsePeakEnvelope parses the body of a levl chunk
+func parsePeakEnvelope(body []byte) (*PeakEnvelope, error) {
+	if len(body) < levlHeaderSize {
+		return nil, ErrShortMetadata
+	}
+
+	r := CreateStreamReader(body)
+	result := &PeakEnvelope{}
+
+	r.SkipBytes(bytesPerint32) // version
+	result.Format, _ = r.ReadUInt32()
+	result.PointsPerValue, _ = r.ReadUInt32()
+	result.BlockSize, _ = r.ReadUInt32()
+	result.Channels, _ = r.ReadUInt32()
+	frames, _ := r.ReadUInt32()
+	result.PeakOfPeaks, _ = r.ReadUInt32()
+	offset, _ := r.ReadUInt32()
+	result.Timestamp, _ = r.ReadFixedString(levlTimestampSize)
+
+	// the offset to the peaks counts the chunk header
+	if offset >= chunkHeaderSize {
+		r.SetPosition(uint64(offset - chunkHeaderSize))
+	} else {
+		r.SetPosition(levlHeaderSize)
+	}
+
+	count := uint64(frames) * uint64(result.Channels) * uint64(result.PointsPerValue)
+	if count > r.Size() {
+		return nil, ErrShortMetadata
+	}
+
+	result.Peaks = make([]uint16, 0, count)
+
+	for i := uint64(0); i < count; i++ {
+		var value uint16
+
+		var err error
+
+		if result.Format == PeakFormat8 {
+			var b byte
+			b, err = r.ReadByte()
+			value = uint16(b)
+		} else {
+			value, err = r.ReadUInt16()
+		}
+
+		if err != nil {
+			return nil, ErrShortMetadata
+		}
+
+		result.Peaks = append(result.Peaks, value)
+	}
+
+	return result, nil
+}
+
+// marshal returns the body of a levl chunk
+func (v *PeakEnvelope) marshal() []byte {
+	w := CreateStreamWriterSize(levlHeaderSize + len(v.Peaks)*bytesPerint16)
+
+	w.PushUint32(levlVersion)
+	w.PushUint32(v.Format)
+	w.PushUint32(v.PointsPerValue)
+	w.PushUint32(v.BlockSize)
+	w.PushUint32(v.Channels)
+	w.PushUint32(uint32(v.Frames()))
+	w.PushUint32(v.PeakOfPeaks)
+	w.PushUint32(levlHeaderSize + chunkHeaderSize)
+	w.PushFixedString(v.Timestamp, levlTimestampSize)
+	w.PushBytes(make([]byte, levlHeaderSize-w.Len())...)
+
+	for _, value := range v.Peaks[:v.Frames()*int(v.Channels*v.PointsPerValue)] {
+		if v.Format == PeakFormat8 {
+			w.PushBytes(byte(value))
+		} else {
+			w.PushUint16(value)
+		}
+	}
+
+	return w.GetBytes()
+}
+
+// MarshalBinary implements encoding.BinaryMarshaler, returning the body of a levl chunk
+func (v *PeakEnvelope) MarshalBinary() ([]byte, error) {
+	return v.marshal(), nil
+}
+
+// UnmarshalBinary implements encoding.BinaryUnmarshaler, parsing the body of a levl chunk
+func (v *PeakEnvelope) UnmarshalBinary(data []byte) error {
+	parsed, err := parsePeakEnvelope(data)
+	if err != nil {
+		return err
+	}
+
+	*v = *parsed
+
+	return nil
+}
+
+// abs returns the absolute value of an int
+func abs(value int) int {
+	if value < 0 {
+		return -value
+	}
+
+	return value
+}
@@ -0,0 +1,310 @@
+package pkg
+
+import (
+	"github.com/gravestench/wav/pkg/riff"
+)
+
+const (
+	cuePointSize       = 24
+	ltxtHeaderSize     = 20
+	defaultLtxtPurpose = "rgn "
+	smplLoopCountAt    = 28
+	smplUnityNote      = 60
+	nanosPerSecond     = 1e9
+)
+
+// ErrShortMetadata is returned when a metadata chunk is shorter than its contents require
+var ErrShortMetadata = riff.ErrShortChunk
+
+// LoopType is how a sampler plays a smpl loop
+type LoopType uint32
+
+// Loop types
+const (
+	LoopForward LoopType = iota
+	LoopAlternating
+	LoopBackward
+)
+
+// SampleLoop holds the smpl loop fields of a Marker which has no cue point equivalent
+type SampleLoop struct {
+	Type LoopType
+	// Fraction is the fraction of a sample frame by which the loop end is extended
+	Fraction uint32
+	// PlayCount is the number of times the loop is played, or 0 to loop forever
+	PlayCount uint32
+}
+
+// Marker is a cue point together with the label, note and labelled text that the
+// associated data list (LIST adtl) attaches to its ID, and the smpl loop referring
+// to it. A Marker with a non-zero Length is a region, or a loop if Loop is set.
+type Marker struct {
+	// ID is the cue point ID (dwName) linking the cue point to its adtl entries
+	ID uint32
+	// Position is the sample frame the cue point refers to
+	Position uint32
+	// Label is the labl text naming the cue point
+	Label string
+	// Note is the note text commenting on the cue point
+	Note string
+	// Length is the ltxt sample length of a region
+	Length uint32
+	// Purpose is the ltxt purpose ID, such as "rgn "
+	Purpose string
+	// Text is the ltxt text describing a region
+	Text string
+	// Loop is set when a smpl loop refers to the cue point, in which case Position
+	// and Length span the loop
+	Loop *SampleLoop
+}
+
+// markerList collects markers by cue point ID while metadata chunks are parsed
+type markerList struct {
+	markers []Marker
+}
+
+func (v *markerList) get(id uint32) *Marker {
+	for i := range v.markers {
+		if v.markers[i].ID == id {
+			return &v.markers[i]
+		}
+	}
+
+	v.markers = append(v.markers, Marker{ID: id})
+
+	return &v.markers[len(v.markers)-1]
+}
+
+// parseCue parses the body of a cue chunk
+func (v *markerList) parseCue(body []byte) error {
+	r := CreateStreamReader(body)
+
+	count, err := r.ReadUInt32()
+	if err != nil || uint64(count)*cuePointSize > r.Size()-r.Position() {
+		return ErrShortMetadata
+	}
+
+	for i := uint32(0); i < count; i++ {
+		id, _ := r.ReadUInt32()
+		r.SkipBytes(bytesPerint32 * 4) //nolint:gomnd // position, chunk ID, chunk start, block start
+		offset, _ := r.ReadUInt32()
+
+		// the loop bounds take precedence over the cue point
+		if marker := v.get(id); marker.Loop == nil {
+			marker.Position = offset
+		}
+	}
+
+	return nil
+}
+
+// parseSmpl parses the loops of a smpl chunk
+func (v *markerList) parseSmpl(body []byte) error {
+	if len(body) < smplHeaderSize {
+		return ErrShortMetadata
+	}
+
+	r := CreateStreamReader(body)
+	r.SetPosition(smplLoopCountAt)
+
+	count, _ := r.ReadUInt32()
+	r.SkipBytes(bytesPerint32) // sampler data size
+
+	if uint64(count)*smplLoopSize > r.Size()-r.Position() {
+		return ErrShortMetadata
+	}
+
+	for i := uint32(0); i < count; i++ {
+		id, _ := r.ReadUInt32()
+		loopType, _ := r.ReadUInt32()
+		start, _ := r.ReadUInt32()
+		end, _ := r.ReadUInt32()
+		fraction, _ := r.ReadUInt32()
+		playCount, _ := r.ReadUInt32()
+
+		marker := v.get(id)
+		marker.Position = start
+		marker.Length = 0
+		marker.Loop = &SampleLoop{Type: LoopType(loopType), Fraction: fraction, PlayCount: playCount}
+
+		// the loop end is inclusive
+		if end >= start {
+			marker.Length = end - start + 1
+		}
+	}
+
+	return nil
+}
+
+// parseAdtl parses the sub chunks of a LIST adtl chunk, following the list type
+func (v *markerList) parseAdtl(body []byte) error {
+	r := CreateStreamReader(body)
+
+	for !r.EOF() {
+		id, err := r.ReadFourCC()
+		if err != nil {
+			return ErrShortMetadata
+		}
+
+		size, err := r.ReadUInt32()
+		if err != nil {
+			return ErrShortMetadata
+		}
+
+		data, err := r.ReadBytes(int(size))
+		if err != nil || len(data) < bytesPerint32 {
+			return ErrShortMetadata
+		}
+
+		if pad := chunkPadding(int64(size)); pad != 0 {
+			next := r.Position() + uint64(pad)
+			if next >= r.Size() || isChunkID(body[next:]) || !isChunkID(body[r.Position():]) {
+				r.SkipBytes(int(pad))
+			}
+		}
+
+		sub := CreateStreamReader(data)
+		cueID, _ := sub.ReadUInt32()
+		marker := v.get(cueID)
+
+		switch id {
+		case ChunkLabl:
+			marker.Label, _ = sub.ReadFixedString(len(data) - bytesPerint32)
+		case ChunkNote:
+			marker.Note, _ = sub.ReadFixedString(len(data) - bytesPerint32)
+		case ChunkLtxt:
+			if len(data) < ltxtHeaderSize {
+				return ErrShortMetadata
+			}
+
+			marker.Length, _ = sub.ReadUInt32()
+			purpose, _ := sub.ReadFourCC()
+			marker.Purpose = FourCC(purpose).String()
+			sub.SetPosition(ltxtHeaderSize)
+			marker.Text, _ = sub.ReadFixedString(len(data) - ltxtHeaderSize)
+		}
+	}
+
+	return nil
+}
+
+// marshalCue returns the body of a cue chunk holding a cue point per marker
+func marshalCue(markers []Marker) []byte {
+	w := CreateStreamWriter()
+
+	w.PushUint32(uint32(len(markers)))
+
+	for _, marker := range markers {
+		w.PushUint32(marker.ID)
+		w.PushUint32(marker.Position)
+		w.PushFourCC(ChunkData)
+		w.PushUint32(0)
+		w.PushUint32(0)
+		w.PushUint32(marker.Position)
+	}
+
+	return w.GetBytes()
+}
+
+// marshalAdtl returns the body of a LIST adtl chunk holding the labels, notes and
+// labelled texts of the markers, or nil if there are none
+func marshalAdtl(markers []Marker) []byte {
+	w := CreateStreamWriter()
+
+	w.PushFourCC(ChunkAdtl)
+
+	for _, marker := range markers {
+		if marker.Label != "" {
+			pushChunk(w, ChunkLabl, marshalCueText(marker.ID, nil, marker.Label))
+		}
+
+		if marker.Note != "" {
+			pushChunk(w, ChunkNote, marshalCueText(marker.ID, nil, marker.Note))
+		}
+
+		if marker.Length == 0 && marker.Purpose == "" && marker.Text == "" {
+			continue
+		}
+
+		purpose := []byte(marker.Purpose + "    ")[:bytesPerint32]
+		if marker.Purpose == "" {
+			purpose = []byte(defaultLtxtPurpose)
+		}
+
+		header := CreateStreamWriter()
+		header.PushUint32(marker.Length)
+		header.PushBytes(purpose...)
+		header.PushUint64(0) // country, language, dialect and code page
+
+		pushChunk(w, ChunkLtxt, marshalCueText(marker.ID, header.GetBytes(), marker.Text))
+	}
+
+	if w.Len() == bytesPerint32 {
+		return nil
+	}
+
+	return w.GetBytes()
+}
+
+// marshalSmpl returns the body of a smpl chunk holding the loops of the markers, or
+// nil if there are none. unityNote is the MIDI note played back at the original pitch.
+func marshalSmpl(markers []Marker, sampleRate uint32, unityNote uint8) []byte {
+	var loops []Marker
+
+	for _, marker := range markers {
+		if marker.Loop != nil {
+			loops = append(loops, marker)
+		}
+	}
+
+	if len(loops) == 0 {
+		return nil
+	}
+
+	var period uint32
+	if sampleRate > 0 {
+		period = uint32(nanosPerSecond / float64(sampleRate))
+	}
+
+	w := CreateStreamWriterSize(smplHeaderSize + len(loops)*smplLoopSize)
+
+	w.PushUint32(0) // manufacturer
+	w.PushUint32(0) // product
+	w.PushUint32(period)
+	w.PushUint32(uint32(unityNote))
+	w.PushUint32(0) // pitch fraction
+	w.PushUint32(0) // SMPTE format
+	w.PushUint32(0) // SMPTE offset
+	w.PushUint32(uint32(len(loops)))
+	w.PushUint32(0) // sampler data size
+
+	for _, marker := range loops {
+		end := marker.Position
+		if marker.Length > 0 {
+			end += marker.Length - 1
+		}
+
+		w.PushUint32(marker.ID)
+		w.PushUint32(uint32(marker.Loop.Type))
+		w.PushUint32(marker.Position)
+		w.PushUint32(end)
+		w.PushUint32(marker.Loop.Fraction)
+		w.PushUint32(marker.Loop.PlayCount)
+	}
+
+	return w.GetBytes()
+}
+
+// marshalCueText returns a cue point ID, fixed fields and a NUL terminated text
+func marshalCueText(id uint32, fields []byte, text string) []byte {
+	w := CreateStreamWriter()
+
+	w.PushUint32(id)
+	w.PushBytes(fields...)
+
+	if text != "" {
+		w.PushCString(text)
+	}
+
+	return w.GetBytes()
+}
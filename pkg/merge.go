@@ -0,0 +1,121 @@
+package pkg
+
+import (
+	"errors"
+	"fmt"
+	"io"
+)
+
+// Errors returned by Merge
+var (
+	ErrNoInputs           = errors.New("no inputs to merge")
+	ErrSampleRateMismatch = errors.New("inputs have different sample rates")
+	ErrLengthMismatch     = errors.New("inputs have different lengths")
+	ErrChannelSource      = errors.New("channel source doesn't exist")
+)
+
+// ChannelSource selects a channel of one of the inputs of Merge
+type ChannelSource struct {
+	Input   int
+	Channel int
+}
+
+// mergeInput is a decoded input of Merge
+type mergeInput struct {
+	samples  []int16
+	channels int
+	frames   int
+}
+
+// Merge combines the audio of several inputs, each a WAV file or compressed MPQ sector,
+// into one multichannel WAV file. order lists the source of each output channel; if
+// it's nil every channel of every input is used in turn. The inputs must share a
+// sample rate unless WithSampleRate resamples them, and must be the same length
+// unless WithPadding pads the shorter ones with silence.
+func Merge(dst io.Writer, inputs []io.Reader, order []ChannelSource, opts ...Option) error {
+	options := applyOptions(opts)
+
+	if len(inputs) == 0 {
+		return ErrNoInputs
+	}
+
+	decoded := make([]mergeInput, len(inputs))
+	sampleRate := options.sampleRate
+	frames := 0
+
+	for i, input := range inputs {
+		data, err := io.ReadAll(input)
+		if err != nil {
+			return err
+		}
+
+		samples, format, _, err := decodeInput(data, options)
+		if err != nil {
+			return fmt.Errorf("input %d: %w", i, err)
+		}
+
+		channels := int(format.Channels)
+
+		switch {
+		case options.sampleRate > 0:
+			if samples, err = options.resample(samples, channels, int(format.SampleRate), options.sampleRate); err != nil {
+				return fmt.Errorf("input %d: %w", i, err)
+			}
+		case i == 0:
+			sampleRate = int(format.SampleRate)
+		case int(format.SampleRate) != sampleRate:
+			return fmt.Errorf("%w: input %d is %d Hz, not %d Hz", ErrSampleRateMismatch, i, format.SampleRate, sampleRate)
+		}
+
+		decoded[i] = mergeInput{samples: samples, channels: channels, frames: len(samples) / channels}
+
+		if i > 0 && decoded[i].frames != frames && !options.padding {
+			return fmt.Errorf("%w: input %d has %d frames, not %d", ErrLengthMismatch, i, decoded[i].frames, frames)
+		}
+
+		if decoded[i].frames > frames {
+			frames = decoded[i].frames
+		}
+	}
+
+	if order == nil {
+		for i, input := range decoded {
+			for ch := 0; ch < input.channels; ch++ {
+				order = append(order, ChannelSource{Input: i, Channel: ch})
+			}
+		}
+	}
+
+	for _, source := range order {
+		if source.Input < 0 || source.Input >= len(decoded) ||
+			source.Channel < 0 || source.Channel >= decoded[source.Input].channels {
+			return fmt.Errorf("%w: input %d channel %d", ErrChannelSource, source.Input, source.Channel)
+		}
+	}
+
+	output := make([]int16, frames*len(order))
+
+	for ch, source := range order {
+		input := decoded[source.Input]
+
+		for frame := 0; frame < input.frames; frame++ {
+			output[frame*len(order)+ch] = input.samples[frame*input.channels+source.Channel]
+		}
+	}
+
+	bitsPerSample := d2BitsPerSample
+	if options.bitsPerSample > 0 {
+		bitsPerSample = options.bitsPerSample
+	}
+
+	encoder, err := CreateEncoder(dst, sampleRate, bitsPerSample, len(order))
+	if err != nil {
+		return err
+	}
+
+	if err = encoder.WriteSamples(output); err != nil {
+		return err
+	}
+
+	return encoder.Close()
+}
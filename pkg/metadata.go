@@ -0,0 +1,378 @@
+package pkg
+
+import (
+	"sort"
+)
+
+// INFO tag IDs read and written by the Metadata accessors
+const (
+	TagTitle     = "INAM"
+	TagArtist    = "IART"
+	TagAlbum     = "IPRD"
+	TagComment   = "ICMT"
+	TagGenre     = "IGNR"
+	TagCopyright = "ICOP"
+	TagDate      = "ICRD"
+	TagSoftware  = "ISFT"
+)
+
+// Metadata gathers the metadata most files carry: the text tags of the LIST INFO
+// chunk, the bext chunk, and the cue points, regions and loops of the cue, adtl and
+// smpl chunks. A Decoder collects it from a file and an Encoder writes it back, so
+// common edits don't need the chunks themselves.
+type Metadata struct {
+	// Tags holds the text of the LIST INFO chunk, keyed by ID such as INAM or IART
+	Tags map[string]string
+	// Bext is the bext chunk, or nil if there is none
+	Bext *Bext
+	// Markers are the cue points, regions and loops
+	Markers []Marker
+}
+
+// IsEmpty reports whether there is no metadata to write
+func (v *Metadata) IsEmpty() bool {
+	return len(v.Tags) == 0 && v.Bext == nil && len(v.Markers) == 0
+}
+
+// Clone returns a deep copy of the metadata
+func (v *Metadata) Clone() *Metadata {
+	result := &Metadata{Markers: make([]Marker, len(v.Markers))}
+
+	for id, text := range v.Tags {
+		result.SetTag(id, text)
+	}
+
+	if v.Bext != nil {
+		bext := *v.Bext
+		result.Bext = &bext
+	}
+
+	for i, marker := range v.Markers {
+		if marker.Loop != nil {
+			loop := *marker.Loop
+			marker.Loop = &loop
+		}
+
+		result.Markers[i] = marker
+	}
+
+	return result
+}
+
+// Tag returns the text of the INFO tag with the given ID, or "" if it isn't set
+func (v *Metadata) Tag(id string) string {
+	return v.Tags[id]
+}
+
+// SetTag sets the text of the INFO tag with the given ID, or removes it if text is ""
+func (v *Metadata) SetTag(id, text string) {
+	if text == "" {
+		delete(v.Tags, id)
+		return
+	}
+
+	if v.Tags == nil {
+		v.Tags = map[string]string{}
+	}
+
+	v.Tags[id] = text
+}
+
+// Title returns the INAM tag
+func (v *Metadata) Title() string { return v.Tag(TagTitle) }
+
+// SetTitle sets the INAM tag
+func (v *Metadata) SetTitle(title string) { v.SetTag(TagTitle, title) }
+
+// Artist returns the IART tag
+func (v *Metadata) Artist() string { return v.Tag(TagArtist) }
+
+// SetArtist sets the IART tag
+func (v *Metadata) SetArtist(artist string) { v.SetTag(TagArtist, artist) }
+
+// Album returns the IPRD tag, naming the product the audio belongs to
+func (v *Metadata) Album() string { return v.Tag(TagAlbum) }
+
+// SetAlbum sets the IPRD tag
+func (v *Metadata) SetAlbum(album string) { v.SetTag(TagAlbum, album) }
+
+// Comment returns the ICMT tag
+func (v *Metadata) Comment() string { return v.Tag(TagComment) }
+
+// SetComment sets the ICMT tag
+func (v *Metadata) SetComment(comment string) { v.SetTag(TagComment, comment) }
+
+// Genre returns the IGNR tag
+func (v *Metadata) Genre() string { return v.Tag(TagGenre) }
+
+// SetGenre sets the IGNR tag
+func (v *Metadata) SetGenre(genre string) { v.SetTag(TagGenre, genre) }
+
+// Copyright returns the ICOP tag
+func (v *Metadata) Copyright() string { return v.Tag(TagCopyright) }
+
+// SetCopyright sets the ICOP tag
+func (v *Metadata) SetCopyright(copyright string) { v.SetTag(TagCopyright, copyright) }
+
+// Date returns the ICRD tag, the creation date, usually formatted as yyyy-mm-dd
+func (v *Metadata) Date() string { return v.Tag(TagDate) }
+
+// SetDate sets the ICRD tag
+func (v *Metadata) SetDate(date string) { v.SetTag(TagDate, date) }
+
+// Software returns the ISFT tag, naming the software that created the file
+func (v *Metadata) Software() string { return v.Tag(TagSoftware) }
+
+// SetSoftware sets the ISFT tag
+func (v *Metadata) SetSoftware(software string) { v.SetTag(TagSoftware, software) }
+
+// Description returns the description of the bext chunk, or "" if there is none
+func (v *Metadata) Description() string {
+	if v.Bext == nil {
+		return ""
+	}
+
+	return v.Bext.Description
+}
+
+// SetDescription sets the description of the bext chunk, adding one if needed
+func (v *Metadata) SetDescription(description string) {
+	v.bext().Description = description
+}
+
+// Originator returns the originator of the bext chunk, or "" if there is none
+func (v *Metadata) Originator() string {
+	if v.Bext == nil {
+		return ""
+	}
+
+	return v.Bext.Originator
+}
+
+// SetOriginator sets the originator of the bext chunk, adding one if needed
+func (v *Metadata) SetOriginator(originator string) {
+	v.bext().Originator = originator
+}
+
+// TimeReference returns the position of the first sample in sample frames since
+// midnight from the bext chunk, or 0 if there is none
+func (v *Metadata) TimeReference() uint64 {
+	if v.Bext == nil {
+		return 0
+	}
+
+	return v.Bext.TimeReference
+}
+
+// SetTimeReference sets the time reference of the bext chunk, adding one if needed
+func (v *Metadata) SetTimeReference(position uint64) {
+	v.bext().TimeReference = position
+}
+
+// bext returns the bext chunk, adding an empty one if there is none
+func (v *Metadata) bext() *Bext {
+	if v.Bext == nil {
+		v.Bext = &Bext{}
+	}
+
+	return v.Bext
+}
+
+// Cues returns the markers which aren't loops: cue points and regions
+func (v *Metadata) Cues() []Marker {
+	var result []Marker
+
+	for _, marker := range v.Markers {
+		if marker.Loop == nil {
+			result = append(result, marker)
+		}
+	}
+
+	return result
+}
+
+// Loops returns the markers which are smpl loops
+func (v *Metadata) Loops() []Marker {
+	var result []Marker
+
+	for _, marker := range v.Markers {
+		if marker.Loop != nil {
+			result = append(result, marker)
+		}
+	}
+
+	return result
+}
+
+// AddMarker adds a marker, giving it an unused cue point ID if its ID is 0, and
+// returns its ID
+func (v *Metadata) AddMarker(marker Marker) uint32 {
+	if marker.ID == 0 {
+		for _, other := range v.Markers {
+			if other.ID >= marker.ID {
+				marker.ID = other.ID + 1
+			}
+		}
+
+		if marker.ID == 0 {
+			marker.ID = 1
+		}
+	}
+
+	v.Markers = append(v.Markers, marker)
+
+	return marker.ID
+}
+
+// AddCue adds a cue point at the given sample frame, and returns its ID
+func (v *Metadata) AddCue(position uint32, label string) uint32 {
+	return v.AddMarker(Marker{Position: position, Label: label})
+}
+
+// AddLoop adds a loop of length sample frames starting at the given sample frame,
+// and returns its ID
+func (v *Metadata) AddLoop(start, length uint32, loopType LoopType) uint32 {
+	return v.AddMarker(Marker{Position: start, Length: length, Loop: &SampleLoop{Type: loopType}})
+}
+
+// RemoveMarker removes the marker with the given cue point ID, reporting whether
+// there was one
+func (v *Metadata) RemoveMarker(id uint32) bool {
+	for i, marker := range v.Markers {
+		if marker.ID == id {
+			v.Markers = append(v.Markers[:i], v.Markers[i+1:]...)
+			return true
+		}
+	}
+
+	return false
+}
+
+// metadataOf returns the metadata of a WAV file, or nil if it has none or its
+// headers can't be parsed
+func metadataOf(riff []byte) *Metadata {
+	decoder, err := CreateDecoder(riff)
+	if err != nil {
+		return nil
+	}
+
+	return decoder.Metadata()
+}
+
+// rescale returns a copy of the metadata with its marker positions and lengths and
+// the bext time reference converted from one sample rate to another
+func (v *Metadata) rescale(from, to int) *Metadata {
+	result := v.Clone()
+	if from == to || from <= 0 {
+		return result
+	}
+
+	for i := range result.Markers {
+		marker := &result.Markers[i]
+		marker.Position = rescalePosition(marker.Position, from, to)
+		marker.Length = rescalePosition(marker.Length, from, to)
+	}
+
+	if result.Bext != nil {
+		// the product fits 64 bits for any time of day at any 32-bit rate
+		result.Bext.TimeReference = result.Bext.TimeReference * uint64(to) / uint64(from)
+	}
+
+	return result
+}
+
+// slice returns a copy of the metadata for the sample frames from start to end, with
+// the markers outside them dropped and the rest and the bext time reference moved to
+// count from start, which may be negative when silence is added ahead. It returns nil
+// for nil metadata.
+func (v *Metadata) slice(start, end int64) *Metadata {
+	if v == nil {
+		return nil
+	}
+
+	result := v.Clone()
+	markers := result.Markers
+	result.Markers = markers[:0]
+
+	for _, marker := range markers {
+		position := int64(marker.Position)
+		if position < start || position >= end {
+			continue
+		}
+
+		marker.Position = uint32(position - start)
+		if tail := end - position; int64(marker.Length) > tail {
+			marker.Length = uint32(tail)
+		}
+
+		result.Markers = append(result.Markers, marker)
+	}
+
+	if result.Bext != nil {
+		if reference := int64(result.Bext.TimeReference) + start; reference > 0 {
+			result.Bext.TimeReference = uint64(reference)
+		} else {
+			result.Bext.TimeReference = 0
+		}
+	}
+
+	return result
+}
+
+// parseInfo parses the tags of a LIST INFO chunk body following the list type
+func parseInfo(body []byte) (map[string]string, error) {
+	result := map[string]string{}
+	r := CreateStreamReader(body)
+
+	for !r.EOF() {
+		id, err := r.ReadFourCC()
+		if err != nil {
+			return result, ErrShortMetadata
+		}
+
+		size, err := r.ReadUInt32()
+		if err != nil {
+			return result, ErrShortMetadata
+		}
+
+		text, err := r.ReadBytes(int(size))
+		if err != nil {
+			return result, ErrShortMetadata
+		}
+
+		if pad := chunkPadding(int64(size)); pad != 0 {
+			next := r.Position() + uint64(pad)
+			if next >= r.Size() || isChunkID(body[next:]) || !isChunkID(body[r.Position():]) {
+				r.SkipBytes(int(pad))
+			}
+		}
+
+		result[FourCC(id).String()] = trimString(text)
+	}
+
+	return result, nil
+}
+
+// marshalInfo returns the body of a LIST INFO chunk holding the tags in ID order,
+// each NUL terminated, or nil if there are none
+func marshalInfo(tags map[string]string) []byte {
+	if len(tags) == 0 {
+		return nil
+	}
+
+	ids := make([]string, 0, len(tags))
+	for id := range tags {
+		ids = append(ids, id)
+	}
+
+	sort.Strings(ids)
+
+	w := CreateStreamWriter()
+	w.PushFourCC(ChunkINFO)
+
+	for _, id := range ids {
+		pushChunk(w, CreateFourCC(id), append([]byte(tags[id]), 0))
+	}
+
+	return w.GetBytes()
+}
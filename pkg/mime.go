@@ -0,0 +1,53 @@
+package pkg
+
+import (
+	"fmt"
+)
+
+const defaultMIMEType = "application/octet-stream"
+
+// kindMediaTypes holds the MIME type and canonical file extension of each kind
+//
+//nolint:gochecknoglobals // lookup table
+var kindMediaTypes = map[Kind][2]string{
+	KindRIFF:   {"audio/wav", ".wav"},
+	KindRIFX:   {"audio/wav", ".wav"},
+	KindRF64:   {"audio/wav", ".wav"},
+	KindWave64: {"audio/x-w64", ".w64"},
+	KindAIFF:   {"audio/aiff", ".aiff"},
+	KindAU:     {"audio/basic", ".au"},
+	KindADPCM:  {defaultMIMEType, ".bin"},
+}
+
+// MIMEType returns the MIME type to serve data of the kind with, falling back to
+// application/octet-stream for unknown data
+func (v Kind) MIMEType() string {
+	if media, ok := kindMediaTypes[v]; ok {
+		return media[0]
+	}
+
+	return defaultMIMEType
+}
+
+// FileExtension returns the canonical file extension of the kind, including the dot, or
+// an empty string for unknown data
+func (v Kind) FileExtension() string {
+	return kindMediaTypes[v][1]
+}
+
+// MIMEType returns the MIME type of a WAV file holding audio in the format. Formats
+// browsers play, integer and float PCM, are audio/wav; others name their format tag
+// as the codec parameter of RFC 2361, such as "audio/vnd.wave; codec=55" for MP3.
+func (v Format) MIMEType() string {
+	if tag := v.effectiveTag(); v.validateSamples() == nil && tag != FormatMuLaw && tag != FormatALaw {
+		return kindMediaTypes[KindRIFF][0]
+	}
+
+	return fmt.Sprintf("audio/vnd.wave; codec=%x", v.effectiveTag())
+}
+
+// FileExtension returns the canonical file extension of a WAV file holding audio in the
+// format, including the dot
+func (v Format) FileExtension() string {
+	return KindRIFF.FileExtension()
+}
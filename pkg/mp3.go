@@ -0,0 +1,107 @@
+package pkg
+
+import (
+	"bytes"
+	"errors"
+	"io"
+)
+
+const (
+	mp3FormatSize      = 12
+	mpegLayer3         = 4 // ACM_MPEG_LAYER3 in the fwHeadLayer field of MPEG1WAVEFORMAT
+	mp3FrameHeaderSize = 4
+	mp3LayerIII        = 1
+	mp3ReservedVersion = 1
+	mp3BadBitrate      = 15
+	mp3ReservedRate    = 3
+	id3Prefix          = "ID3"
+	mp3FrameSync       = 0xE0
+)
+
+// ErrNotMP3 is returned when a WAV file doesn't hold MPEG Layer-3 audio
+var ErrNotMP3 = errors.New("not MPEG Layer-3 audio")
+
+// MP3Format holds the MPEGLAYER3WAVEFORMAT fields following the WAVEFORMATEX of
+// format 0x55
+type MP3Format struct {
+	ID             uint16
+	Flags          uint32
+	BlockSize      uint16
+	FramesPerBlock uint16
+	CodecDelay     uint16
+}
+
+// MP3 reports whether the format describes MPEG Layer-3 audio, either as format 0x55
+// or as format 0x50 with layer 3 in its MPEG1WAVEFORMAT fields, and returns the
+// MPEGLAYER3WAVEFORMAT fields of format 0x55 when present
+func (v Format) MP3() (MP3Format, bool) {
+	var result MP3Format
+
+	switch v.effectiveTag() {
+	case FormatMPEG:
+		return result, len(v.Extension) >= bytesPerint16 && v.Extension[0] == mpegLayer3 && v.Extension[1] == 0
+	case FormatMPEGLayer3:
+	default:
+		return result, false
+	}
+
+	if v.FormatTag == FormatMPEGLayer3 && len(v.Extension) >= mp3FormatSize {
+		r := CreateStreamReader(v.Extension)
+		result.ID, _ = r.ReadUInt16()
+		result.Flags, _ = r.ReadUInt32()
+		result.BlockSize, _ = r.ReadUInt16()
+		result.FramesPerBlock, _ = r.ReadUInt16()
+		result.CodecDelay, _ = r.ReadUInt16()
+	}
+
+	return result, true
+}
+
+// isMP3Frame reports whether b starts with an ID3v2 tag or a valid MPEG Layer-3 frame
+// header, which identifies MP3 payloads stored under a generic MPEG format
+func isMP3Frame(b []byte) bool {
+	if bytes.HasPrefix(b, []byte(id3Prefix)) {
+		return true
+	}
+
+	if len(b) < mp3FrameHeaderSize || b[0] != 0xFF || b[1]&mp3FrameSync != mp3FrameSync {
+		return false
+	}
+
+	version := b[1] >> 3 & 3 //nolint:gomnd // version ID bits
+	layer := b[1] >> 1 & 3   //nolint:gomnd // layer bits
+	bitrate := b[2] >> 4     //nolint:gomnd // bitrate index bits
+	rate := b[2] >> 2 & 3    //nolint:gomnd // sample rate index bits
+
+	return version != mp3ReservedVersion && layer == mp3LayerIII && bitrate != mp3BadBitrate && rate != mp3ReservedRate
+}
+
+// ExtractMP3 returns the MPEG Layer-3 elementary stream held in the data chunk of a
+// WAV file, which can be played or decoded as a plain MP3 file. Files of format
+// 0x50 without layer information are recognised by the first frame of the payload.
+func ExtractMP3(data []byte) ([]byte, error) {
+	format, payload, err := ExtractRaw(data)
+	if err != nil {
+		return nil, err
+	}
+
+	if _, ok := format.MP3(); ok {
+		return payload, nil
+	}
+
+	if format.effectiveTag() == FormatMPEG && isMP3Frame(payload) {
+		return payload, nil
+	}
+
+	return nil, ErrNotMP3
+}
+
+// MP3Stream returns a reader over the MPEG Layer-3 elementary stream of the data
+// chunk, for decoders created with WithRawData or with an MP3 codec registered
+func (v *Decoder) MP3Stream() (io.Reader, error) {
+	if _, ok := v.format.MP3(); !ok {
+		return nil, ErrNotMP3
+	}
+
+	return v.RawReader(), nil
+}
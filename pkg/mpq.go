@@ -0,0 +1,178 @@
+package pkg
+
+import (
+	"bytes"
+	"compress/bzip2"
+	"compress/zlib"
+	"errors"
+	"fmt"
+	"io"
+)
+
+// MPQ sector compression flags
+const (
+	CompressionHuffman     = 0x01
+	CompressionZlib        = 0x02
+	CompressionPKWare      = 0x08
+	CompressionBZip2       = 0x10
+	CompressionADPCMMono   = 0x40
+	CompressionADPCMStereo = 0x80
+)
+
+// ErrUnsupportedCompression is returned for MPQ compression flags which can't be decompressed
+var ErrUnsupportedCompression = errors.New("unsupported compression")
+
+// supportedCompression holds every compression method MultiDecompress can undo
+const supportedCompression = CompressionHuffman | CompressionZlib | CompressionPKWare | CompressionBZip2 |
+	CompressionADPCMMono | CompressionADPCMStereo
+
+// MultiDecompress decompresses the payload of an MPQ sector which was compressed with
+// every method in mask. The stages are undone in the same order as StormLib does:
+// bzip2, PKWARE explode, zlib, Huffman and finally ADPCM.
+func MultiDecompress(data []byte, mask byte) ([]byte, error) {
+	if unknown := mask &^ supportedCompression; unknown != 0 {
+		return nil, fmt.Errorf("%w: 0x%02x", ErrUnsupportedCompression, unknown)
+	}
+
+	var err error
+
+	if mask&CompressionBZip2 != 0 {
+		if data, err = io.ReadAll(bzip2.NewReader(bytes.NewReader(data))); err != nil {
+			return nil, err
+		}
+	}
+
+	if mask&CompressionPKWare != 0 {
+		if data, err = PKWareDecompress(data); err != nil {
+			return nil, err
+		}
+	}
+
+	if mask&CompressionZlib != 0 {
+		if data, err = zlibDecompress(data); err != nil {
+			return nil, err
+		}
+	}
+
+	if mask&CompressionHuffman != 0 {
+		if data, err = HuffmanDecompressSize(data, 0); err != nil {
+			return nil, err
+		}
+	}
+
+	switch {
+	case mask&CompressionADPCMStereo != 0:
+		return WavDecompress(data, 2) //nolint:gomnd // stereo
+	case mask&CompressionADPCMMono != 0:
+		return WavDecompress(data, 1)
+	}
+
+	return data, nil
+}
+
+// CreateSectorReader creates a reader decompressing an MPQ sector payload which was
+// compressed with every method in mask, like MultiDecompress but streaming each stage,
+// so memory use stays bounded by fixed size buffers whatever the length of the payload
+func CreateSectorReader(r io.Reader, mask byte) (io.Reader, error) {
+	if unknown := mask &^ supportedCompression; unknown != 0 {
+		return nil, fmt.Errorf("%w: 0x%02x", ErrUnsupportedCompression, unknown)
+	}
+
+	if mask&CompressionBZip2 != 0 {
+		r = bzip2.NewReader(r)
+	}
+
+	if mask&CompressionPKWare != 0 {
+		r = CreatePKWareReader(r)
+	}
+
+	if mask&CompressionZlib != 0 {
+		zr, err := zlib.NewReader(r)
+		if err != nil {
+			return nil, err
+		}
+
+		r = zr
+	}
+
+	if mask&CompressionHuffman != 0 {
+		r = CreateHuffmanReader(r)
+	}
+
+	switch {
+	case mask&CompressionADPCMStereo != 0:
+		return CreateADPCMReader(r, 2) //nolint:gomnd // stereo
+	case mask&CompressionADPCMMono != 0:
+		return CreateADPCMReader(r, 1)
+	}
+
+	return r, nil
+}
+
+func zlibDecompress(data []byte) ([]byte, error) {
+	r, err := zlib.NewReader(bytes.NewReader(data))
+	if err != nil {
+		return nil, err
+	}
+
+	defer r.Close() //nolint:errcheck // read only
+
+	return io.ReadAll(r)
+}
+
+// Wave compression qualities, matching StormLib's MPQ_WAVE_QUALITY_* values
+const (
+	WaveQualityHigh   = 0
+	WaveQualityMedium = 1
+	WaveQualityLow    = 2
+)
+
+// wavePreset holds the ADPCM compression level and the huffman compression type
+// StormLib pairs with it
+type wavePreset struct {
+	adpcmLevel  int
+	huffmanType byte
+}
+
+// wavePresets maps wave qualities to the settings StormLib's ADPCM compression stage
+// chooses for the compression level SFileAddWave uses with them (4 and 2)
+//
+//nolint:gochecknoglobals,gomnd // preset table
+var wavePresets = map[int]wavePreset{
+	WaveQualityMedium: {adpcmLevel: ADPCMQualityMedium, huffmanType: 7},
+	WaveQualityLow:    {adpcmLevel: ADPCMQualityLow, huffmanType: 6},
+}
+
+// CompressWave compresses a sector of 16-bit PCM with the settings StormLib's
+// SFileAddWave uses for quality, returning the payload and the compression mask to
+// store in front of it. Medium and low quality use ADPCM followed by huffman coding.
+// High quality is lossless, using PKWARE implode. As with any MPQ writer, a sector
+// which doesn't shrink should be stored uncompressed instead.
+func CompressWave(pcm []byte, channels, quality int) ([]byte, byte, error) {
+	if quality == WaveQualityHigh {
+		imploded, err := PKWareCompress(pcm)
+		return imploded, CompressionPKWare, err
+	}
+
+	preset, ok := wavePresets[quality]
+	if !ok {
+		return nil, 0, fmt.Errorf("%w: wave quality %d", ErrUnsupportedCompression, quality)
+	}
+
+	mask := byte(CompressionHuffman | CompressionADPCMMono)
+	if channels == 2 { //nolint:gomnd // stereo
+		mask = CompressionHuffman | CompressionADPCMStereo
+	}
+
+	adpcm, err := WavCompress(pcm, channels, preset.adpcmLevel)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	compressed, err := HuffmanCompress(adpcm, preset.huffmanType)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	return compressed, mask, nil
+}
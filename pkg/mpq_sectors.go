@@ -0,0 +1,109 @@
+package pkg
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+)
+
+// ErrSectorSize is returned when a sector doesn't decompress to its expected size
+var ErrSectorSize = errors.New("sector decompressed to the wrong size")
+
+// SectorGap is a damaged sector which SalvageSectors replaced with silence
+type SectorGap struct {
+	// Sector is the index of the damaged sector
+	Sector int
+	// Offset is the position of the gap in the decompressed output
+	Offset int64
+	// Size is the number of bytes of silence filling the gap
+	Size int
+	// Err is why the sector couldn't be decompressed
+	Err error
+}
+
+// DecompressSectors decompresses the sectors of a file stored in an MPQ archive and
+// joins them. Every sector decompresses to sectorSize bytes except the last, which
+// holds the rest of fileSize. Like StormLib, a sector whose stored size equals its
+// decompressed size is taken as uncompressed; others start with their compression
+// mask. The first sector which fails to decompress fails the whole file.
+func DecompressSectors(sectors [][]byte, sectorSize, fileSize int) ([]byte, error) {
+	output := make([]byte, 0, fileSize)
+
+	for i, sector := range sectors {
+		data, err := decompressSector(sector, sectorLength(i, sectorSize, fileSize))
+		if err != nil {
+			return nil, fmt.Errorf("sector %d: %w", i, err)
+		}
+
+		output = append(output, data...)
+	}
+
+	return output, nil
+}
+
+// SalvageSectors decompresses sectors like DecompressSectors, but resynchronizes at
+// the next sector when one fails to decompress: the damaged sector is replaced by
+// silence of its expected size and reported as a gap, so the rest of the audio
+// survives a corrupted archive
+func SalvageSectors(sectors [][]byte, sectorSize, fileSize int) ([]byte, []SectorGap) {
+	output := make([]byte, 0, fileSize)
+
+	var gaps []SectorGap
+
+	for i, sector := range sectors {
+		size := sectorLength(i, sectorSize, fileSize)
+
+		data, err := decompressSector(sector, size)
+		if err != nil {
+			gaps = append(gaps, SectorGap{Sector: i, Offset: int64(len(output)), Size: size, Err: err})
+			data = make([]byte, size)
+		}
+
+		output = append(output, data...)
+	}
+
+	return output, gaps
+}
+
+// sectorLength returns the decompressed size of a sector
+func sectorLength(index, sectorSize, fileSize int) int {
+	remaining := fileSize - index*sectorSize
+
+	switch {
+	case remaining < 0:
+		return 0
+	case remaining < sectorSize:
+		return remaining
+	}
+
+	return sectorSize
+}
+
+// decompressSector decompresses one sector, streaming it so that damaged data can't
+// expand beyond the expected size
+func decompressSector(sector []byte, size int) ([]byte, error) {
+	if len(sector) == size {
+		return sector, nil
+	}
+
+	if len(sector) == 0 {
+		return nil, io.ErrUnexpectedEOF
+	}
+
+	r, err := CreateSectorReader(bytes.NewReader(sector[1:]), sector[0])
+	if err != nil {
+		return nil, err
+	}
+
+	data, err := io.ReadAll(io.LimitReader(r, int64(size)+1))
+	if err != nil {
+		return nil, err
+	}
+
+	if len(data) != size {
+		return nil, fmt.Errorf("%w: %d bytes, expected %d", ErrSectorSize, len(data), size)
+	}
+
+	return data, nil
+}
@@ -0,0 +1,187 @@
+package pkg_test
+
+import (
+	"bytes"
+	"io"
+	"math"
+	"math/rand"
+	"runtime"
+	"testing"
+	"time"
+
+	"github.com/gravestench/wav/pkg"
+)
+
+const (
+	testRate      = 22050
+	readChunkSize = 4096
+	// allocationRuns is the number of runs allocation counts are averaged over
+	allocationRuns = 5
+	// the runtime allocates now and then while a test runs, so comparisons allow
+	// for a little more than a single run of the code allocates
+	allocationCountSlack = 1
+	allocationSizeSlack  = 4096
+)
+
+// sineSamples returns d of a 440 Hz sine at half scale as interleaved samples
+func sineSamples(channels int, d time.Duration) []int16 {
+	frames := int(d * testRate / time.Second)
+	result := make([]int16, frames*channels)
+
+	for i := range result {
+		phase := 2 * math.Pi * 440 * float64(i/channels) / testRate
+		result[i] = int16(math.Sin(phase) * math.MaxInt16 / 2)
+	}
+
+	return result
+}
+
+// noiseSamples returns d of white noise at half scale as interleaved samples, the
+// same for every call
+func noiseSamples(channels int, d time.Duration) []int16 {
+	random := rand.New(rand.NewSource(1))
+
+	result := make([]int16, int(d*testRate/time.Second)*channels)
+	for i := range result {
+		result[i] = int16(random.Intn(math.MaxInt16) - math.MaxInt16/2)
+	}
+
+	return result
+}
+
+// samplesToPCM returns samples as little-endian 16-bit PCM
+func samplesToPCM(samples []int16) []byte {
+	result := make([]byte, len(samples)*2)
+	for i, sample := range samples {
+		result[i*2] = byte(sample)
+		result[i*2+1] = byte(uint16(sample) >> 8)
+	}
+
+	return result
+}
+
+// TestCompressWaveRoundTrip decompresses sectors compressed with every wave quality,
+// which must give back as much audio close to the original
+func TestCompressWaveRoundTrip(t *testing.T) {
+	for _, channels := range []int{1, 2} {
+		samples := sineSamples(channels, time.Second/4)
+		pcm := samplesToPCM(samples)
+
+		for _, quality := range []int{pkg.WaveQualityHigh, pkg.WaveQualityMedium, pkg.WaveQualityLow} {
+			payload, mask, err := pkg.CompressWave(pcm, channels, quality)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			got, err := pkg.MultiDecompress(payload, mask)
+			if err != nil {
+				t.Fatalf("%d channels, quality %d: %v", channels, quality, err)
+			}
+
+			if len(got) != len(pcm) {
+				t.Fatalf("%d channels, quality %d: decompressed %d bytes, want %d", channels, quality, len(got), len(pcm))
+			}
+
+			var worst int
+
+			for i, sample := range samples {
+				diff := int(int16(uint16(got[i*2])|uint16(got[i*2+1])<<8)) - int(sample)
+				if diff < 0 {
+					diff = -diff
+				}
+
+				if diff > worst {
+					worst = diff
+				}
+			}
+
+			// ADPCM follows a sine closely once its step size has adapted
+			if worst > math.MaxInt16/64 {
+				t.Errorf("%d channels, quality %d: samples off by up to %d", channels, quality, worst)
+			}
+		}
+	}
+}
+
+// allocations returns the average number of allocations and the bytes allocated by
+// a call of f
+func allocations(f func()) (float64, uint64) {
+	count := testing.AllocsPerRun(allocationRuns, f)
+
+	var before, after runtime.MemStats
+
+	runtime.ReadMemStats(&before)
+	f()
+	runtime.ReadMemStats(&after)
+
+	return count, after.TotalAlloc - before.TotalAlloc
+}
+
+// drain reads r to the end in chunks of readChunkSize bytes, returning the number of
+// bytes read
+func drain(t *testing.T, r io.Reader) int {
+	t.Helper()
+
+	p := make([]byte, readChunkSize)
+	total := 0
+
+	for {
+		n, err := r.Read(p)
+		total += n
+
+		if err == io.EOF {
+			return total
+		}
+
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+// grew reports whether the second of two measurements by allocations exceeds the
+// first by more than the runtime's own allocations explain
+func grew(counts [2]float64, sizes [2]uint64) bool {
+	return counts[1] > counts[0]+allocationCountSlack || sizes[1] > sizes[0]+allocationSizeSlack
+}
+
+// TestSectorReaderBoundedMemory streams sectors many times larger than the buffers
+// of CreateSectorReader through its huffman and ADPCM stages, and checks that a
+// sector 16 times as long allocates about as much as a short one, and less than its
+// size
+func TestSectorReaderBoundedMemory(t *testing.T) {
+	for _, quality := range []int{pkg.WaveQualityMedium, pkg.WaveQualityLow} {
+		var counts [2]float64
+
+		var sizes [2]uint64
+
+		for i, d := range []time.Duration{time.Second, 16 * time.Second} {
+			pcm := samplesToPCM(noiseSamples(2, d))
+
+			payload, mask, err := pkg.CompressWave(pcm, 2, quality)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			counts[i], sizes[i] = allocations(func() {
+				r, err := pkg.CreateSectorReader(bytes.NewReader(payload), mask)
+				if err != nil {
+					t.Fatal(err)
+				}
+
+				if n := drain(t, r); n != len(pcm) {
+					t.Fatalf("quality %d: read %d bytes, want %d", quality, n, len(pcm))
+				}
+			})
+
+			if i == 1 && sizes[i] >= uint64(len(payload)) {
+				t.Errorf("quality %d: allocated %d bytes streaming a %d byte sector", quality, sizes[i], len(payload))
+			}
+		}
+
+		if grew(counts, sizes) {
+			t.Errorf("quality %d: a 16 times longer sector made %v allocations of %d bytes, up from %v of %d",
+				quality, counts[1], sizes[1], counts[0], sizes[0])
+		}
+	}
+}
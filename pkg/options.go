@@ -0,0 +1,170 @@
+package pkg
+
+import (
+	"time"
+)
+
+// Option configures the audio written by Transcode
+type Option func(*options)
+
+type options struct {
+	sampleRate      int
+	bitsPerSample   int
+	channels        int
+	codec           uint16
+	inputSampleRate int
+	inputChannels   int
+	keepMetadata    bool
+	canonicalOrder  bool
+	padding         bool
+	channelMap      ChannelMap
+	layout          Layout
+	passthrough     bool
+	resampler       Resampler
+	noAntiAlias     bool
+	telephony       bool
+	duration        time.Duration
+	hasDuration     bool
+	// metadata is written by encodeOutput, as set by EncodeBuffer
+	metadata *Metadata
+}
+
+func applyOptions(opts []Option) options {
+	result := options{
+		inputSampleRate: D2SampleRate,
+		inputChannels:   1,
+	}
+
+	for _, opt := range opts {
+		opt(&result)
+	}
+
+	return result
+}
+
+// WithSampleRate resamples the output to the given rate
+func WithSampleRate(sampleRate int) Option {
+	return func(o *options) {
+		o.sampleRate = sampleRate
+	}
+}
+
+// WithResampler sets the Resampler used when the output is resampled, replacing the
+// built-in LinearResampler
+func WithResampler(resampler Resampler) Option {
+	return func(o *options) {
+		o.resampler = resampler
+	}
+}
+
+// WithoutAntiAlias skips the low-pass filter the built-in resampler applies when
+// reducing the sample rate
+func WithoutAntiAlias() Option {
+	return func(o *options) {
+		o.noAntiAlias = true
+	}
+}
+
+// WithBitDepth converts the output to 8, 16, 24 or 32 bit PCM
+func WithBitDepth(bitsPerSample int) Option {
+	return func(o *options) {
+		o.bitsPerSample = bitsPerSample
+	}
+}
+
+// WithChannels mixes the output to the given number of channels after any channel
+// mapping. See Remix for how channels are combined or duplicated.
+func WithChannels(channels int) Option {
+	return func(o *options) {
+		o.channels = channels
+	}
+}
+
+// WithCodec writes the output with the given format tag: FormatPCM, FormatIEEEFloat,
+// or FormatMuLaw or FormatALaw for 8-bit G.711. IEEE float output is 32 bit unless
+// WithBitDepth asks for 64.
+func WithCodec(tag uint16) Option {
+	return func(o *options) {
+		o.codec = tag
+	}
+}
+
+// WithDuration pads the end of the output with silence or truncates it, so that it
+// plays for exactly d rounded down to a whole sample frame
+func WithDuration(d time.Duration) Option {
+	return func(o *options) {
+		o.duration = d
+		o.hasDuration = true
+	}
+}
+
+// WithInputSampleRate sets the sample rate of compressed input which decompresses
+// to headerless PCM. It defaults to the Diablo II rate of 22050 Hz.
+func WithInputSampleRate(sampleRate int) Option {
+	return func(o *options) {
+		o.inputSampleRate = sampleRate
+	}
+}
+
+// WithInputChannels sets the number of interleaved channels of compressed input which
+// decompresses to headerless PCM. It defaults to mono; ADPCM sectors give their own
+// channel count in the compression mask.
+func WithInputChannels(channels int) Option {
+	return func(o *options) {
+		o.inputChannels = channels
+	}
+}
+
+// WithMetadata carries the chunks of WAV input other than fmt, fact and data over to
+// the output verbatim, in their original order and keeping their place before the fmt
+// chunk or before or after the data chunk. Cue point and smpl loop positions are
+// rescaled when the output is resampled.
+func WithMetadata() Option {
+	return func(o *options) {
+		o.keepMetadata = true
+	}
+}
+
+// WithCanonicalOrder carries metadata over like WithMetadata, but moves the chunks the
+// Encoder writes itself to where it places them: bext, cart, levl, chna and LIST INFO
+// between the fmt and data chunks, and cue, smpl, inst, acid, axml, dbmd and LIST adtl
+// after the data chunk. Other chunks keep their side of the data chunk, after the fmt chunk.
+func WithCanonicalOrder() Option {
+	return func(o *options) {
+		o.keepMetadata = true
+		o.canonicalOrder = true
+	}
+}
+
+// WithPadding lets Merge combine inputs of different lengths, padding the shorter ones
+// with silence
+func WithPadding() Option {
+	return func(o *options) {
+		o.padding = true
+	}
+}
+
+// WithChannelMap remaps the channels of the output
+func WithChannelMap(channelMap ChannelMap) Option {
+	return func(o *options) {
+		o.channelMap = channelMap
+	}
+}
+
+// WithLayout writes the output as WAVE_FORMAT_EXTENSIBLE with the given speaker layout.
+// Unless WithChannelMap is also given, the channels of the input are mapped to it by
+// speaker position.
+func WithLayout(layout Layout) Option {
+	return func(o *options) {
+		o.layout = layout
+	}
+}
+
+// WithPassthrough lets Transcode resample, remap or change the bit depth of input
+// detected as IEC 61937 or DTS passthrough audio, which otherwise fails with
+// ErrPassthrough as the result would no longer be decodable
+func WithPassthrough() Option {
+	return func(o *options) {
+		o.passthrough = true
+	}
+}
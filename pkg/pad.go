@@ -0,0 +1,64 @@
+package pkg
+
+import (
+	"time"
+)
+
+// PadSamples returns interleaved samples with before and after frames of silence
+// added at either end. A trailing partial frame is dropped.
+func PadSamples(samples []int16, channels, before, after int) []int16 {
+	if channels <= 0 {
+		return samples
+	}
+
+	samples = samples[:len(samples)-len(samples)%channels]
+
+	if before < 0 {
+		before = 0
+	}
+
+	if after < 0 {
+		after = 0
+	}
+
+	result := make([]int16, (before+after)*channels+len(samples))
+	copy(result[before*channels:], samples)
+
+	return result
+}
+
+// FitSamples returns interleaved samples padded with silence or truncated to exactly
+// frames sample frames
+func FitSamples(samples []int16, channels, frames int) []int16 {
+	if channels <= 0 {
+		return samples
+	}
+
+	if frames < 0 {
+		frames = 0
+	}
+
+	if want := frames * channels; want <= len(samples) {
+		return samples[:want]
+	}
+
+	return PadSamples(samples, channels, 0, frames-len(samples)/channels)
+}
+
+// Pad adds before and after worth of silence at the start and end of the buffer,
+// moving its markers along with the audio
+func (v *Buffer) Pad(before, after time.Duration) {
+	rate := int64(v.SampleRate)
+	frames := durationToFrames(before, rate)
+
+	v.Samples = PadSamples(v.Samples, v.Channels, int(frames), int(durationToFrames(after, rate)))
+	v.Metadata = v.Metadata.slice(-frames, int64(v.Frames()))
+}
+
+// SetDuration pads the end of the buffer with silence or truncates it, so that it
+// plays for exactly d rounded down to a whole sample frame. Markers past the end
+// are dropped.
+func (v *Buffer) SetDuration(d time.Duration) {
+	v.Samples = FitSamples(v.Samples, v.Channels, int(durationToFrames(d, int64(v.SampleRate))))
+	v.Metadata = v.Metadata.slice(0, int64(v.Frames()))
+}
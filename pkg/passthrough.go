@@ -0,0 +1,153 @@
+package pkg
+
+import (
+	"errors"
+	"io"
+)
+
+const (
+	iec61937SyncA        = 0xF872
+	iec61937SyncB        = 0x4E1F
+	iec61937DataTypeMask = 0x1F
+	passthroughScanBytes = 64 * 1024
+	passthroughMinBursts = 2
+)
+
+// ErrPassthrough is returned when processing would alter compressed audio carried as
+// IEC 61937 bursts or raw DTS in a PCM data chunk, which is no longer playable once
+// its bits change
+var ErrPassthrough = errors.New("data chunk carries compressed passthrough audio")
+
+// Passthrough describes compressed audio disguised as 16-bit PCM, as written for
+// playback through S/PDIF and HDMI receivers
+type Passthrough struct {
+	// Codec names the compressed format, such as "AC-3" or "DTS"
+	Codec string
+	// DataType is the IEC 61937 burst data type, or 0 for raw DTS
+	DataType uint8
+	// Offset is the index of the sample holding the first sync word
+	Offset int
+}
+
+// iec61937DataTypes names the IEC 61937 burst data types
+//
+//nolint:gochecknoglobals // lookup table
+var iec61937DataTypes = map[uint8]string{
+	1:  "AC-3",
+	4:  "MPEG-1 Layer 1",
+	5:  "MPEG-1 Layer 2/3",
+	6:  "MPEG-2",
+	7:  "MPEG-2 AAC",
+	8:  "MPEG-2 Layer 1 LSF",
+	9:  "MPEG-2 Layer 2/3 LSF",
+	11: "DTS",
+	12: "DTS",
+	13: "DTS",
+	17: "DTS-HD",
+	21: "E-AC-3",
+	22: "TrueHD",
+}
+
+// dtsSyncWords are the first two 16-bit words of a raw DTS frame, in the 16 and 14
+// bit packings and both byte orders
+//
+//nolint:gochecknoglobals // lookup table
+var dtsSyncWords = [][2]uint16{
+	{0x7FFE, 0x8001},
+	{0xFE7F, 0x0180},
+	{0x1FFF, 0xE800},
+	{0xFF1F, 0x00E8},
+}
+
+// DetectPassthrough reports whether interleaved 16-bit samples carry IEC 61937 bursts
+// or raw DTS frames rather than audio. At least two sync words must be present, so
+// a few seconds of PCM are needed to recognise formats with long bursts like E-AC-3.
+func DetectPassthrough(samples []int16) (Passthrough, bool) {
+	var result Passthrough
+
+	found := 0
+
+	for i := 0; i+1 < len(samples); i++ {
+		a, b := uint16(samples[i]), uint16(samples[i+1])
+
+		codec, dataType := "", uint8(0)
+
+		if a == iec61937SyncA && b == iec61937SyncB {
+			if i+2 >= len(samples) {
+				break
+			}
+
+			dataType = uint8(samples[i+2]) & iec61937DataTypeMask
+			codec = iec61937DataTypes[dataType]
+
+			if codec == "" {
+				// null and pause bursts pad the stream between frames
+				continue
+			}
+		} else if codec = dtsCodec(a, b); codec == "" {
+			continue
+		}
+
+		if found == 0 {
+			result = Passthrough{Codec: codec, DataType: dataType, Offset: i}
+		}
+
+		found++
+
+		if found == passthroughMinBursts {
+			return result, true
+		}
+
+		i++
+	}
+
+	return result, false
+}
+
+// dtsCodec returns "DTS" if a and b are the sync words of a raw DTS frame
+func dtsCodec(a, b uint16) string {
+	for _, sync := range dtsSyncWords {
+		if a == sync[0] && b == sync[1] {
+			return "DTS"
+		}
+	}
+
+	return ""
+}
+
+// isPassthroughCandidate reports whether the format is the 16-bit PCM that IEC 61937
+// and DTS CD streams are disguised as
+func (v Format) isPassthroughCandidate() bool {
+	return v.effectiveTag() == FormatPCM && v.BitsPerSample == d2BitsPerSample
+}
+
+// Passthrough reads the start of the data chunk and reports whether it carries
+// compressed passthrough audio, leaving the read position unchanged. The source must
+// be seekable, which it is for decoders created from a byte slice or file.
+func (v *Decoder) Passthrough() (Passthrough, bool, error) {
+	if !v.format.isPassthroughCandidate() {
+		return Passthrough{}, false, nil
+	}
+
+	if v.seeker == nil {
+		return Passthrough{}, false, ErrNotSeekable
+	}
+
+	position := v.position
+	v.position = 0
+
+	data, err := v.readData(passthroughScanBytes, bytesPerint16)
+	v.position = position
+
+	if errors.Is(err, io.EOF) {
+		return Passthrough{}, false, nil
+	}
+
+	if err != nil {
+		return Passthrough{}, false, err
+	}
+
+	result, ok := DetectPassthrough(bytesToSamples(data))
+
+	return result, ok, nil
+}
@@ -0,0 +1,40 @@
+package pkg
+
+import (
+	"io"
+
+	"github.com/gravestench/wav/pkg/pkware"
+)
+
+// dictionary sizes StormLib implodes sectors of up to pkwareSmallSector and
+// pkwareMediumSector bytes with
+const (
+	pkwareSmallSector  = 0x600
+	pkwareMediumSector = 0xC00
+)
+
+// PKWareDecompress decompresses data imploded with the PKWARE Data Compression Library
+func PKWareDecompress(data []byte) ([]byte, error) {
+	return pkware.Explode(data)
+}
+
+// PKWareCompress implodes data with the PKWARE Data Compression Library, using binary
+// literals and the dictionary size StormLib chooses for a sector of its length
+func PKWareCompress(data []byte) ([]byte, error) {
+	dictionarySize := pkware.DictionarySize4096
+
+	switch {
+	case len(data) < pkwareSmallSector:
+		dictionarySize = pkware.DictionarySize1024
+	case len(data) < pkwareMediumSector:
+		dictionarySize = pkware.DictionarySize2048
+	}
+
+	return pkware.Implode(data, dictionarySize)
+}
+
+// CreatePKWareReader creates a reader decompressing the PKWARE imploded data read from
+// r, like PKWareDecompress but streaming
+func CreatePKWareReader(r io.Reader) io.Reader {
+	return pkware.CreateReader(r)
+}
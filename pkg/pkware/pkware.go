@@ -0,0 +1,326 @@
+// Package pkware implements the PKWARE Data Compression Library (DCL) implode format,
+// which MPQ archives use as one of their sector compression methods
+package pkware
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+
+	"github.com/gravestench/wav/pkg/bitio"
+)
+
+// Dictionary sizes, the distance a match can reach back
+const (
+	DictionarySize1024 = 1024
+	DictionarySize2048 = 2048
+	DictionarySize4096 = 4096
+)
+
+const (
+	bitsPerByte = 8
+	// literalsBinary and literalsASCII are the literal modes of the header: stored as
+	// is, or huffman coded with a table suited to text
+	literalsBinary = 0
+	literalsASCII  = 1
+	// dictionary sizes are stored as the number of low distance bits, 4 to 6
+	minDictionaryBits = 4
+	maxDictionaryBits = 6
+	// shortDistanceBits is the number of low distance bits of a match of length 2,
+	// which can reach back shortDistance bytes past the 64 distance codes
+	shortDistanceBits = 2
+	distanceCodeBits  = 6
+	shortDistance     = 1 << (shortDistanceBits + distanceCodeBits)
+	minMatch          = 2
+	maxMatch          = 518
+	// endLength is the length marking the end of the stream
+	endLength = maxMatch + 1
+	// maxCodeBits is the length of the longest huffman code
+	maxCodeBits = 13
+)
+
+// Errors returned while exploding a stream
+var (
+	ErrLiteralMode    = errors.New("invalid PKWARE DCL literal mode")
+	ErrDictionarySize = errors.New("invalid PKWARE DCL dictionary size")
+	ErrDistance       = errors.New("PKWARE DCL distance reaches before the start of the data")
+	ErrCode           = errors.New("invalid PKWARE DCL code")
+)
+
+// code tables as lists of bit lengths, each byte holding a length in its low nibble
+// and how many more symbols in a row have it in its high nibble, as given by Mark
+// Adler's blast.c
+//
+//nolint:gochecknoglobals // code tables
+var (
+	literalLengths = []byte{
+		11, 124, 8, 7, 28, 7, 188, 13, 76, 4, 10, 8, 12, 10, 12, 10, 8, 23, 8,
+		9, 7, 6, 7, 8, 7, 6, 55, 8, 23, 24, 12, 11, 7, 9, 11, 12, 6, 7, 22, 5,
+		7, 24, 6, 11, 9, 6, 7, 22, 7, 11, 38, 7, 9, 8, 25, 11, 8, 11, 9, 12,
+		8, 12, 5, 38, 5, 38, 5, 11, 7, 5, 6, 21, 6, 10, 53, 8, 7, 24, 10, 27,
+		44, 253, 253, 253, 252, 252, 252, 13, 12, 45, 12, 45, 12, 61, 12, 45,
+		44, 173,
+	}
+	lengthLengths   = []byte{2, 35, 36, 53, 38, 23}
+	distanceLengths = []byte{2, 20, 53, 230, 247, 151, 248}
+
+	// lengthBase and lengthExtra give the smallest match length of each length code
+	// and the number of bits added to it
+	lengthBase  = [16]int{3, 2, 4, 5, 6, 7, 8, 9, 10, 12, 16, 24, 40, 72, 136, 264}
+	lengthExtra = [16]int{0, 0, 0, 0, 0, 0, 0, 0, 1, 2, 3, 4, 5, 6, 7, 8}
+)
+
+//nolint:gochecknoglobals // code tables
+var (
+	literalCodes  = createCodeTable(literalLengths)
+	lengthCodes   = createCodeTable(lengthLengths)
+	distanceCodes = createCodeTable(distanceLengths)
+)
+
+// codeTable is a canonical huffman code, with the codes of each length counting up
+// in symbol order
+type codeTable struct {
+	// count is the number of codes of each length
+	count [maxCodeBits + 1]int
+	// symbol lists the symbols ordered by code
+	symbol []int
+	// code and length give the code of each symbol
+	code   []int
+	length []int
+}
+
+// createCodeTable builds a code table from its compact list of bit lengths
+func createCodeTable(compact []byte) *codeTable {
+	result := &codeTable{}
+
+	for _, b := range compact {
+		for repeat := int(b>>4) + 1; repeat > 0; repeat-- { //nolint:gomnd // high nibble
+			result.length = append(result.length, int(b&0x0F)) //nolint:gomnd // low nibble
+		}
+	}
+
+	for _, length := range result.length {
+		result.count[length]++
+	}
+
+	// offsets of the first symbol of each length
+	var offsets [maxCodeBits + 2]int
+	for length := 1; length <= maxCodeBits; length++ {
+		offsets[length+1] = offsets[length] + result.count[length]
+	}
+
+	result.symbol = make([]int, len(result.length))
+	result.code = make([]int, len(result.length))
+
+	for symbol, length := range result.length {
+		result.symbol[offsets[length]] = symbol
+		offsets[length]++
+	}
+
+	code := 0
+
+	for length, i := 1, 0; length <= maxCodeBits; length++ {
+		for n := 0; n < result.count[length]; n++ {
+			result.code[result.symbol[i]] = code
+			code++
+			i++
+		}
+
+		code <<= 1
+	}
+
+	return result
+}
+
+// Explode decompresses a PKWARE DCL stream
+func Explode(data []byte) ([]byte, error) {
+	return io.ReadAll(CreateReader(bytes.NewReader(data)))
+}
+
+// Implode compresses data as a PKWARE DCL stream with binary literals and the given
+// dictionary size, 1024, 2048 or 4096 bytes. Larger dictionaries find more matches
+// in data longer than them.
+func Implode(data []byte, dictionarySize int) ([]byte, error) {
+	dictionaryBits := 0
+
+	switch dictionarySize {
+	case DictionarySize1024:
+		dictionaryBits = minDictionaryBits
+	case DictionarySize2048:
+		dictionaryBits = minDictionaryBits + 1
+	case DictionarySize4096:
+		dictionaryBits = maxDictionaryBits
+	default:
+		return nil, fmt.Errorf("%w: %d", ErrDictionarySize, dictionarySize)
+	}
+
+	w := bitio.CreateBitWriter(bitio.LSBFirst)
+	w.PushBytes(literalsBinary, byte(dictionaryBits))
+
+	finder := createMatchFinder(data, dictionarySize)
+
+	for position := 0; position < len(data); {
+		length, distance := finder.find(position)
+
+		if length < minMatch {
+			w.PushBit(false)
+			w.PushBits(uint64(data[position]), bitsPerByte)
+			finder.insert(position)
+			position++
+
+			continue
+		}
+
+		w.PushBit(true)
+		pushLength(w, length)
+
+		shift := dictionaryBits
+		if length == minMatch {
+			shift = shortDistanceBits
+		}
+
+		pushCode(w, distanceCodes, (distance-1)>>uint(shift))
+		w.PushBits(uint64(distance-1), shift)
+
+		for end := position + length; position < end; position++ {
+			finder.insert(position)
+		}
+	}
+
+	w.PushBit(true)
+	pushLength(w, endLength)
+
+	return w.GetBytes(), nil
+}
+
+// pushLength writes the code of a match length followed by its extra bits
+func pushLength(w *bitio.BitWriter, length int) {
+	for symbol, base := range lengthBase {
+		if length >= base && length < base+1<<uint(lengthExtra[symbol]) {
+			pushCode(w, lengthCodes, symbol)
+			w.PushBits(uint64(length-base), lengthExtra[symbol])
+
+			return
+		}
+	}
+}
+
+// pushCode writes the code of symbol, most significant bit first and inverted, as the
+// codes are stored
+func pushCode(w *bitio.BitWriter, table *codeTable, symbol int) {
+	code := table.code[symbol]
+
+	for i := table.length[symbol] - 1; i >= 0; i-- {
+		w.PushBit(code>>uint(i)&1 == 0)
+	}
+}
+
+const (
+	hashBits      = 12
+	maxChainDepth = 64
+)
+
+// matchFinder finds the longest earlier match of the data at a position, through
+// chains of the positions starting with the same three bytes
+type matchFinder struct {
+	data   []byte
+	window int
+	// head holds the latest position plus one of each hash, prev the previous
+	// position plus one with the same hash of each position
+	head [1 << hashBits]int32
+	prev []int32
+	// pairs holds the latest position plus one of each hash of two bytes
+	pairs [1 << hashBits]int32
+}
+
+// createMatchFinder creates a match finder reaching window bytes back
+func createMatchFinder(data []byte, window int) *matchFinder {
+	return &matchFinder{data: data, window: window, prev: make([]int32, len(data))}
+}
+
+// hash hashes the three bytes at position
+func (v *matchFinder) hash(position int) int {
+	d := v.data[position:]
+
+	return (int(d[0])<<8 ^ int(d[1])<<4 ^ int(d[2])) & (1<<hashBits - 1) //nolint:gomnd // hash mixing
+}
+
+// pairHash hashes the two bytes at position
+func (v *matchFinder) pairHash(position int) int {
+	d := v.data[position:]
+
+	return (int(d[0])<<4 ^ int(d[1])) & (1<<hashBits - 1) //nolint:gomnd // hash mixing
+}
+
+// insert adds position to its chain
+func (v *matchFinder) insert(position int) {
+	if position+minMatch <= len(v.data) {
+		v.pairs[v.pairHash(position)] = int32(position + 1)
+	}
+
+	if position+3 > len(v.data) { //nolint:gomnd // hashed bytes
+		return
+	}
+
+	hash := v.hash(position)
+	v.prev[position] = v.head[hash]
+	v.head[hash] = int32(position + 1)
+}
+
+// find returns the length and distance of the longest match at position, preferring
+// the nearest, or a length of 0 if there is none worth coding. Matches of length 2
+// are only coded within 256 bytes, which their shorter distance field reaches.
+func (v *matchFinder) find(position int) (length, distance int) {
+	limit := len(v.data) - position
+	if limit > maxMatch {
+		limit = maxMatch
+	}
+
+	if limit < minMatch {
+		return 0, 0
+	}
+
+	if limit >= 3 { //nolint:gomnd // hashed bytes
+		candidate := int(v.head[v.hash(position)])
+
+		for depth := 0; candidate > 0 && depth < maxChainDepth; depth++ {
+			start := candidate - 1
+			if position-start > v.window {
+				break
+			}
+
+			n := matchLength(v.data[start:], v.data[position:], limit)
+			if n > length {
+				length, distance = n, position-start
+				if n == limit {
+					break
+				}
+			}
+
+			candidate = int(v.prev[start])
+		}
+	}
+
+	if length > minMatch {
+		return length, distance
+	}
+
+	// the longest match is at most 2 bytes, which only pays off close by
+	if start := int(v.pairs[v.pairHash(position)]) - 1; start >= 0 && position-start <= shortDistance &&
+		matchLength(v.data[start:], v.data[position:], minMatch) == minMatch {
+		return minMatch, position - start
+	}
+
+	return 0, 0
+}
+
+// matchLength returns the number of leading bytes a and b share, up to limit
+func matchLength(a, b []byte, limit int) int {
+	n := 0
+	for n < limit && a[n] == b[n] {
+		n++
+	}
+
+	return n
+}
@@ -0,0 +1,84 @@
+package pkware_test
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"math/rand"
+	"testing"
+
+	"github.com/gravestench/wav/pkg/pkware"
+)
+
+// inputs returns data without matches, with matches of every length and with
+// matches reaching back further than the smaller dictionaries
+func inputs() map[string][]byte {
+	random := make([]byte, 8192)
+	rand.New(rand.NewSource(1)).Read(random)
+
+	// a random block repeated after more than 1024 bytes, so only the larger
+	// dictionaries find it
+	repeated := append(append([]byte(nil), random[:3000]...), random[:3000]...)
+
+	text := bytes.Repeat([]byte("the quick brown fox jumps over the lazy dog. "), 200)
+
+	return map[string][]byte{
+		"empty":    {},
+		"byte":     {0x42},
+		"text":     text,
+		"zeros":    make([]byte, 10000),
+		"random":   random,
+		"repeated": repeated,
+	}
+}
+
+func TestRoundTrip(t *testing.T) {
+	sizes := []int{pkware.DictionarySize1024, pkware.DictionarySize2048, pkware.DictionarySize4096}
+
+	for name, data := range inputs() {
+		for _, size := range sizes {
+			compressed, err := pkware.Implode(data, size)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			got, err := pkware.Explode(compressed)
+			if err != nil {
+				t.Fatalf("%s, dictionary %d: %v", name, size, err)
+			}
+
+			if !bytes.Equal(got, data) {
+				t.Errorf("%s, dictionary %d: exploded %d bytes, want the %d imploded", name, size, len(got), len(data))
+			}
+		}
+	}
+}
+
+// TestExplode decodes the example stream of Mark Adler's blast.c
+func TestExplode(t *testing.T) {
+	got, err := pkware.Explode([]byte{0x00, 0x04, 0x82, 0x24, 0x25, 0x8f, 0x80, 0x7f})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if want := "AIAIAIAIAIAIA"; string(got) != want {
+		t.Errorf("exploded %q, want %q", got, want)
+	}
+}
+
+func TestExplodeTruncated(t *testing.T) {
+	compressed, err := pkware.Implode(inputs()["text"], pkware.DictionarySize4096)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err = pkware.Explode(compressed[:len(compressed)/2]); !errors.Is(err, io.ErrUnexpectedEOF) {
+		t.Errorf("exploding half a stream returned %v, want %v", err, io.ErrUnexpectedEOF)
+	}
+}
+
+func TestImplodeDictionarySize(t *testing.T) {
+	if _, err := pkware.Implode([]byte("data"), 512); !errors.Is(err, pkware.ErrDictionarySize) {
+		t.Errorf("imploding with a 512 byte dictionary returned %v, want %v", err, pkware.ErrDictionarySize)
+	}
+}
@@ -0,0 +1,207 @@
+package pkware
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+)
+
+// trimSize is how far output may run ahead of the window before the reader drops
+// what it no longer needs
+const trimSize = 4 * DictionarySize4096
+
+// explodeReader decodes a PKWARE DCL stream as it is read, holding only the last
+// dictionary of output and a small input buffer
+type explodeReader struct {
+	src io.ByteReader
+	// current holds bitCount unread bits, least significant first
+	current  int
+	bitCount int
+	started  bool
+	ascii    bool
+	// dictionaryBits is the number of low distance bits of matches longer than 2
+	dictionaryBits int
+	// output holds the decoded bytes, of which those from position on haven't been read
+	output   []byte
+	position int
+	err      error
+}
+
+// CreateReader creates a reader decompressing the PKWARE DCL stream read from r, like
+// Explode but streaming, so memory use doesn't grow with the length of the data. A
+// stream ending before its end marker fails with io.ErrUnexpectedEOF.
+func CreateReader(r io.Reader) io.Reader {
+	src, ok := r.(io.ByteReader)
+	if !ok {
+		src = bufio.NewReader(r)
+	}
+
+	return &explodeReader{src: src}
+}
+
+// start reads the literal mode and dictionary size
+func (v *explodeReader) start() error {
+	mode, err := v.readBits(bitsPerByte)
+	if err != nil {
+		return err
+	}
+
+	if mode != literalsBinary && mode != literalsASCII {
+		return fmt.Errorf("%w: %d", ErrLiteralMode, mode)
+	}
+
+	v.ascii = mode == literalsASCII
+
+	if v.dictionaryBits, err = v.readBits(bitsPerByte); err != nil {
+		return err
+	}
+
+	if v.dictionaryBits < minDictionaryBits || v.dictionaryBits > maxDictionaryBits {
+		return fmt.Errorf("%w: %d", ErrDictionarySize, v.dictionaryBits)
+	}
+
+	v.started = true
+
+	return nil
+}
+
+// readBits reads up to 16 bits, least significant first
+func (v *explodeReader) readBits(bitCount int) (int, error) {
+	for v.bitCount < bitCount {
+		next, err := v.src.ReadByte()
+		if err != nil {
+			return 0, io.ErrUnexpectedEOF
+		}
+
+		v.current |= int(next) << uint(v.bitCount)
+		v.bitCount += bitsPerByte
+	}
+
+	result := v.current & (1<<uint(bitCount) - 1)
+	v.current >>= uint(bitCount)
+	v.bitCount -= bitCount
+
+	return result, nil
+}
+
+// readCode reads a code of the table bit by bit, as the codes are stored most
+// significant bit first and inverted, and returns its symbol
+func (v *explodeReader) readCode(table *codeTable) (int, error) {
+	code, first, index := 0, 0, 0
+
+	for length := 1; length <= maxCodeBits; length++ {
+		bit, err := v.readBits(1)
+		if err != nil {
+			return 0, err
+		}
+
+		code |= bit ^ 1
+		count := table.count[length]
+
+		if code < first+count {
+			return table.symbol[index+code-first], nil
+		}
+
+		index += count
+		first = (first + count) << 1
+		code <<= 1
+	}
+
+	return 0, ErrCode
+}
+
+// decode decodes a literal or a match, or returns io.EOF at the end marker
+func (v *explodeReader) decode() error {
+	match, err := v.readBits(1)
+	if err != nil {
+		return err
+	}
+
+	if match == 0 {
+		var literal int
+
+		if v.ascii {
+			literal, err = v.readCode(literalCodes)
+		} else {
+			literal, err = v.readBits(bitsPerByte)
+		}
+
+		if err != nil {
+			return err
+		}
+
+		v.output = append(v.output, byte(literal))
+
+		return nil
+	}
+
+	symbol, err := v.readCode(lengthCodes)
+	if err != nil {
+		return err
+	}
+
+	extra, err := v.readBits(lengthExtra[symbol])
+	if err != nil {
+		return err
+	}
+
+	length := lengthBase[symbol] + extra
+	if length == endLength {
+		return io.EOF
+	}
+
+	shift := v.dictionaryBits
+	if length == minMatch {
+		shift = shortDistanceBits
+	}
+
+	symbol, err = v.readCode(distanceCodes)
+	if err != nil {
+		return err
+	}
+
+	low, err := v.readBits(shift)
+	if err != nil {
+		return err
+	}
+
+	distance := symbol<<uint(shift) + low + 1
+	if distance > len(v.output) {
+		return ErrDistance
+	}
+
+	// the match may overlap the bytes it produces
+	for from := len(v.output) - distance; length > 0; length-- {
+		v.output = append(v.output, v.output[from])
+		from++
+	}
+
+	return nil
+}
+
+// Read implements io.Reader
+func (v *explodeReader) Read(p []byte) (int, error) {
+	if !v.started && v.err == nil {
+		v.err = v.start()
+	}
+
+	// keep the dictionary of output already read for the matches to come
+	if v.position > trimSize {
+		drop := v.position - DictionarySize4096
+		v.output = append(v.output[:0], v.output[drop:]...)
+		v.position -= drop
+	}
+
+	for len(v.output)-v.position < len(p) && v.err == nil {
+		v.err = v.decode()
+	}
+
+	n := copy(p, v.output[v.position:])
+	v.position += n
+
+	if n == 0 && v.err != nil {
+		return 0, v.err
+	}
+
+	return n, nil
+}
@@ -0,0 +1,200 @@
+package pkg
+
+import (
+	"errors"
+	"fmt"
+	"io"
+)
+
+// ErrPushClosed is returned when writing to a closed PushDecoder
+var ErrPushClosed = errors.New("push decoder is closed")
+
+// FrameHandler receives audio decoded by a PushDecoder as interleaved samples holding
+// whole sample frames. The slice is reused once the handler returns.
+type FrameHandler func(samples []int16) error
+
+// PushDecoder decodes a RIFF/WAVE stream fed to it in pieces of any size, such as
+// packets arriving from the network, and passes the decoded frames to a FrameHandler.
+// It is the push counterpart of Decoder, which pulls its input from an io.Reader.
+type PushDecoder struct {
+	handler FrameHandler
+	options decoderOptions
+	input   pushInput
+	decoder *Decoder
+	// remaining is the number of bytes of the data chunk not yet received
+	remaining int64
+	// pending holds the bytes of a partial sample frame
+	pending []byte
+	samples []int16
+	closed  bool
+}
+
+// pushInput is the source of the headers of a PushDecoder. It records whether the
+// Decoder parsing them asked for more than has been received so far.
+type pushInput struct {
+	data     []byte
+	position int
+	starved  bool
+}
+
+// Read implements io.Reader, reporting io.EOF at the end of the received input
+func (v *pushInput) Read(p []byte) (int, error) {
+	if v.position >= len(v.data) {
+		v.starved = true
+		return 0, io.EOF
+	}
+
+	n := copy(p, v.data[v.position:])
+	v.position += n
+
+	return n, nil
+}
+
+// CreatePushDecoder creates a PushDecoder passing the frames decoded from what is
+// written to it to handler
+func CreatePushDecoder(handler FrameHandler, opts ...DecoderOption) *PushDecoder {
+	return &PushDecoder{
+		handler: handler,
+		options: applyDecoderOptions(opts),
+	}
+}
+
+// Format returns the format of the audio, and whether the headers holding it have
+// been received yet
+func (v *PushDecoder) Format() (Format, bool) {
+	if v.decoder == nil {
+		return Format{}, false
+	}
+
+	return v.decoder.format, true
+}
+
+// Write implements io.Writer, decoding p and passing every sample frame it completes
+// to the handler. Headers are buffered until the start of the data chunk has been
+// received, and anything following the data chunk is ignored.
+func (v *PushDecoder) Write(p []byte) (int, error) {
+	if v.closed {
+		return 0, ErrPushClosed
+	}
+
+	if v.decoder == nil {
+		v.input.data = append(v.input.data, p...)
+
+		data, err := v.readHeaders()
+		if err != nil || v.decoder == nil {
+			return len(p), err
+		}
+
+		return len(p), v.decode(data)
+	}
+
+	return len(p), v.decode(p)
+}
+
+// readHeaders parses the received input up to the data chunk, returning the audio
+// bytes following its header. The decoder is left unset while the headers are incomplete.
+func (v *PushDecoder) readHeaders() ([]byte, error) {
+	v.input.position = 0
+	v.input.starved = false
+
+	decoder := &Decoder{options: v.options}
+
+	if err := decoder.Reset(&v.input); err != nil {
+		if v.input.starved {
+			return nil, nil
+		}
+
+		return nil, err
+	}
+
+	if decoder.segments != nil {
+		return nil, fmt.Errorf("%w: audio split across a wave list", ErrUnsupportedFile)
+	}
+
+	if err := decoder.format.validateSamples(); err != nil {
+		return nil, err
+	}
+
+	data := v.input.data[decoder.dataOffset:]
+
+	v.decoder = decoder
+	v.remaining = decoder.dataSize
+	v.input = pushInput{}
+
+	return data, nil
+}
+
+// decode passes the whole frames of the audio bytes in data to the handler, holding
+// back a trailing partial frame
+func (v *PushDecoder) decode(data []byte) error {
+	if int64(len(data)) > v.remaining {
+		data = data[:v.remaining]
+	}
+
+	v.remaining -= int64(len(data))
+
+	format := v.decoder.format
+	sampleSize := format.bytesPerSample()
+	frameSize := sampleSize * int(format.Channels)
+
+	if len(v.pending) > 0 {
+		take := frameSize - len(v.pending)
+		if take > len(data) {
+			take = len(data)
+		}
+
+		v.pending = append(v.pending, data[:take]...)
+		data = data[take:]
+
+		if len(v.pending) < frameSize {
+			return nil
+		}
+
+		if err := v.emit(v.pending, sampleSize); err != nil {
+			return err
+		}
+
+		v.pending = v.pending[:0]
+	}
+
+	whole := len(data) - len(data)%frameSize
+	v.pending = append(v.pending, data[whole:]...)
+
+	if whole == 0 {
+		return nil
+	}
+
+	return v.emit(data[:whole], sampleSize)
+}
+
+// emit decodes whole frames of encoded samples and passes them to the handler
+func (v *PushDecoder) emit(encoded []byte, sampleSize int) error {
+	count := len(encoded) / sampleSize
+	if cap(v.samples) < count {
+		v.samples = make([]int16, count)
+	}
+
+	samples := v.samples[:count]
+	for i := range samples {
+		samples[i] = v.decoder.format.decodeSample(encoded[i*sampleSize:])
+	}
+
+	return v.handler(samples)
+}
+
+// Close ends the stream. A partial sample frame at the end of the input is dropped,
+// as Decoder drops it from a truncated file, and io.ErrUnexpectedEOF is returned if
+// the headers were never completed.
+func (v *PushDecoder) Close() error {
+	if v.closed {
+		return ErrPushClosed
+	}
+
+	v.closed = true
+
+	if v.decoder == nil {
+		return io.ErrUnexpectedEOF
+	}
+
+	return nil
+}
@@ -0,0 +1,117 @@
+package pkg
+
+import (
+	"math"
+)
+
+const (
+	spectrumSize  = 1024
+	spectrumFloor = 1e-10
+	decibels      = 10
+)
+
+// QualityMetrics measures how far degraded audio strays from the original
+type QualityMetrics struct {
+	// SNR is the ratio of signal power to error power in dB
+	SNR float64
+	// PSNR is the ratio of full scale 16-bit power to error power in dB
+	PSNR float64
+	// SpectralDistance is the mean log-spectral distance between the signals in dB,
+	// computed over windows of 1024 frames per channel
+	SpectralDistance float64
+}
+
+// MeasureQuality compares interleaved degraded samples against the original, such as
+// the output of an encode/decode round trip. Identical signals have infinite SNR and PSNR.
+func MeasureQuality(original, degraded []int16, channels int) QualityMetrics {
+	n := len(original)
+	if len(degraded) < n {
+		n = len(degraded)
+	}
+
+	var signal, noise float64
+
+	for i := 0; i < n; i++ {
+		s := float64(original[i])
+		e := s - float64(degraded[i])
+		signal += s * s
+		noise += e * e
+	}
+
+	result := QualityMetrics{
+		SNR:  math.Inf(1),
+		PSNR: math.Inf(1),
+	}
+
+	if noise > 0 {
+		result.SNR = decibels * math.Log10(signal/noise)
+		result.PSNR = decibels * math.Log10(float64(maxInt16)*float64(maxInt16)*float64(n)/noise)
+	}
+
+	result.SpectralDistance = spectralDistance(original[:n], degraded[:n], channels)
+
+	return result
+}
+
+// MeasureADPCMQuality compresses interleaved samples with WavCompress at the given
+// level, decompresses them again and measures the degradation
+func MeasureADPCMQuality(samples []int16, channels, compressionLevel int) (QualityMetrics, error) {
+	compressed, err := WavCompress(samplesToBytes(samples), channels, compressionLevel)
+	if err != nil {
+		return QualityMetrics{}, err
+	}
+
+	decompressed, err := WavDecompressSamples(compressed, channels)
+	if err != nil {
+		return QualityMetrics{}, err
+	}
+
+	return MeasureQuality(samples, decompressed, channels), nil
+}
+
+// spectralDistance returns the log-spectral distance averaged over windows and channels
+func spectralDistance(a, b []int16, channels int) float64 {
+	if channels <= 0 {
+		return 0
+	}
+
+	frames := len(a) / channels
+	bufA := make([]complex128, spectrumSize)
+	bufB := make([]complex128, spectrumSize)
+
+	var total float64
+
+	windows := 0
+
+	for start := 0; start+spectrumSize <= frames; start += spectrumSize {
+		for ch := 0; ch < channels; ch++ {
+			for i := 0; i < spectrumSize; i++ {
+				// Hann window
+				w := 0.5 - 0.5*math.Cos(2*math.Pi*float64(i)/float64(spectrumSize-1)) //nolint:gomnd // window
+				bufA[i] = complex(w*float64(a[(start+i)*channels+ch]), 0)
+				bufB[i] = complex(w*float64(b[(start+i)*channels+ch]), 0)
+			}
+
+			fft(bufA)
+			fft(bufB)
+
+			var sum float64
+
+			for k := 0; k <= spectrumSize/2; k++ {
+				powerA := real(bufA[k])*real(bufA[k]) + imag(bufA[k])*imag(bufA[k]) + spectrumFloor
+				powerB := real(bufB[k])*real(bufB[k]) + imag(bufB[k])*imag(bufB[k]) + spectrumFloor
+				d := decibels * math.Log10(powerA/powerB)
+				sum += d * d
+			}
+
+			total += math.Sqrt(sum / float64(spectrumSize/2+1))
+			windows++
+		}
+	}
+
+	if windows == 0 {
+		return 0
+	}
+
+	return total / float64(windows)
+}
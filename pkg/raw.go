@@ -0,0 +1,34 @@
+package pkg
+
+import (
+	"io"
+)
+
+// ExtractRaw returns the complete format description and the data chunk of a WAV
+// file as stored, whatever its codec, so the payload can be handed to a decoder
+// outside this package
+func ExtractRaw(data []byte) (Format, []byte, error) {
+	decoder, err := CreateDecoder(data, WithRawData())
+	if err != nil {
+		return Format{}, nil, err
+	}
+
+	payload, err := io.ReadAll(decoder.RawReader())
+
+	return decoder.Format(), payload, err
+}
+
+// RawReader returns an io.Reader over the remainder of the data chunk as stored,
+// reading through ReadRaw
+func (v *Decoder) RawReader() io.Reader {
+	return rawReader{v}
+}
+
+// rawReader adapts ReadRaw to io.Reader
+type rawReader struct {
+	decoder *Decoder
+}
+
+func (v rawReader) Read(p []byte) (int, error) {
+	return v.decoder.ReadRaw(p)
+}
@@ -0,0 +1,158 @@
+package pkg
+
+import (
+	"math"
+)
+
+const (
+	// antiAliasZeroCrossings is the number of zero crossings of the sinc kernel on
+	// each side of the low-pass filter, at the input rate scaled by the rate ratio
+	antiAliasZeroCrossings = 8
+	// antiAliasCutoff is the filter cutoff as a fraction of the target Nyquist
+	// frequency, leaving room for the transition band
+	antiAliasCutoff  = 0.9
+	maxAntiAliasTaps = 1023
+)
+
+// Resampler converts interleaved samples with the given number of channels from one
+// sample rate to another. Transcode and Merge use LinearResampler unless WithResampler
+// injects another implementation.
+type Resampler interface {
+	Resample(samples []int16, channels, from, to int) ([]int16, error)
+}
+
+// ResamplerFunc adapts a function to the Resampler interface
+type ResamplerFunc func(samples []int16, channels, from, to int) ([]int16, error)
+
+// Resample calls the function
+func (v ResamplerFunc) Resample(samples []int16, channels, from, to int) ([]int16, error) {
+	return v(samples, channels, from, to)
+}
+
+// LinearResampler is the built-in Resampler, interpolating linearly between
+// neighbouring frames. When reducing the sample rate, the input is first low-pass
+// filtered below the target Nyquist frequency so that higher frequencies don't alias.
+type LinearResampler struct {
+	// NoAntiAlias skips the low-pass filter, which is faster but lets frequencies
+	// above the target Nyquist frequency fold back into the audible range
+	NoAntiAlias bool
+}
+
+// Resample converts samples between sample rates, returning them unchanged if the
+// rates are equal
+func (v LinearResampler) Resample(samples []int16, channels, from, to int) ([]int16, error) {
+	if !v.NoAntiAlias && to < from {
+		samples = lowPass(samples, channels, antiAliasCutoff*float64(to)/float64(from))
+	}
+
+	return resampleLinear(samples, channels, from, to), nil
+}
+
+// resample converts samples between sample rates with the resampler of options
+func (v options) resample(samples []int16, channels, from, to int) ([]int16, error) {
+	if from == to {
+		return samples, nil
+	}
+
+	if v.resampler == nil {
+		return LinearResampler{NoAntiAlias: v.noAntiAlias}.Resample(samples, channels, from, to)
+	}
+
+	return v.resampler.Resample(samples, channels, from, to)
+}
+
+// resampleLinear converts interleaved samples between sample rates by linear
+// interpolation between neighbouring frames
+func resampleLinear(samples []int16, channels, from, to int) []int16 {
+	if from == to || from <= 0 || to <= 0 || channels <= 0 {
+		return samples
+	}
+
+	frames := len(samples) / channels
+	outFrames := int(int64(frames) * int64(to) / int64(from))
+	result := make([]int16, outFrames*channels)
+
+	for i := 0; i < outFrames; i++ {
+		// position of the output frame in input frames, in 1/to units
+		position := int64(i) * int64(from)
+		index := int(position / int64(to))
+		fraction := float64(position%int64(to)) / float64(to)
+
+		next := index + 1
+		if next >= frames {
+			next = frames - 1
+		}
+
+		for ch := 0; ch < channels; ch++ {
+			a := float64(samples[index*channels+ch])
+			b := float64(samples[next*channels+ch])
+			result[i*channels+ch] = int16(a + (b-a)*fraction)
+		}
+	}
+
+	return result
+}
+
+// lowPass filters interleaved samples with a Blackman windowed sinc, passing
+// frequencies below cutoff, given as a fraction of the Nyquist frequency. The filter
+// gets longer as the cutoff falls, so its transition band stays proportionally narrow.
+func lowPass(samples []int16, channels int, cutoff float64) []int16 {
+	if cutoff <= 0 || cutoff >= 1 || channels <= 0 {
+		return samples
+	}
+
+	half := int(math.Ceil(antiAliasZeroCrossings / cutoff))
+	if half > maxAntiAliasTaps/2 {
+		half = maxAntiAliasTaps / 2
+	}
+
+	kernel := make([]float64, 2*half+1)
+	sum := 0.0
+
+	for i := range kernel {
+		x := float64(i - half)
+		value := cutoff
+
+		if x != 0 {
+			value = math.Sin(math.Pi*cutoff*x) / (math.Pi * x)
+		}
+
+		//nolint:gomnd // Blackman window coefficients
+		phase := 2 * math.Pi * float64(i) / float64(len(kernel)-1)
+		value *= 0.42 - 0.5*math.Cos(phase) + 0.08*math.Cos(2*phase)
+
+		kernel[i] = value
+		sum += value
+	}
+
+	// unity gain at DC
+	for i := range kernel {
+		kernel[i] /= sum
+	}
+
+	frames := len(samples) / channels
+	result := make([]int16, frames*channels)
+
+	for frame := 0; frame < frames; frame++ {
+		// the input is taken as silent beyond its ends
+		first, last := frame-half, frame+half
+		if first < 0 {
+			first = 0
+		}
+
+		if last >= frames {
+			last = frames - 1
+		}
+
+		for ch := 0; ch < channels; ch++ {
+			acc := 0.0
+			for j := first; j <= last; j++ {
+				acc += kernel[j-frame+half] * float64(samples[j*channels+ch])
+			}
+
+			result[frame*channels+ch] = int16(clampInt(int(math.Round(acc)), math.MinInt16, math.MaxInt16))
+		}
+	}
+
+	return result
+}
@@ -0,0 +1,44 @@
+package pkg
+
+// ReverseSamples reverses the order of the sample frames of interleaved samples in
+// place, keeping the channels of each frame in order. A trailing partial frame is
+// left where it is.
+func ReverseSamples(samples []int16, channels int) {
+	if channels <= 0 {
+		return
+	}
+
+	frames := len(samples) / channels
+
+	for i, j := 0, frames-1; i < j; i, j = i+1, j-1 {
+		a := samples[i*channels : (i+1)*channels]
+		b := samples[j*channels : (j+1)*channels]
+
+		for ch := range a {
+			a[ch], b[ch] = b[ch], a[ch]
+		}
+	}
+}
+
+// Reverse reverses the buffer in place, so that it plays backwards
+func (v *Buffer) Reverse() {
+	ReverseSamples(v.Samples, v.Channels)
+}
+
+// ReverseFile decodes the WAV file at src and writes it played backwards to dst, at
+// the same bit depth unless opts convert it like EncodeFile. Markers aren't carried
+// over, as their sample positions would no longer apply.
+func ReverseFile(src, dst string, opts ...Option) error {
+	buffer, err := DecodeFile(src, opts...)
+	if err != nil {
+		return err
+	}
+
+	buffer.Reverse()
+
+	if buffer.Metadata != nil {
+		buffer.Metadata.Markers = nil
+	}
+
+	return EncodeFile(dst, buffer, opts...)
+}
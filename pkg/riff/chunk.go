@@ -0,0 +1,49 @@
+package riff
+
+import (
+	"github.com/gravestench/wav/pkg/bitio"
+)
+
+const (
+	// HeaderSize is the size of a chunk header, its ID and 32-bit size
+	HeaderSize = 8
+	// FormHeaderSize is the size of the header of a file, the RIFF chunk header and form type
+	FormHeaderSize = 12
+	bytesPerint32  = 4
+)
+
+// Padding returns the number of pad bytes following a chunk body of the given
+// size, as RIFF keeps every chunk word aligned
+func Padding(size int64) int64 {
+	return size & 1
+}
+
+// PushChunk writes a chunk header, the body and its pad byte
+func PushChunk(w *bitio.StreamWriter, id FourCC, body []byte) {
+	w.PushFourCC(id)
+	w.PushUint32(uint32(len(body)))
+	w.PushBytes(body...)
+	w.PushBytes(make([]byte, Padding(int64(len(body))))...)
+}
+
+// PaddedSize returns the number of bytes a chunk with a body of the given size
+// occupies, including its header and pad byte
+func PaddedSize(size int) int {
+	return HeaderSize + size + int(Padding(int64(size)))
+}
+
+// IsChunkID reports whether b starts with four printable ASCII characters, which is
+// used to detect writers that omit the pad byte after odd-sized chunks
+func IsChunkID(b []byte) bool {
+	if len(b) < bytesPerint32 {
+		return false
+	}
+
+	for _, c := range b[:bytesPerint32] {
+		if c < ' ' || c > '~' {
+			return false
+		}
+	}
+
+	return true
+}
@@ -0,0 +1,153 @@
+package riff
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+
+	"github.com/gravestench/wav/pkg/bitio"
+)
+
+// Errors returned while parsing chunks
+var (
+	// ErrShortChunk is returned when a chunk is shorter than its contents require
+	ErrShortChunk = errors.New("metadata chunk is too short")
+	// ErrChunkSize is returned when a chunk claims more bytes than the file holds
+	ErrChunkSize = errors.New("chunk size exceeds the file")
+)
+
+const (
+	// DS64MinSize is the size of a ds64 chunk body without table entries
+	DS64MinSize   = 28
+	ds64EntrySize = 12
+	// SizePlaceholder fills the size fields of RF64 files whose real size is in the ds64 chunk
+	SizePlaceholder = 0xFFFFFFFF
+)
+
+// DS64 holds the contents of the ds64 chunk of an RF64 or BW64 file, which carries
+// the sizes that don't fit the 32-bit size fields
+type DS64 struct {
+	RIFFSize    int64
+	DataSize    int64
+	SampleCount int64
+	// Table holds the sizes of other chunks too large for their size field
+	Table map[FourCC]int64
+}
+
+// IsRF64 reports whether the code identifies an RF64 or BW64 file
+func IsRF64(id FourCC) bool {
+	return id == ChunkRF64 || id == ChunkBW64
+}
+
+// ParseDS64 parses the body of a ds64 chunk. Sizes which are negative or larger than
+// the file they describe fail with ErrChunkSize.
+func ParseDS64(body []byte) (*DS64, error) {
+	if len(body) < DS64MinSize {
+		return nil, ErrShortChunk
+	}
+
+	r := bitio.CreateStreamReader(body)
+	result := &DS64{Table: map[FourCC]int64{}}
+
+	result.RIFFSize, _ = r.ReadInt64()
+	result.DataSize, _ = r.ReadInt64()
+	result.SampleCount, _ = r.ReadInt64()
+	count, _ := r.ReadUInt32()
+
+	if result.RIFFSize < 0 || result.SampleCount < 0 {
+		return nil, fmt.Errorf("%w: RIFF size %d, sample count %d", ErrChunkSize, result.RIFFSize, result.SampleCount)
+	}
+
+	if err := result.checkSize(ChunkData, result.DataSize); err != nil {
+		return nil, err
+	}
+
+	for i := uint32(0); i < count; i++ {
+		id, err := r.ReadFourCC()
+		if err != nil {
+			return nil, ErrShortChunk
+		}
+
+		size, err := r.ReadInt64()
+		if err != nil {
+			return nil, ErrShortChunk
+		}
+
+		if err = result.checkSize(id, size); err != nil {
+			return nil, err
+		}
+
+		result.Table[id] = size
+	}
+
+	return result, nil
+}
+
+// checkSize reports whether the size of a chunk fits in the RIFF chunk
+func (v *DS64) checkSize(id FourCC, size int64) error {
+	if size < 0 || size > v.RIFFSize {
+		return fmt.Errorf("%w: %q claims %d bytes of a %d byte RIFF chunk", ErrChunkSize, id, size, v.RIFFSize)
+	}
+
+	return nil
+}
+
+// ChunkSize returns the size of a chunk whose size field held size, looking up the
+// real size of chunks with the placeholder size. A nil table returns size as is, as
+// does a negative size in a table which wasn't parsed by ParseDS64.
+func (v *DS64) ChunkSize(id FourCC, size uint32) int64 {
+	if v == nil || size != SizePlaceholder {
+		return int64(size)
+	}
+
+	real, ok := v.Table[id]
+	if id == ChunkData {
+		real, ok = v.DataSize, true
+	}
+
+	if !ok || real < 0 {
+		return int64(size)
+	}
+
+	return real
+}
+
+// Bytes returns the body of the ds64 chunk
+func (v *DS64) Bytes() []byte {
+	w := bitio.CreateStreamWriterSize(DS64MinSize + len(v.Table)*ds64EntrySize)
+
+	w.PushInt64(v.RIFFSize)
+	w.PushInt64(v.DataSize)
+	w.PushInt64(v.SampleCount)
+	w.PushUint32(uint32(len(v.Table)))
+
+	for id, size := range v.Table {
+		w.PushFourCC(id)
+		w.PushInt64(size)
+	}
+
+	return w.GetBytes()
+}
+
+// MarshalBinary implements encoding.BinaryMarshaler, returning the body of the ds64 chunk
+func (v *DS64) MarshalBinary() ([]byte, error) {
+	return v.Bytes(), nil
+}
+
+// UnmarshalBinary implements encoding.BinaryUnmarshaler, parsing the body of a ds64 chunk
+func (v *DS64) UnmarshalBinary(data []byte) error {
+	parsed, err := ParseDS64(data)
+	if err != nil {
+		return err
+	}
+
+	*v = *parsed
+
+	return nil
+}
+
+// ReadChunkSize returns the size in the header of a chunk, resolved through the ds64
+// chunk of an RF64 file
+func ReadChunkSize(header []byte, table *DS64) int64 {
+	return table.ChunkSize(ReadFourCC(header), binary.LittleEndian.Uint32(header[4:]))
+}
@@ -0,0 +1,104 @@
+package riff_test
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"math"
+	"reflect"
+	"testing"
+
+	"github.com/gravestench/wav/pkg/bitio"
+	"github.com/gravestench/wav/pkg/riff"
+)
+
+// chunkFmt identifies the fmt chunk, which only matters to this package as a chunk
+// to walk past
+//
+//nolint:gochecknoglobals // four character codes can't be constants
+var chunkFmt = riff.CreateFourCC("fmt ")
+
+// rf64File returns an RF64 file with the ds64 chunk table, a 16 byte fmt chunk and a
+// data chunk of 4 bytes, whatever size the table gives it
+func rf64File(table *riff.DS64) []byte {
+	w := bitio.CreateStreamWriter()
+
+	w.PushFourCC(riff.ChunkRF64)
+	w.PushUint32(riff.SizePlaceholder)
+	w.PushFourCC(riff.ChunkWAVE)
+	riff.PushChunk(w, riff.ChunkDs64, table.Bytes())
+	riff.PushChunk(w, chunkFmt, make([]byte, 16))
+	w.PushFourCC(riff.ChunkData)
+	w.PushUint32(riff.SizePlaceholder)
+	w.PushBytes(1, 2, 3, 4)
+
+	return w.GetBytes()
+}
+
+func TestParseDS64(t *testing.T) {
+	table := &riff.DS64{
+		RIFFSize:    1 << 33,
+		DataSize:    1<<33 - 100,
+		SampleCount: 1 << 31,
+		Table:       map[riff.FourCC]int64{chunkFmt: 1 << 32},
+	}
+
+	got, err := riff.ParseDS64(table.Bytes())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !reflect.DeepEqual(got, table) {
+		t.Errorf("parsed %+v, want %+v", got, table)
+	}
+
+	if _, err = riff.ParseDS64(table.Bytes()[:riff.DS64MinSize-1]); !errors.Is(err, riff.ErrShortChunk) {
+		t.Errorf("parsing a short chunk returned %v, want %v", err, riff.ErrShortChunk)
+	}
+
+	if _, err = riff.ParseDS64(table.Bytes()[:riff.DS64MinSize+4]); !errors.Is(err, riff.ErrShortChunk) {
+		t.Errorf("parsing a truncated table returned %v, want %v", err, riff.ErrShortChunk)
+	}
+}
+
+// TestParseDS64Sizes rejects sizes which would run backwards or past the file
+func TestParseDS64Sizes(t *testing.T) {
+	tables := map[string]riff.DS64{
+		"negative RIFF size":    {RIFFSize: -1},
+		"negative data size":    {RIFFSize: 100, DataSize: math.MinInt64},
+		"negative sample count": {RIFFSize: 100, SampleCount: -1},
+		"data past the file":    {RIFFSize: 100, DataSize: 101},
+		"negative table size":   {RIFFSize: 100, Table: map[riff.FourCC]int64{chunkFmt: -16}},
+		"table past the file":   {RIFFSize: 100, Table: map[riff.FourCC]int64{chunkFmt: math.MaxInt64}},
+	}
+
+	for name, table := range tables {
+		if _, err := riff.ParseDS64(table.Bytes()); !errors.Is(err, riff.ErrChunkSize) {
+			t.Errorf("%s: parsing returned %v, want %v", name, err, riff.ErrChunkSize)
+		}
+	}
+}
+
+// TestChunkSizeNegative looks up negative sizes of a table built by hand, which must
+// not be returned
+func TestChunkSizeNegative(t *testing.T) {
+	table := &riff.DS64{DataSize: -1, Table: map[riff.FourCC]int64{chunkFmt: -1}}
+
+	for _, id := range []riff.FourCC{riff.ChunkData, chunkFmt} {
+		if size := table.ChunkSize(id, riff.SizePlaceholder); size < 0 {
+			t.Errorf("%q: size %d", id, size)
+		}
+	}
+}
+
+func TestWalkMalformedDS64(t *testing.T) {
+	data := rf64File(&riff.DS64{RIFFSize: 100, DataSize: -8})
+
+	err := riff.Walk(bytes.NewReader(data), func(riff.FourCC, int64, int64, io.Reader) error {
+		return nil
+	})
+
+	if !errors.Is(err, riff.ErrChunkSize) {
+		t.Errorf("walking returned %v, want %v", err, riff.ErrChunkSize)
+	}
+}
@@ -0,0 +1,53 @@
+package riff
+
+import (
+	"encoding/hex"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// Dump writes a line for every chunk giving its ID, offset and size, followed by a
+// hex and ASCII preview of up to preview bytes of its body. Chunks nested in a LIST
+// are indented below it. A preview of 0 lists the chunks alone.
+func (v *File) Dump(w io.Writer, preview int) error {
+	for _, chunk := range v.chunks {
+		indent := ""
+		if chunk.List != (FourCC{}) {
+			indent = "  "
+		}
+
+		if _, err := fmt.Fprintf(w, "%s%q offset %d size %d\n", indent, chunk.ID.String(), chunk.Offset, chunk.Size); err != nil {
+			return err
+		}
+
+		if preview <= 0 || chunk.Size == 0 {
+			continue
+		}
+
+		size := chunk.Size
+		if size > int64(preview) {
+			size = int64(preview)
+		}
+
+		body := make([]byte, size)
+		if _, err := chunk.r.ReadAt(body, chunk.Offset); err != nil {
+			return err
+		}
+
+		lines := strings.TrimSuffix(hex.Dump(body), "\n")
+		for _, line := range strings.Split(lines, "\n") {
+			if _, err := fmt.Fprintf(w, "%s    %s\n", indent, line); err != nil {
+				return err
+			}
+		}
+
+		if size < chunk.Size {
+			if _, err := fmt.Fprintf(w, "%s    ... %d more bytes\n", indent, chunk.Size-size); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,155 @@
+package riff
+
+import (
+	"errors"
+	"io"
+	"os"
+)
+
+// ErrChunkNotFound is returned when reading a chunk the file doesn't have
+var ErrChunkNotFound = errors.New("chunk not found")
+
+// Chunk is a chunk found in a RIFF file. Its body is only read when asked for.
+type Chunk struct {
+	ID FourCC
+	// List is the type of the LIST the chunk is nested in, or zero at the top level
+	List FourCC
+	// Offset is the position of the chunk body in the file
+	Offset int64
+	Size   int64
+	r      io.ReaderAt
+}
+
+// Reader returns a reader over the chunk body
+func (v *Chunk) Reader() *io.SectionReader {
+	return io.NewSectionReader(v.r, v.Offset, v.Size)
+}
+
+// Bytes reads the chunk body. A nil chunk, as returned by File.Chunk for a chunk the
+// file doesn't have, returns ErrChunkNotFound.
+func (v *Chunk) Bytes() ([]byte, error) {
+	if v == nil {
+		return nil, ErrChunkNotFound
+	}
+
+	result := make([]byte, v.Size)
+	if _, err := v.r.ReadAt(result, v.Offset); err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
+
+// File holds the chunk layout of a RIFF WAVE file without reading the chunk bodies,
+// so files can be probed for metadata without loading their audio
+type File struct {
+	chunks []*Chunk
+	closer io.Closer
+}
+
+// Open indexes the chunks of a WAV file. The returned File must be closed.
+func Open(path string) (*File, error) {
+	f, err := os.Open(path) //nolint:gosec // opening caller supplied paths is the point
+	if err != nil {
+		return nil, err
+	}
+
+	info, err := f.Stat()
+	if err != nil {
+		_ = f.Close()
+		return nil, err
+	}
+
+	result, err := CreateFile(f, info.Size())
+	if err != nil {
+		_ = f.Close()
+		return nil, err
+	}
+
+	result.closer = f
+
+	return result, nil
+}
+
+// CreateFile indexes the chunks of the WAV file of the given size held by r
+func CreateFile(r io.ReaderAt, size int64) (*File, error) {
+	type list struct {
+		listType FourCC
+		end      int64
+	}
+
+	result := &File{}
+	section := io.NewSectionReader(r, 0, size)
+
+	var lists []list
+
+	err := Walk(section, func(id FourCC, offset, size int64, body io.Reader) error {
+		for len(lists) > 0 && offset >= lists[len(lists)-1].end {
+			lists = lists[:len(lists)-1]
+		}
+
+		chunk := &Chunk{ID: id, Offset: offset, Size: size, r: r}
+		if len(lists) > 0 {
+			chunk.List = lists[len(lists)-1].listType
+		}
+
+		result.chunks = append(result.chunks, chunk)
+
+		if id == ChunkLIST && size >= bytesPerint32 {
+			listType := make([]byte, bytesPerint32)
+			if _, err := io.ReadFull(body, listType); err != nil {
+				return err
+			}
+
+			lists = append(lists, list{listType: ReadFourCC(listType), end: offset + size})
+		}
+
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
+
+// Chunks returns every chunk in file order, with the chunks nested in a LIST
+// following the LIST itself
+func (v *File) Chunks() []*Chunk {
+	return v.chunks
+}
+
+// Reserved returns the chunks holding reserved space, such as JUNK and PAD chunks
+func (v *File) Reserved() []*Chunk {
+	var result []*Chunk
+
+	for _, chunk := range v.chunks {
+		if chunk.ID.Reserved() {
+			result = append(result, chunk)
+		}
+	}
+
+	return result
+}
+
+// Chunk returns the first chunk with the given ID, or nil if there is none
+func (v *File) Chunk(id string) *Chunk {
+	fourCC := CreateFourCC(id)
+
+	for _, chunk := range v.chunks {
+		if chunk.ID == fourCC {
+			return chunk
+		}
+	}
+
+	return nil
+}
+
+// Close closes the file opened by Open
+func (v *File) Close() error {
+	if v.closer == nil {
+		return nil
+	}
+
+	return v.closer.Close()
+}
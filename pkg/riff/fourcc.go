@@ -0,0 +1,74 @@
+// Package riff reads and writes the chunk structure of RIFF WAVE files, including
+// the RF64 and BW64 variants whose ds64 chunk carries 64-bit sizes
+package riff
+
+import (
+	"errors"
+)
+
+// ErrFourCC is returned when unmarshaling text which isn't four bytes long
+var ErrFourCC = errors.New("a four character code must be four bytes")
+
+// FourCC is a four character code, identifying RIFF chunks, list and form types
+type FourCC [4]byte
+
+// Chunk IDs and list types of the RIFF structure
+//
+//nolint:gochecknoglobals // four character codes can't be constants
+var (
+	ChunkRIFF = FourCC{'R', 'I', 'F', 'F'}
+	ChunkRF64 = FourCC{'R', 'F', '6', '4'}
+	ChunkBW64 = FourCC{'B', 'W', '6', '4'}
+	ChunkWAVE = FourCC{'W', 'A', 'V', 'E'}
+	ChunkData = FourCC{'d', 'a', 't', 'a'}
+	ChunkDs64 = FourCC{'d', 's', '6', '4'}
+	ChunkLIST = FourCC{'L', 'I', 'S', 'T'}
+	ChunkJUNK = FourCC{'J', 'U', 'N', 'K'}
+	ChunkPAD  = FourCC{'P', 'A', 'D', ' '}
+	ChunkFake = FourCC{'F', 'a', 'k', 'e'}
+	ChunkFLLR = FourCC{'F', 'L', 'L', 'R'}
+)
+
+// Reserved reports whether the code identifies a chunk holding nothing but reserved
+// space, such as JUNK reserving room for a later RF64 upgrade
+func (v FourCC) Reserved() bool {
+	return v == ChunkJUNK || v == ChunkPAD || v == ChunkFake || v == ChunkFLLR
+}
+
+// CreateFourCC creates a FourCC from a string, truncating it or padding it with spaces
+func CreateFourCC(s string) FourCC {
+	result := FourCC{' ', ' ', ' ', ' '}
+	copy(result[:], s)
+
+	return result
+}
+
+// ReadFourCC returns the four character code at the start of b
+func ReadFourCC(b []byte) FourCC {
+	var result FourCC
+
+	copy(result[:], b)
+
+	return result
+}
+
+// String returns the code as a string
+func (v FourCC) String() string {
+	return string(v[:])
+}
+
+// MarshalText implements encoding.TextMarshaler
+func (v FourCC) MarshalText() ([]byte, error) {
+	return []byte(v.String()), nil
+}
+
+// UnmarshalText implements encoding.TextUnmarshaler
+func (v *FourCC) UnmarshalText(text []byte) error {
+	if len(text) != len(v) {
+		return ErrFourCC
+	}
+
+	copy(v[:], text)
+
+	return nil
+}
@@ -0,0 +1,155 @@
+package riff
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+)
+
+// Errors returned when walking a file
+var (
+	ErrNotRIFF      = errors.New("not a RIFF file")
+	ErrNotWAVE      = errors.New("not a WAVE file")
+	ErrCorruptChunk = errors.New("invalid chunk ID")
+)
+
+// SkipList can be returned by a WalkChunks callback for a LIST chunk to skip the
+// chunks nested in it
+var SkipList = errors.New("skip this list") //nolint:errname,revive,stylecheck // used like filepath.SkipDir
+
+// WalkChunks calls fn for every chunk of a RIFF WAVE stream, in file order. The chunks
+// nested in a LIST follow the LIST itself, whose body starts with the list type. body
+// reads the chunk body, and needn't be drained. The walk stops at the first error
+// returned by fn, other than SkipList. In RF64 files the sizes of chunks too large for
+// 32 bits are taken from the ds64 chunk; size is then 0xFFFFFFFF, but body reads the
+// whole chunk.
+func WalkChunks(r io.ReadSeeker, fn func(id FourCC, size uint32, body io.Reader) error) error {
+	return Walk(r, func(id FourCC, _, size int64, body io.Reader) error {
+		if size > SizePlaceholder {
+			size = SizePlaceholder
+		}
+
+		return fn(id, uint32(size), body)
+	})
+}
+
+// chunkWalker walks the chunks of a RIFF or RF64 file
+type chunkWalker struct {
+	r     io.ReadSeeker
+	rf64  bool
+	sizes *DS64
+	fn    func(id FourCC, offset, size int64, body io.Reader) error
+}
+
+// HeaderError returns the error of reading a RIFF header of which n bytes were read,
+// wrapping io.ErrUnexpectedEOF when the source ended first, even before the first byte
+func HeaderError(n int, err error) error {
+	if errors.Is(err, io.EOF) || errors.Is(err, io.ErrUnexpectedEOF) {
+		return fmt.Errorf("%w: RIFF header ends after %d bytes", io.ErrUnexpectedEOF, n)
+	}
+
+	return err
+}
+
+// Walk calls fn for every chunk of a RIFF WAVE stream like WalkChunks, passing the
+// offset of its body and its 64-bit size
+func Walk(r io.ReadSeeker, fn func(id FourCC, offset, size int64, body io.Reader) error) error {
+	header := make([]byte, FormHeaderSize)
+	if n, err := io.ReadFull(r, header); err != nil {
+		return HeaderError(n, err)
+	}
+
+	walker := &chunkWalker{r: r, rf64: IsRF64(ReadFourCC(header)), fn: fn}
+
+	if ReadFourCC(header) != ChunkRIFF && !walker.rf64 {
+		return ErrNotRIFF
+	}
+
+	if ReadFourCC(header[8:]) != ChunkWAVE {
+		return ErrNotWAVE
+	}
+
+	end, err := r.Seek(0, io.SeekEnd)
+	if err != nil {
+		return err
+	}
+
+	if riffEnd := int64(binary.LittleEndian.Uint32(header[4:])) + HeaderSize; riffEnd < end && !walker.rf64 {
+		end = riffEnd
+	}
+
+	return walker.walk(FormHeaderSize, end)
+}
+
+// walk calls fn for the chunks between the offsets start and end
+func (v *chunkWalker) walk(start, end int64) error {
+	header := make([]byte, HeaderSize)
+
+	for offset := start; offset+HeaderSize <= end; {
+		if _, err := v.r.Seek(offset, io.SeekStart); err != nil {
+			return err
+		}
+
+		if _, err := io.ReadFull(v.r, header); err != nil {
+			return err
+		}
+
+		// some writers omit the pad byte after odd-sized chunks
+		if !IsChunkID(header) && offset > start {
+			unpadded := make([]byte, HeaderSize)
+			if _, err := v.r.Seek(offset-1, io.SeekStart); err != nil {
+				return err
+			}
+
+			if _, err := io.ReadFull(v.r, unpadded); err == nil && IsChunkID(unpadded) {
+				offset--
+				copy(header, unpadded)
+			}
+		}
+
+		if !IsChunkID(header) {
+			return ErrCorruptChunk
+		}
+
+		id := ReadFourCC(header)
+		size := ReadChunkSize(header, v.sizes)
+		bodyStart := offset + HeaderSize
+
+		if size > end-bodyStart {
+			size = end - bodyStart
+		}
+
+		var body io.Reader = io.LimitReader(v.r, size)
+
+		if id == ChunkDs64 && v.rf64 && v.sizes == nil {
+			data, err := io.ReadAll(body)
+			if err != nil {
+				return err
+			}
+
+			if v.sizes, err = ParseDS64(data); err != nil {
+				return err
+			}
+
+			body = bytes.NewReader(data)
+		}
+
+		err := v.fn(id, bodyStart, size, body)
+
+		switch {
+		case errors.Is(err, SkipList):
+		case err != nil:
+			return err
+		case id == ChunkLIST && size >= bytesPerint32:
+			if err := v.walk(bodyStart+bytesPerint32, bodyStart+size); err != nil {
+				return err
+			}
+		}
+
+		offset = bodyStart + size + Padding(size)
+	}
+
+	return nil
+}
@@ -0,0 +1,20 @@
+package riff_test
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"testing"
+
+	"github.com/gravestench/wav/pkg/riff"
+)
+
+func TestWalkShortHeader(t *testing.T) {
+	err := riff.Walk(bytes.NewReader([]byte("RIFF")), func(riff.FourCC, int64, int64, io.Reader) error {
+		return nil
+	})
+
+	if !errors.Is(err, io.ErrUnexpectedEOF) {
+		t.Errorf("walking a short header returned %v, want %v", err, io.ErrUnexpectedEOF)
+	}
+}
@@ -0,0 +1,123 @@
+package pkg
+
+import (
+	"sync/atomic"
+)
+
+// RingBuffer is a fixed size queue of interleaved samples for one producer and one
+// consumer goroutine, such as a decoding goroutine feeding an audio callback. Reads
+// and writes move whole sample frames, never block, never allocate and take no locks.
+type RingBuffer struct {
+	samples  []int16
+	channels int
+	// read and write count the samples consumed and produced so far. Each is only
+	// stored by one side, after the samples it covers have been copied.
+	read   atomic.Uint64
+	write  atomic.Uint64
+	closed atomic.Bool
+}
+
+// CreateRingBuffer creates a RingBuffer holding up to frames sample frames of the
+// given number of channels
+func CreateRingBuffer(frames, channels int) *RingBuffer {
+	if frames < 1 {
+		frames = 1
+	}
+
+	if channels < 1 {
+		channels = 1
+	}
+
+	return &RingBuffer{
+		samples:  make([]int16, frames*channels),
+		channels: channels,
+	}
+}
+
+// Channels returns the number of samples in each frame
+func (v *RingBuffer) Channels() int {
+	return v.channels
+}
+
+// Capacity returns the number of frames the buffer holds when full
+func (v *RingBuffer) Capacity() int {
+	return len(v.samples) / v.channels
+}
+
+// Available returns the number of frames which can be read
+func (v *RingBuffer) Available() int {
+	return int(v.write.Load()-v.read.Load()) / v.channels
+}
+
+// Free returns the number of frames which can be written
+func (v *RingBuffer) Free() int {
+	return v.Capacity() - v.Available()
+}
+
+// Write copies as many whole frames of samples as fit into the buffer, returning the
+// number of samples written. It must only be called by the producer.
+func (v *RingBuffer) Write(samples []int16) int {
+	write := v.write.Load()
+	free := uint64(len(v.samples)) - (write - v.read.Load())
+
+	count := uint64(len(samples))
+	if count > free {
+		count = free
+	}
+
+	count -= count % uint64(v.channels)
+	if count == 0 {
+		return 0
+	}
+
+	start := int(write % uint64(len(v.samples)))
+	n := copy(v.samples[start:], samples[:count])
+	copy(v.samples, samples[n:count])
+
+	v.write.Store(write + count)
+
+	return int(count)
+}
+
+// Read copies as many whole frames as are available and fit in dst, returning the
+// number of samples read. It must only be called by the consumer.
+func (v *RingBuffer) Read(dst []int16) int {
+	read := v.read.Load()
+	available := v.write.Load() - read
+
+	count := uint64(len(dst))
+	if count > available {
+		count = available
+	}
+
+	count -= count % uint64(v.channels)
+	if count == 0 {
+		return 0
+	}
+
+	start := int(read % uint64(len(v.samples)))
+	n := copy(dst[:count], v.samples[start:])
+	copy(dst[n:count], v.samples)
+
+	v.read.Store(read + count)
+
+	return int(count)
+}
+
+// Close marks the end of the stream. It must only be called by the producer, after
+// its last Write.
+func (v *RingBuffer) Close() {
+	v.closed.Store(true)
+}
+
+// Done reports whether the producer has closed the buffer and every frame has been read
+func (v *RingBuffer) Done() bool {
+	return v.closed.Load() && v.Available() == 0
+}
+
+// Reset empties the buffer and reopens it. Neither side may use it concurrently.
+func (v *RingBuffer) Reset() {
+	v.read.Store(0)
+	v.write.Store(0)
+	v.closed.Store(false)
+}
@@ -0,0 +1,112 @@
+package pkg
+
+import (
+	"fmt"
+	"math"
+	"time"
+)
+
+// Segment is a clip of a recording between silent gaps, as sample frame indexes
+type Segment struct {
+	// Start is the first frame of the clip
+	Start int
+	// End is the frame following the last frame of the clip
+	End int
+}
+
+// FindSegments returns the clips of interleaved samples separated by gaps of at least
+// minGap frames in which every sample is quieter than threshold, given in decibels
+// relative to full scale, such as -50. Silence before, between and after the clips
+// is left out; shorter pauses stay within their clip.
+func FindSegments(samples []int16, channels int, threshold float64, minGap int) []Segment {
+	if channels <= 0 {
+		return nil
+	}
+
+	limit := int(math.Round(-minInt16 * math.Pow(10, threshold/20))) //nolint:gomnd // decibels of amplitude
+	if minGap < 1 {
+		minGap = 1
+	}
+
+	var result []Segment
+
+	start, lastLoud := -1, -1
+	frames := len(samples) / channels
+
+	for frame := 0; frame < frames; frame++ {
+		loud := false
+
+		for _, sample := range samples[frame*channels : (frame+1)*channels] {
+			if level := int(sample); level >= limit || -level >= limit {
+				loud = true
+				break
+			}
+		}
+
+		switch {
+		case loud && start < 0:
+			start = frame
+			lastLoud = frame
+		case loud:
+			lastLoud = frame
+		case start >= 0 && frame-lastLoud >= minGap:
+			result = append(result, Segment{Start: start, End: lastLoud + 1})
+			start = -1
+		}
+	}
+
+	if start >= 0 {
+		result = append(result, Segment{Start: start, End: lastLoud + 1})
+	}
+
+	return result
+}
+
+// Segments returns the clips of the buffer separated by gaps of at least minGap in
+// which every sample is quieter than threshold decibels relative to full scale
+func (v *Buffer) Segments(threshold float64, minGap time.Duration) []Segment {
+	return FindSegments(v.Samples, v.Channels, threshold, int(durationToFrames(minGap, int64(v.SampleRate))))
+}
+
+// SplitSilence splits the buffer into clips at gaps of at least minGap in which every
+// sample is quieter than threshold decibels relative to full scale, as Segments finds
+// them. The clips share the samples of the buffer, and each keeps the markers within it.
+func (v *Buffer) SplitSilence(threshold float64, minGap time.Duration) []*Buffer {
+	segments := v.Segments(threshold, minGap)
+	result := make([]*Buffer, len(segments))
+
+	for i, segment := range segments {
+		clip := *v
+		clip.Samples = v.Samples[segment.Start*v.Channels : segment.End*v.Channels]
+		clip.Metadata = v.Metadata.slice(int64(segment.Start), int64(segment.End))
+		result[i] = &clip
+	}
+
+	return result
+}
+
+// SplitFile splits the WAV file at src into clips at silent gaps like SplitSilence, and
+// writes them to numbered files named by formatting pattern, such as "line%03d.wav",
+// with the clip number counting from 1. opts convert the clips like EncodeFile. The
+// names of the files written are returned.
+func SplitFile(src, pattern string, threshold float64, minGap time.Duration, opts ...Option) ([]string, error) {
+	buffer, err := DecodeFile(src, opts...)
+	if err != nil {
+		return nil, err
+	}
+
+	clips := buffer.SplitSilence(threshold, minGap)
+	result := make([]string, 0, len(clips))
+
+	for i, clip := range clips {
+		path := fmt.Sprintf(pattern, i+1)
+
+		if err = EncodeFile(path, clip, opts...); err != nil {
+			return result, err
+		}
+
+		result = append(result, path)
+	}
+
+	return result, nil
+}
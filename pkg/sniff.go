@@ -0,0 +1,132 @@
+package pkg
+
+import (
+	"bytes"
+)
+
+// SniffSize is the number of leading bytes Sniff needs to tell every Kind apart
+const SniffSize = 16
+
+// Kind identifies the container or stream format of audio data
+type Kind int
+
+// Kinds recognised by Sniff
+const (
+	KindUnknown Kind = iota
+	KindRIFF
+	KindRIFX
+	KindRF64
+	KindWave64
+	KindAIFF
+	KindAU
+	KindADPCM
+)
+
+//nolint:gochecknoglobals // lookup table
+var kindNames = map[Kind]string{
+	KindUnknown: "unknown",
+	KindRIFF:    "RIFF/WAVE",
+	KindRIFX:    "RIFX/WAVE",
+	KindRF64:    "RF64/WAVE",
+	KindWave64:  "Wave64",
+	KindAIFF:    "AIFF",
+	KindAU:      "AU",
+	KindADPCM:   "MPQ ADPCM",
+}
+
+// String returns the name of the kind
+func (v Kind) String() string {
+	if name, ok := kindNames[v]; ok {
+		return name
+	}
+
+	return kindNames[KindUnknown]
+}
+
+// wave64GUID is the Sony Wave64 "riff" chunk GUID starting a Wave64 file
+//
+//nolint:gochecknoglobals // GUID bytes
+var wave64GUID = []byte{
+	'r', 'i', 'f', 'f', 0x2E, 0x91, 0xCF, 0x11, 0xA5, 0xD6, 0x28, 0xDB, 0x04, 0xC1, 0x00, 0x00,
+}
+
+// Sniff identifies the format of audio data from its first bytes, without parsing
+// it. Reading SniffSize bytes is enough to tell every Kind apart; shorter prefixes
+// are matched as far as they go. RIFF style containers must hold a WAVE form, and
+// KindADPCM is a compressed MPQ sector as accepted by Transcode: its compression
+// mask followed by the payload.
+func Sniff(prefix []byte) Kind {
+	if len(prefix) < bytesPerint32 {
+		return KindUnknown
+	}
+
+	id := readFourCC(prefix)
+
+	switch {
+	case id == ChunkRIFF:
+		return sniffForm(prefix, ChunkWAVE, KindRIFF)
+	case id == CreateFourCC("RIFX"):
+		return sniffForm(prefix, ChunkWAVE, KindRIFX)
+	case id == ChunkRF64 || id == ChunkBW64:
+		return sniffForm(prefix, ChunkWAVE, KindRF64)
+	case id == CreateFourCC("FORM"):
+		if kind := sniffForm(prefix, CreateFourCC("AIFF"), KindAIFF); kind != KindUnknown {
+			return kind
+		}
+
+		return sniffForm(prefix, CreateFourCC("AIFC"), KindAIFF)
+	case id == CreateFourCC(".snd"):
+		return KindAU
+	case isWave64(prefix):
+		return KindWave64
+	}
+
+	if isADPCMSector(prefix) {
+		return KindADPCM
+	}
+
+	return KindUnknown
+}
+
+// sniffForm returns kind if the RIFF style header in prefix holds the given form
+// type, or is too short to tell
+func sniffForm(prefix []byte, form FourCC, kind Kind) Kind {
+	if len(prefix) < riffHeaderSize || readFourCC(prefix[8:]) == form {
+		return kind
+	}
+
+	return KindUnknown
+}
+
+// isADPCMSector reports whether prefix starts with an MPQ compression mask naming one
+// ADPCM channel layout, followed by the header of the ADPCM stream if ADPCM is the
+// only compression
+func isADPCMSector(prefix []byte) bool {
+	mask := prefix[0]
+	adpcm := mask & (CompressionADPCMMono | CompressionADPCMStereo)
+
+	if adpcm != CompressionADPCMMono && adpcm != CompressionADPCMStereo {
+		return false
+	}
+
+	if mask&^(CompressionHuffman|CompressionZlib|CompressionPKWare|CompressionBZip2|adpcm) != 0 {
+		return false
+	}
+
+	if mask != adpcm {
+		return true
+	}
+
+	shift := prefix[2]
+
+	return prefix[1] == 0 && shift >= ADPCMLevelMin-1 && shift < ADPCMLevelMax
+}
+
+// isWave64 reports whether prefix matches the start of the Wave64 "riff" GUID
+func isWave64(prefix []byte) bool {
+	if len(prefix) > len(wave64GUID) {
+		prefix = prefix[:len(wave64GUID)]
+	}
+
+	return bytes.HasPrefix(wave64GUID, prefix)
+}
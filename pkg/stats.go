@@ -0,0 +1,69 @@
+package pkg
+
+import (
+	"time"
+)
+
+// Stats counts the work done by a Decoder or Encoder, for monitoring throughput.
+// Stages which don't apply to one or the other stay zero.
+type Stats struct {
+	// BytesIn counts the bytes read from the source of a decoder, or handed to an
+	// encoder as samples or encoded blocks
+	BytesIn int64
+	// BytesOut counts the bytes of samples a decoder returned, or the bytes an
+	// encoder wrote to its destination
+	BytesOut int64
+	// Blocks counts the blocks of nBlockAlign bytes decoded or encoded, which are
+	// sample frames for PCM
+	Blocks int64
+	// ReadTime is the time a decoder spent reading its source
+	ReadTime time.Duration
+	// DecodeTime is the time a decoder spent converting samples
+	DecodeTime time.Duration
+	// EncodeTime is the time an encoder spent converting samples and assembling chunks
+	EncodeTime time.Duration
+	// WriteTime is the time an encoder spent writing to its destination
+	WriteTime time.Duration
+}
+
+// statsRecorder accumulates Stats when enabled, and does nothing otherwise
+type statsRecorder struct {
+	enabled bool
+	stats   Stats
+	// partial holds the bytes of a block counted in part by a previous call
+	partial int64
+}
+
+// start returns the time a stage starts, if timing is enabled
+func (v *statsRecorder) start() time.Time {
+	if !v.enabled {
+		return time.Time{}
+	}
+
+	return time.Now()
+}
+
+// stop adds the time since start to the stage
+func (v *statsRecorder) stop(stage *time.Duration, start time.Time) {
+	if v.enabled {
+		*stage += time.Since(start)
+	}
+}
+
+// add adds n to the counter
+func (v *statsRecorder) add(counter *int64, n int) {
+	if v.enabled {
+		*counter += int64(n)
+	}
+}
+
+// addBlocks counts the whole blocks in size more bytes of data
+func (v *statsRecorder) addBlocks(size, blockSize int) {
+	if !v.enabled || blockSize <= 0 {
+		return
+	}
+
+	total := v.partial + int64(size)
+	v.stats.Blocks += total / int64(blockSize)
+	v.partial = total % int64(blockSize)
+}
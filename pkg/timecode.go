@@ -0,0 +1,158 @@
+package pkg
+
+import (
+	"errors"
+	"fmt"
+)
+
+const (
+	secondsPerMinute = 60
+	minutesPerHour   = 60
+	hoursPerDay      = 24
+	ntscNumerator    = 1000
+	ntscDenominator  = 1001
+	dropFrameDivisor = 15
+	dropFrameMinutes = 10
+)
+
+// ErrTimecode is returned when parsing a malformed timecode
+var ErrTimecode = errors.New("invalid timecode")
+
+// FrameRate is the rate of SMPTE timecode
+type FrameRate struct {
+	// Base is the number of frames counted per timecode second, such as 30 for 29.97
+	Base int
+	// NTSC runs the frames slower than Base by a factor of 1000/1001
+	NTSC bool
+	// DropFrame skips frame numbers to keep NTSC timecode in step with the clock
+	DropFrame bool
+}
+
+// Standard timecode rates
+//
+//nolint:gochecknoglobals // predefined rates
+var (
+	FrameRate23976  = FrameRate{Base: 24, NTSC: true}
+	FrameRate24     = FrameRate{Base: 24}
+	FrameRate25     = FrameRate{Base: 25}
+	FrameRate2997   = FrameRate{Base: 30, NTSC: true}
+	FrameRate2997DF = FrameRate{Base: 30, NTSC: true, DropFrame: true}
+	FrameRate30     = FrameRate{Base: 30}
+	FrameRate50     = FrameRate{Base: 50}
+	FrameRate5994   = FrameRate{Base: 60, NTSC: true}
+	FrameRate5994DF = FrameRate{Base: 60, NTSC: true, DropFrame: true}
+	FrameRate60     = FrameRate{Base: 60}
+)
+
+// Timecode is an SMPTE timecode, hours:minutes:seconds:frames
+type Timecode struct {
+	Hours   int
+	Minutes int
+	Seconds int
+	Frames  int
+	Rate    FrameRate
+}
+
+// TimecodeAt returns the timecode of the sample frame at position, counted from
+// midnight as bext TimeReference is. Timecode wraps around after 24 hours.
+func TimecodeAt(position int64, sampleRate int, rate FrameRate) Timecode {
+	result := Timecode{Rate: rate}
+	if sampleRate <= 0 || rate.Base <= 0 {
+		return result
+	}
+
+	numerator, denominator := rate.speed()
+	frame := position * int64(rate.Base) * numerator / (int64(sampleRate) * denominator)
+
+	if drop := rate.dropped(); drop > 0 {
+		perMinute := int64(rate.Base*secondsPerMinute) - drop
+		perTenMinutes := perMinute*dropFrameMinutes + drop
+		tens, rest := frame/perTenMinutes, frame%perTenMinutes
+
+		frame += drop * (dropFrameMinutes - 1) * tens
+		if rest > drop {
+			frame += drop * ((rest - drop) / perMinute)
+		}
+	}
+
+	base := int64(rate.Base)
+	result.Frames = int(frame % base)
+	result.Seconds = int(frame / base % secondsPerMinute)
+	result.Minutes = int(frame / (base * secondsPerMinute) % minutesPerHour)
+	result.Hours = int(frame / (base * secondsPerMinute * minutesPerHour) % hoursPerDay)
+
+	return result
+}
+
+// ParseTimecode parses a timecode written as hh:mm:ss:ff, or hh:mm:ss;ff for drop
+// frame rates
+func ParseTimecode(s string, rate FrameRate) (Timecode, error) {
+	result := Timecode{Rate: rate}
+
+	var separator byte
+
+	if _, err := fmt.Sscanf(s, "%d:%d:%d%c%d", &result.Hours, &result.Minutes, &result.Seconds, &separator, &result.Frames); err != nil {
+		return result, fmt.Errorf("%w: %q", ErrTimecode, s)
+	}
+
+	if separator != ':' && separator != ';' && separator != '.' {
+		return result, fmt.Errorf("%w: %q", ErrTimecode, s)
+	}
+
+	if result.Minutes >= minutesPerHour || result.Seconds >= secondsPerMinute || result.Frames >= rate.Base {
+		return result, fmt.Errorf("%w: %q is out of range", ErrTimecode, s)
+	}
+
+	return result, nil
+}
+
+// SamplePosition returns the first sample frame within the timecode frame, counted
+// from midnight as bext TimeReference is
+func (v Timecode) SamplePosition(sampleRate int) int64 {
+	if v.Rate.Base <= 0 {
+		return 0
+	}
+
+	minutes := int64(v.Hours*minutesPerHour + v.Minutes)
+	seconds := minutes*secondsPerMinute + int64(v.Seconds)
+	frame := seconds*int64(v.Rate.Base) + int64(v.Frames)
+
+	if drop := v.Rate.dropped(); drop > 0 {
+		frame -= drop * (minutes - minutes/dropFrameMinutes)
+	}
+
+	numerator, denominator := v.Rate.speed()
+	divisor := int64(v.Rate.Base) * numerator
+
+	// round up, as frames rarely start on a whole sample
+	return (frame*int64(sampleRate)*denominator + divisor - 1) / divisor
+}
+
+// String formats the timecode as hh:mm:ss:ff, or hh:mm:ss;ff for drop frame rates
+func (v Timecode) String() string {
+	separator := ':'
+	if v.Rate.DropFrame {
+		separator = ';'
+	}
+
+	return fmt.Sprintf("%02d:%02d:%02d%c%02d", v.Hours, v.Minutes, v.Seconds, separator, v.Frames)
+}
+
+// speed returns the ratio of the real frame rate to Base
+func (v FrameRate) speed() (numerator, denominator int64) {
+	if v.NTSC {
+		return ntscNumerator, ntscDenominator
+	}
+
+	return 1, 1
+}
+
+// dropped returns the number of frame numbers skipped at the start of each minute
+// not divisible by ten
+func (v FrameRate) dropped() int64 {
+	if !v.DropFrame {
+		return 0
+	}
+
+	return int64(v.Base / dropFrameDivisor)
+}
@@ -0,0 +1,287 @@
+package pkg
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"math"
+)
+
+// Errors returned for input which can't be transcoded
+var (
+	ErrEmptyInput  = errors.New("empty input")
+	ErrInputFormat = errors.New("input is neither a WAV file nor a compressed MPQ sector")
+)
+
+// Transcode reads a WAV file or a compressed MPQ sector (its compression mask byte
+// followed by the payload) from src, and writes it to dst as a PCM WAV file,
+// resampled and converted as requested by opts. Other input fails with ErrInputFormat.
+func Transcode(src io.Reader, dst io.Writer, opts ...Option) error {
+	options := applyOptions(opts)
+
+	data, err := io.ReadAll(src)
+	if err != nil {
+		return err
+	}
+
+	samples, format, riff, err := decodeInput(data, options)
+	if err != nil {
+		return err
+	}
+
+	return encodeOutput(dst, samples, format, riff, options)
+}
+
+// encodeOutput converts decoded samples as requested by options and writes them to
+// dst as a WAV file, copying the metadata of riff when asked to
+func encodeOutput(dst io.Writer, samples []int16, format Format, riff []byte, options options) error {
+	var err error
+
+	if err = checkPassthrough(samples, format, options); err != nil {
+		return err
+	}
+
+	channels := int(format.Channels)
+	layout := options.layout
+
+	switch {
+	case layout != nil && options.channelMap == nil:
+		options.channelMap = MapLayout(format.Layout(), layout)
+	case options.telephony:
+		// telephony systems expect a plain format
+		layout = nil
+	case layout == nil && options.channelMap == nil && format.ChannelMask() != 0:
+		// keep the speaker assignment of the input
+		layout = format.Layout()
+	}
+
+	if options.channelMap != nil {
+		if samples, err = options.channelMap.Apply(samples, channels); err != nil {
+			return err
+		}
+
+		channels = len(options.channelMap)
+	}
+
+	if options.channels > 0 && channels > 0 && options.channels != channels {
+		if layout != nil && options.layout == nil {
+			// the speakers of the input no longer apply
+			layout = nil
+		}
+
+		samples = Remix(samples, channels, options.channels)
+		channels = options.channels
+	}
+
+	sampleRate := int(format.SampleRate)
+	if options.sampleRate > 0 {
+		if samples, err = options.resample(samples, channels, sampleRate, options.sampleRate); err != nil {
+			return err
+		}
+
+		sampleRate = options.sampleRate
+	}
+
+	if options.hasDuration {
+		samples = FitSamples(samples, channels, int(durationToFrames(options.duration, int64(sampleRate))))
+	}
+
+	bitsPerSample := d2BitsPerSample
+
+	switch options.codec {
+	case FormatIEEEFloat:
+		bitsPerSample = 32 //nolint:gomnd // single precision
+	case FormatMuLaw, FormatALaw:
+		bitsPerSample = bitsPerByte
+	}
+
+	if options.bitsPerSample > 0 {
+		bitsPerSample = options.bitsPerSample
+	}
+
+	if layout != nil && len(layout) != channels {
+		return fmt.Errorf("%w: layout of %d channels for %d", ErrChannelMap, len(layout), channels)
+	}
+
+	outputFormat := CreatePCMFormat(sampleRate, bitsPerSample, channels)
+	ambisonics, isAmbisonic := format.Ambisonics()
+
+	switch {
+	case layout != nil:
+		outputFormat = CreateExtensibleFormat(sampleRate, bitsPerSample, layout)
+	case isAmbisonic && options.channelMap == nil && !options.telephony:
+		// keep the ambisonic convention of the input
+		if outputFormat, err = CreateAmbisonicFormat(sampleRate, bitsPerSample, ambisonics); err != nil {
+			return err
+		}
+	}
+
+	if options.codec != 0 {
+		outputFormat = outputFormat.withCodec(options.codec)
+		if err = outputFormat.validateSamples(); err != nil {
+			return err
+		}
+	}
+
+	encoder, err := CreateFormatEncoder(dst, outputFormat)
+	if err != nil {
+		return err
+	}
+
+	if options.keepMetadata && riff != nil {
+		if err = copyMetadata(encoder, riff, int(format.SampleRate), sampleRate, options.canonicalOrder); err != nil {
+			return err
+		}
+	}
+
+	if options.metadata != nil {
+		encoder.SetMetadata(options.metadata.rescale(int(format.SampleRate), sampleRate))
+	}
+
+	if err = encoder.WriteSamples(samples); err != nil {
+		return err
+	}
+
+	return encoder.Close()
+}
+
+// checkPassthrough refuses to process compressed passthrough audio in ways that would
+// change its samples, unless WithPassthrough is given
+func checkPassthrough(samples []int16, format Format, options options) error {
+	if options.passthrough || !format.isPassthroughCandidate() {
+		return nil
+	}
+
+	altered := options.channelMap != nil || options.layout != nil ||
+		(options.sampleRate > 0 && options.sampleRate != int(format.SampleRate)) ||
+		(options.bitsPerSample > 0 && options.bitsPerSample != d2BitsPerSample) ||
+		(options.channels > 0 && options.channels != int(format.Channels)) ||
+		(options.codec != 0 && options.codec != FormatPCM)
+
+	if !altered {
+		return nil
+	}
+
+	if passthrough, ok := DetectPassthrough(samples); ok {
+		return fmt.Errorf("%w: %s", ErrPassthrough, passthrough.Codec)
+	}
+
+	return nil
+}
+
+// decodeInput decodes a WAV file or a compressed MPQ sector into interleaved samples,
+// also returning the WAV file if there was one
+func decodeInput(data []byte, options options) ([]int16, Format, []byte, error) {
+	if len(data) == 0 {
+		return nil, Format{}, nil, ErrEmptyInput
+	}
+
+	if !isWaveFile(data) {
+		if !isSectorMask(data[0]) {
+			return nil, Format{}, nil, fmt.Errorf("%w: compression mask 0x%02x", ErrInputFormat, data[0])
+		}
+
+		if adpcm := data[0] & (CompressionADPCMMono | CompressionADPCMStereo); adpcm != 0 {
+			return decodeADPCMSector(data, adpcm, options)
+		}
+
+		decompressed, err := MultiDecompress(data[1:], data[0])
+		if err != nil {
+			return nil, Format{}, nil, err
+		}
+
+		if !isWaveFile(decompressed) {
+			return decodeHeaderless(decompressed, options)
+		}
+
+		data = decompressed
+	}
+
+	decoder, err := CreateDecoder(data)
+	if err != nil {
+		return nil, Format{}, nil, err
+	}
+
+	samples, err := decoder.DecodeAll()
+
+	return samples, decoder.Format(), data, err
+}
+
+// decodeHeaderless decodes a decompressed sector holding 16-bit PCM without a WAV
+// header, at the rate and channel count given by the options. A trailing partial
+// frame is dropped.
+func decodeHeaderless(data []byte, options options) ([]int16, Format, []byte, error) {
+	channels := options.inputChannels
+	if channels < 1 || channels > math.MaxUint16 {
+		return nil, Format{}, nil, fmt.Errorf("%w: %d input channels", ErrUnsupportedFormat, channels)
+	}
+
+	samples := bytesToSamples(data)
+	samples = samples[:len(samples)-len(samples)%channels]
+
+	return samples, CreatePCMFormat(options.inputSampleRate, d2BitsPerSample, channels), nil, nil
+}
+
+// isWaveFile reports whether data starts like a RIFF or RF64 WAV file rather than a
+// compressed MPQ sector
+func isWaveFile(data []byte) bool {
+	if len(data) < bytesPerint32 {
+		return false
+	}
+
+	id := readFourCC(data)
+
+	return id == ChunkRIFF || id == ChunkRF64 || id == ChunkBW64
+}
+
+// isSectorMask reports whether mask is the compression mask of an MPQ sector holding
+// audio: at least one supported method and at most one ADPCM channel layout
+func isSectorMask(mask byte) bool {
+	adpcm := mask & (CompressionADPCMMono | CompressionADPCMStereo)
+
+	return mask != 0 && mask&^supportedCompression == 0 && adpcm != CompressionADPCMMono|CompressionADPCMStereo
+}
+
+// decodeADPCMSector undoes the compression stages of an MPQ sector before ADPCM, then
+// decodes the ADPCM stream straight into samples
+func decodeADPCMSector(data []byte, adpcm byte, options options) ([]int16, Format, []byte, error) {
+	channels := 1
+	if adpcm&CompressionADPCMStereo != 0 {
+		channels = 2
+	}
+
+	decompressed, err := MultiDecompress(data[1:], data[0]&^adpcm)
+	if err != nil {
+		return nil, Format{}, nil, err
+	}
+
+	samples, err := WavDecompressSamples(decompressed, channels)
+	if err != nil {
+		return nil, Format{}, nil, err
+	}
+
+	return samples, CreatePCMFormat(options.inputSampleRate, d2BitsPerSample, channels), nil, nil
+}
+
+// bytesToSamples converts little-endian 16-bit PCM to samples, dropping an odd byte
+func bytesToSamples(data []byte) []int16 {
+	result := make([]int16, len(data)/bytesPerint16)
+
+	for i := range result {
+		result[i] = int16(uint16(data[i*2]) | uint16(data[i*2+1])<<bitsPerByte)
+	}
+
+	return result
+}
+
+// samplesToBytes converts samples to little-endian 16-bit PCM
+func samplesToBytes(samples []int16) []byte {
+	result := make([]byte, len(samples)*bytesPerint16)
+
+	for i, sample := range samples {
+		result[i*2] = byte(sample)
+		result[i*2+1] = byte(uint16(sample) >> bitsPerByte)
+	}
+
+	return result
+}
@@ -0,0 +1,146 @@
+package pkg
+
+import (
+	"bytes"
+	"encoding/binary"
+	"io"
+)
+
+const (
+	smplHeaderSize  = 36
+	smplPeriodAt    = 8
+	smplLoopSize    = 24
+	smplLoopStartAt = 8
+	cuePositionAt   = 4
+	cueOffsetAt     = 20
+)
+
+// chunk placements relative to the fmt and data chunks
+const (
+	beforeFmt = iota
+	beforeData
+	afterData
+)
+
+// canonicalPlacement returns where the Encoder places a chunk it writes itself, or
+// the given placement after the fmt chunk for other chunks
+func canonicalPlacement(id FourCC, data []byte, placement int) int {
+	switch id {
+	case ChunkBext, ChunkCart, ChunkLevl, ChunkChna:
+		return beforeData
+	case ChunkCue, ChunkSmpl, ChunkInst, ChunkAcid, ChunkAXML, ChunkDbmd:
+		return afterData
+	case ChunkLIST:
+		switch {
+		case bytes.HasPrefix(data, ChunkAdtl[:]):
+			return afterData
+		case bytes.HasPrefix(data, ChunkINFO[:]):
+			return beforeData
+		}
+	}
+
+	if placement == beforeFmt {
+		return beforeData
+	}
+
+	return placement
+}
+
+// copyMetadata adds the chunks of a WAV file other than fmt, fact, data and wavl to the
+// encoder in their original order and placement, or moved to their canonical placement
+// if canonical is set, rescaling sample positions in cue and smpl chunks from one sample
+// rate to another
+func copyMetadata(encoder *Encoder, riff []byte, from, to int, canonical bool) error {
+	placement := beforeFmt
+
+	return WalkChunks(bytes.NewReader(riff), func(id FourCC, size uint32, body io.Reader) error {
+		switch id {
+		case ChunkFmt:
+			placement = beforeData
+			return nil
+		case ChunkFact:
+			return nil
+		case ChunkData:
+			placement = afterData
+			return nil
+		}
+
+		data, err := io.ReadAll(body)
+		if err != nil {
+			return err
+		}
+
+		// the audio of a wave list has already been decoded
+		if id == ChunkLIST && bytes.HasPrefix(data, ChunkWavl[:]) {
+			placement = afterData
+			return SkipList
+		}
+
+		if from != to {
+			switch id {
+			case ChunkCue:
+				data = rescaleCue(data, from, to)
+			case ChunkSmpl:
+				data = rescaleSmpl(data, from, to)
+			}
+		}
+
+		at := placement
+		if canonical {
+			at = canonicalPlacement(id, data, placement)
+		}
+
+		switch at {
+		case beforeFmt:
+			encoder.AddLeadingChunk(id, data)
+		case beforeData:
+			encoder.AddHeaderChunk(id, data)
+		default:
+			encoder.AddChunk(id, data)
+		}
+
+		// LIST chunks are copied whole
+		return SkipList
+	})
+}
+
+// rescalePosition converts a sample frame position from one sample rate to another
+func rescalePosition(position uint32, from, to int) uint32 {
+	return uint32(uint64(position) * uint64(to) / uint64(from))
+}
+
+// rescaleCue returns a copy of a cue chunk body with its positions rescaled
+func rescaleCue(body []byte, from, to int) []byte {
+	result := append([]byte(nil), body...)
+
+	for at := bytesPerint32; at+cuePointSize <= len(result); at += cuePointSize {
+		for _, field := range []int{at + cuePositionAt, at + cueOffsetAt} {
+			position := binary.LittleEndian.Uint32(result[field:])
+			binary.LittleEndian.PutUint32(result[field:], rescalePosition(position, from, to))
+		}
+	}
+
+	return result
+}
+
+// rescaleSmpl returns a copy of a smpl chunk body with its sample period and loop
+// points rescaled
+func rescaleSmpl(body []byte, from, to int) []byte {
+	result := append([]byte(nil), body...)
+
+	if len(result) < smplHeaderSize {
+		return result
+	}
+
+	period := binary.LittleEndian.Uint32(result[smplPeriodAt:])
+	binary.LittleEndian.PutUint32(result[smplPeriodAt:], rescalePosition(period, to, from))
+
+	for at := smplHeaderSize; at+smplLoopSize <= len(result); at += smplLoopSize {
+		for _, field := range []int{at + smplLoopStartAt, at + smplLoopStartAt + bytesPerint32} {
+			position := binary.LittleEndian.Uint32(result[field:])
+			binary.LittleEndian.PutUint32(result[field:], rescalePosition(position, from, to))
+		}
+	}
+
+	return result
+}
@@ -0,0 +1,76 @@
+package pkg_test
+
+import (
+	"bytes"
+	"compress/zlib"
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/gravestench/wav/pkg"
+)
+
+// zlibSector returns a compressed MPQ sector holding pcm without a WAV header
+func zlibSector(t *testing.T, pcm []byte) []byte {
+	t.Helper()
+
+	var sector bytes.Buffer
+
+	sector.WriteByte(pkg.CompressionZlib)
+
+	w := zlib.NewWriter(&sector)
+	if _, err := w.Write(pcm); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	return sector.Bytes()
+}
+
+// TestDecodeHeaderlessChannels decodes a sector of headerless stereo PCM, which is
+// mono unless WithInputChannels says otherwise
+func TestDecodeHeaderlessChannels(t *testing.T) {
+	samples := sineSamples(2, time.Second/10)
+	sector := zlibSector(t, samplesToPCM(samples))
+
+	tests := []struct {
+		opts     []pkg.Option
+		channels int
+	}{
+		{nil, 1},
+		{[]pkg.Option{pkg.WithInputChannels(2)}, 2},
+	}
+
+	for _, test := range tests {
+		buffer, err := pkg.DecodeBuffer(bytes.NewReader(sector), test.opts...)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if buffer.Channels != test.channels {
+			t.Errorf("decoded %d channels, want %d", buffer.Channels, test.channels)
+		}
+
+		if !reflect.DeepEqual(buffer.Samples, samples) {
+			t.Errorf("%d channels: decoded %d samples differing from the %d compressed", test.channels, len(buffer.Samples), len(samples))
+		}
+	}
+
+	// a trailing partial frame is dropped
+	buffer, err := pkg.DecodeBuffer(bytes.NewReader(zlibSector(t, samplesToPCM(samples[:5]))), pkg.WithInputChannels(2))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(buffer.Samples) != 4 {
+		t.Errorf("decoded %d samples of 2 and a half frames, want 4", len(buffer.Samples))
+	}
+
+	if _, err = pkg.DecodeBuffer(bytes.NewReader(sector), pkg.WithInputChannels(0)); !errors.Is(err, pkg.ErrUnsupportedFormat) {
+		t.Errorf("decoding 0 channels returned %v, want %v", err, pkg.ErrUnsupportedFormat)
+	}
+}
@@ -0,0 +1,120 @@
+package pkg
+
+import (
+	"math"
+)
+
+const truePeakTaps = 12
+
+// truePeakPhases holds the 4x oversampling interpolation filter of ITU-R BS.1770-4
+// Annex 2, split into its four phases
+//
+//nolint:gochecknoglobals // filter table
+var truePeakPhases = [4][truePeakTaps]float64{
+	{
+		0.0017089843750, 0.0109863281250, -0.0196533203125, 0.0332031250000,
+		-0.0594482421875, 0.1373291015625, 0.9721679687500, -0.1022949218750,
+		0.0476074218750, -0.0266113281250, 0.0148925781250, -0.0083007812500,
+	},
+	{
+		-0.0291748046875, 0.0292968750000, -0.0517578125000, 0.0891113281250,
+		-0.1665039062500, 0.4650878906250, 0.7797851562500, -0.2003173828125,
+		0.1015625000000, -0.0582275390625, 0.0330810546875, -0.0189208984375,
+	},
+	{
+		-0.0189208984375, 0.0330810546875, -0.0582275390625, 0.1015625000000,
+		-0.2003173828125, 0.7797851562500, 0.4650878906250, -0.1665039062500,
+		0.0891113281250, -0.0517578125000, 0.0292968750000, -0.0291748046875,
+	},
+	{
+		-0.0083007812500, 0.0148925781250, -0.0266113281250, 0.0476074218750,
+		-0.1022949218750, 0.9721679687500, 0.1373291015625, -0.0594482421875,
+		0.0332031250000, -0.0196533203125, 0.0109863281250, 0.0017089843750,
+	},
+}
+
+// PeakLevels holds the peak levels of each channel, as fractions of full scale
+type PeakLevels struct {
+	// Sample is the largest absolute sample value
+	Sample []float64
+	// True is the largest absolute value between samples, found by 4x oversampling as
+	// ITU-R BS.1770 describes. It is never below Sample, and exceeds 1 where decoding
+	// or resampling the audio would clip.
+	True []float64
+}
+
+// MeasurePeaks measures the sample and true peak levels of each channel of
+// interleaved samples
+func MeasurePeaks(samples []int16, channels int) PeakLevels {
+	if channels <= 0 {
+		return PeakLevels{}
+	}
+
+	result := PeakLevels{
+		Sample: make([]float64, channels),
+		True:   make([]float64, channels),
+	}
+
+	frames := len(samples) / channels
+
+	for ch := 0; ch < channels; ch++ {
+		var history [truePeakTaps]float64
+
+		samplePeak, truePeak := 0.0, 0.0
+
+		for frame := 0; frame < frames; frame++ {
+			x := float64(samples[frame*channels+ch]) / -minInt16
+
+			copy(history[1:], history[:truePeakTaps-1])
+			history[0] = x
+
+			samplePeak = math.Max(samplePeak, math.Abs(x))
+
+			for phase := range truePeakPhases {
+				y := 0.0
+				for k, h := range truePeakPhases[phase] {
+					y += h * history[k]
+				}
+
+				truePeak = math.Max(truePeak, math.Abs(y))
+			}
+		}
+
+		result.Sample[ch] = samplePeak
+		result.True[ch] = math.Max(samplePeak, truePeak)
+	}
+
+	return result
+}
+
+// SamplePeak returns the largest sample peak of any channel
+func (v PeakLevels) SamplePeak() float64 {
+	return maxFloat(v.Sample)
+}
+
+// TruePeak returns the largest true peak of any channel
+func (v PeakLevels) TruePeak() float64 {
+	return maxFloat(v.True)
+}
+
+// TruePeakDB returns the largest true peak of any channel in decibels relative to
+// full scale (dBTP), which is -Inf for silence
+func (v PeakLevels) TruePeakDB() float64 {
+	return 20 * math.Log10(v.TruePeak()) //nolint:gomnd // decibels of amplitude
+}
+
+// Peaks measures the sample and true peak levels of each channel of the buffer
+func (v *Buffer) Peaks() PeakLevels {
+	return MeasurePeaks(v.Samples, v.Channels)
+}
+
+// maxFloat returns the largest of values, or 0 if there are none
+func maxFloat(values []float64) float64 {
+	result := 0.0
+
+	for _, value := range values {
+		result = math.Max(result, value)
+	}
+
+	return result
+}
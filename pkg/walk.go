@@ -0,0 +1,21 @@
+package pkg
+
+import (
+	"io"
+
+	"github.com/gravestench/wav/pkg/riff"
+)
+
+// SkipList can be returned by a WalkChunks callback for a LIST chunk to skip the
+// chunks nested in it
+var SkipList = riff.SkipList //nolint:errname,revive,stylecheck // used like filepath.SkipDir
+
+// WalkChunks calls fn for every chunk of a RIFF WAVE stream, in file order. The chunks
+// nested in a LIST follow the LIST itself, whose body starts with the list type. body
+// reads the chunk body, and needn't be drained. The walk stops at the first error
+// returned by fn, other than SkipList. In RF64 files the sizes of chunks too large for
+// 32 bits are taken from the ds64 chunk; size is then 0xFFFFFFFF, but body reads the
+// whole chunk.
+func WalkChunks(r io.ReadSeeker, fn func(id FourCC, size uint32, body io.Reader) error) error {
+	return riff.WalkChunks(r, fn)
+}
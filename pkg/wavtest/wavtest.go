@@ -0,0 +1,123 @@
+// Package wavtest generates test signals and synthetic WAV files, so that tests can
+// create their fixtures programmatically instead of shipping audio files
+package wavtest
+
+import (
+	"bytes"
+	"math"
+	"math/rand"
+	"time"
+
+	"github.com/gravestench/wav/pkg"
+)
+
+const bitsPerSample = 16
+
+// Sine returns a buffer holding a sine wave of the given frequency, with amplitude
+// as a fraction of full scale, on every channel
+func Sine(sampleRate, channels int, frequency, amplitude float64, d time.Duration) *pkg.Buffer {
+	return generate(sampleRate, channels, d, func(t float64) float64 {
+		return amplitude * math.Sin(2*math.Pi*frequency*t)
+	})
+}
+
+// Square returns a buffer holding a square wave of the given frequency, with
+// amplitude as a fraction of full scale, on every channel
+func Square(sampleRate, channels int, frequency, amplitude float64, d time.Duration) *pkg.Buffer {
+	return generate(sampleRate, channels, d, func(t float64) float64 {
+		if math.Mod(frequency*t, 1) < 0.5 { //nolint:gomnd // half of the period
+			return amplitude
+		}
+
+		return -amplitude
+	})
+}
+
+// Noise returns a buffer holding uniform white noise, with amplitude as a fraction
+// of full scale, on every channel. The same seed always gives the same noise.
+func Noise(sampleRate, channels int, amplitude float64, d time.Duration, seed int64) *pkg.Buffer {
+	random := rand.New(rand.NewSource(seed)) //nolint:gosec // reproducible, not secure
+
+	return generate(sampleRate, channels, d, func(float64) float64 {
+		return amplitude * (2*random.Float64() - 1)
+	})
+}
+
+// Sweep returns a buffer holding a sine wave sweeping exponentially from one frequency
+// to another over its duration, with amplitude as a fraction of full scale, on every
+// channel
+func Sweep(sampleRate, channels int, from, to, amplitude float64, d time.Duration) *pkg.Buffer {
+	length := d.Seconds()
+	rate := math.Log(to / from)
+
+	return generate(sampleRate, channels, d, func(t float64) float64 {
+		if length == 0 || rate == 0 {
+			return amplitude * math.Sin(2*math.Pi*from*t)
+		}
+
+		// the phase is the integral of the instantaneous frequency from*e^(rate*t/length)
+		phase := 2 * math.Pi * from * length / rate * (math.Exp(rate*t/length) - 1)
+
+		return amplitude * math.Sin(phase)
+	})
+}
+
+// generate returns a buffer holding signal, a function of time in seconds returning
+// values from -1 to 1, on every channel
+func generate(sampleRate, channels int, d time.Duration, signal func(t float64) float64) *pkg.Buffer {
+	format := pkg.CreatePCMFormat(sampleRate, bitsPerSample, channels)
+	frames := int(format.DurationToFrames(d))
+
+	result := &pkg.Buffer{
+		SampleRate:    sampleRate,
+		Channels:      channels,
+		BitsPerSample: bitsPerSample,
+		Samples:       make([]int16, frames*channels),
+	}
+
+	for frame := 0; frame < frames; frame++ {
+		value := math.Round(signal(float64(frame)/float64(sampleRate)) * math.MaxInt16)
+		sample := int16(math.Max(math.MinInt16, math.Min(math.MaxInt16, value)))
+
+		for ch := 0; ch < channels; ch++ {
+			result.Samples[frame*channels+ch] = sample
+		}
+	}
+
+	return result
+}
+
+// Encode returns the buffer as a WAV file, converted as requested by opts like
+// pkg.EncodeBuffer. It panics if the buffer can't be encoded, as a fixture which
+// can't be created is a bug in the test.
+func Encode(buffer *pkg.Buffer, opts ...pkg.Option) []byte {
+	var result bytes.Buffer
+
+	if err := pkg.EncodeBuffer(&result, buffer, opts...); err != nil {
+		panic(err)
+	}
+
+	return result.Bytes()
+}
+
+// EncodeFormat returns interleaved samples as a WAV file in format, which must be a
+// PCM or IEEE float format such as pkg.CreatePCMFormat returns. Like Encode, it
+// panics if the samples can't be encoded.
+func EncodeFormat(format pkg.Format, samples []int16) []byte {
+	var result bytes.Buffer
+
+	encoder, err := pkg.CreateFormatEncoder(&result, format)
+	if err == nil {
+		err = encoder.WriteSamples(samples)
+	}
+
+	if err == nil {
+		err = encoder.Close()
+	}
+
+	if err != nil {
+		panic(err)
+	}
+
+	return result.Bytes()
+}